@@ -12,9 +12,9 @@ import (
 	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/device"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
 	"github.com/batazor/whiteout-survival-autopilot/internal/gift"
 	"github.com/batazor/whiteout-survival-autopilot/internal/logger"
-	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
 	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
 	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
 	"github.com/batazor/whiteout-survival-autopilot/internal/syncer"
@@ -89,6 +89,16 @@ func main() {
 		return
 	}
 
+	// ─── FSM transition graph (hot reload) ──────────────────────────────────
+	fsmGraph, err := config.DefaultFSMGraph()
+	if err != nil {
+		appLogger.Error("❌ FSM graph loading error", slog.Any("err", err))
+		return
+	}
+	if err := fsmGraph.Watch(ctx); err != nil {
+		appLogger.Warn("⚠️ FSM graph hot reload is disabled", slog.Any("err", err))
+	}
+
 	// 🌟 Initialize TriggerEvaluator 🌟
 	triggerEvaluator := config.NewTriggerEvaluator()
 
@@ -152,4 +162,9 @@ func main() {
 	}
 
 	wg.Wait()
+
+	// the latest frames get their taps and overlays
+	if err := framearchive.Default().Flush(); err != nil {
+		appLogger.Warn("⚠️ Failed to flush frame archive", slog.Any("err", err))
+	}
 }
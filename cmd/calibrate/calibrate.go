@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/calibration"
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// calibrate captures the current screen of the device, locates the anchors of references/anchors.yaml
+// and writes the regions that moved to the device override file. Open the screen with the anchors first.
+func main() {
+	deviceID := flag.String("device", "", "ADB serial of the device")
+	areaPath := flag.String("area", "references/area.json", "base area file")
+	anchorsPath := flag.String("anchors", "references/anchors.yaml", "anchor list")
+	out := flag.String("out", "", "override file (default: PATH_TO_AREA_OVERRIDES/<device>.json)")
+	minShift := flag.Int("min-shift", 4, "ignore shifts below this many pixels")
+	dryRun := flag.Bool("dry-run", false, "only print the report")
+	flag.Parse()
+
+	if *deviceID == "" {
+		log.Fatal("❌ -device is required")
+	}
+	if *out == "" {
+		*out = config.AreaOverridePath(*deviceID)
+	}
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	areas, err := config.LoadAreaReferences(*areaPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := areas.LoadOverrides(*out); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	anchors, err := calibration.LoadAnchors(*anchorsPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	client := ocrclient.NewClient(*deviceID, logger)
+	frame, err := client.CaptureFrame(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to capture the screen: %v", err)
+	}
+
+	scale := domain.NewScreenScale(frame.Width, frame.Height)
+	if err := scale.Check(); err != nil {
+		log.Fatalf("❌ Unsupported screen: %v", err)
+	}
+	areas.SetScale(scale)
+
+	screen, err := client.FrameImage(ctx, frame.ID)
+	if err != nil {
+		log.Fatalf("❌ Failed to load the frame: %v", err)
+	}
+	ocr, err := client.FetchOCRFrame(ctx, frame.ID, "calibration", nil)
+	if err != nil {
+		log.Fatalf("❌ OCR failed: %v", err)
+	}
+
+	viper.SetDefault("PATH_TO_ICONS", "references/icons")
+	icons := vision.NewIconMatcher(viper.GetString("PATH_TO_ICONS"), nil)
+	icons.Scale = scale.Factor
+
+	proposals, err := calibration.Propose(areas, anchors, calibration.ScreenLocator{OCR: ocr, Screen: screen, Icons: icons}, *minShift)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := calibration.WriteReport(os.Stdout, proposals); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if *dryRun {
+		return
+	}
+
+	moved := calibration.Apply(areas, proposals)
+	if moved == 0 {
+		fmt.Println("✅ Nothing moved")
+		return
+	}
+	if err := areas.SaveOverrides(*out); err != nil {
+		log.Fatalf("❌ Failed to write overrides: %v", err)
+	}
+	fmt.Printf("💾 %d regions written to %s\n", moved, *out)
+}
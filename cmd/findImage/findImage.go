@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
@@ -18,7 +19,7 @@ func main() {
 	// 2) Try to find icon "alliance.state.isNeedSupport" (file name alliance.state.isNeedSupport.png in references/icons),
 	//    with threshold 0.8 and debug_name label "alliance.state.isNeedSupport_check"
 	start := time.Now()
-	resp, err := client.FindImage("alliance.state.isNeedSupport", 0.8, "alliance.state.isNeedSupport_check")
+	resp, err := client.FindImage(context.Background(), "alliance.state.isNeedSupport", 0.8, "alliance.state.isNeedSupport_check")
 	elapsed := time.Since(start)
 	if err != nil {
 		log.Fatalf("FindImage failed: %v", err)
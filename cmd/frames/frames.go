@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
+)
+
+// frames lists the archived analysis frames of a trace (or the latest ones), so a usecase that failed
+// in the traces can be inspected on its annotated screenshots.
+func main() {
+	dir := flag.String("dir", "out/frames", "frame archive dir (FRAME_ARCHIVE_DIR of the bot)")
+	traceID := flag.String("trace", "", "trace ID; empty – the latest frames")
+	limit := flag.Int("limit", 20, "number of latest frames to show without -trace (0 – all)")
+	flag.Parse()
+
+	archive := framearchive.New(*dir, 0, 0)
+
+	var records []*framearchive.Record
+	var err error
+	if *traceID != "" {
+		records, err = archive.FindByTrace(*traceID)
+	} else {
+		records, err = archive.List()
+		if *limit > 0 && len(records) > *limit {
+			records = records[len(records)-*limit:]
+		}
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to read frame archive: %v", err)
+	}
+	if len(records) == 0 {
+		fmt.Println("📭 No frames found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tDEVICE\tGAMER\tSCREEN\tRULES\tCLICKS\tERROR\tOVERLAY")
+	for _, rec := range records {
+		overlay := archive.Path(rec, "_overlay.png")
+		if _, err := os.Stat(overlay); os.IsNotExist(err) {
+			// the latest frame of a device is completed by the bot with the next one
+			if err := archive.RenderOverlay(rec); err != nil {
+				overlay = "-"
+			}
+		}
+
+		errText := rec.Error
+		if errText == "" {
+			errText = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			rec.Time.Local().Format(time.TimeOnly), rec.Device, rec.Gamer, rec.Screen,
+			len(rec.Rules), len(rec.Clicks), errText, overlay)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
+)
+
+// fsmGraph exports the FSM screen graph as Graphviz DOT or Mermaid.
+//
+//	go run ./cmd/fsmGraph -format dot | dot -Tsvg > fsm.svg
+//	go run ./cmd/fsmGraph -format mermaid -o fsm.mmd
+func main() {
+	graphPath := flag.String("graph", "references/fsmGraph.yaml", "path to the FSM graph")
+	usecasesDir := flag.String("usecases", "./usecases", "usecases directory")
+	format := flag.String("format", "dot", "output format: dot | mermaid")
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	// no region lookup: the export must work for a graph that is still being fixed
+	graph, err := config.LoadFSMGraph(*graphPath, nil)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+
+		graph, err = config.ParseFSMGraph(*graphPath)
+		if err != nil {
+			log.Fatalf("❌ Failed to load FSM graph: %v", err)
+		}
+	}
+
+	report := fsm.NewGraphReport(graph, config.NewUseCaseLoader(*usecasesDir).All())
+
+	for node, usecases := range report.Usecases {
+		if _, ok := graph.Screen(node); !ok {
+			log.Printf("⚠️ Node %q is not in the graph, used by: %v", node, usecases)
+		}
+	}
+
+	var out string
+	switch *format {
+	case "dot":
+		out = report.DOT()
+	case "mermaid":
+		out = report.Mermaid()
+	default:
+		log.Fatalf("❌ Unknown format %q (dot | mermaid)", *format)
+	}
+
+	if *output == "" {
+		fmt.Print(out)
+		return
+	}
+
+	if err := os.WriteFile(*output, []byte(out), 0644); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", *output, err)
+	}
+	log.Printf("✅ FSM graph written to %s", *output)
+}
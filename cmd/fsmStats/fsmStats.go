@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
+)
+
+// fsmStats prints the flakiest FSM transitions collected by the bot,
+// together with the regions they click, so broken region definitions are easy to spot.
+func main() {
+	redisAddr := flag.String("redis", "localhost:6379", "Redis address")
+	minAttempts := flag.Int64("min-attempts", 3, "ignore transitions executed fewer times")
+	limit := flag.Int("limit", 20, "number of transitions to show (0 – all)")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	rdb := redis.NewClient(&redis.Options{Addr: *redisAddr})
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Fatalf("❌ Redis unavailable: %v", err)
+	}
+
+	stats, err := repository.NewRedisEdgeStatsRepository(rdb).LoadEdgeStats(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to load edge stats: %v", err)
+	}
+
+	flaky := fsm.FlakiestEdges(stats, *minAttempts, *limit)
+	if len(flaky) == 0 {
+		fmt.Println("✅ No failing transitions recorded")
+		return
+	}
+
+	// regions are optional: the report is still useful without the graph
+	var graph *config.FSMGraph
+	if loader, err := config.DefaultFSMGraph(); err == nil {
+		graph = loader.Graph()
+	} else {
+		log.Printf("⚠️ FSM graph not loaded, regions are not shown: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FROM\tTO\tFAIL RATE\tFAILURES\tATTEMPTS\tAVG\tREGIONS")
+	for _, s := range flaky {
+		fmt.Fprintf(w, "%s\t%s\t%.0f%%\t%d\t%d\t%s\t%s\n",
+			s.From, s.To, s.FailureRate()*100, s.Failures, s.Attempts, s.AvgDuration().Round(10*time.Millisecond), regions(graph, s.From, s.To))
+	}
+	_ = w.Flush()
+}
+
+// regions lists click regions and swipe presets of a transition.
+func regions(graph *config.FSMGraph, from, to string) string {
+	if graph == nil {
+		return "-"
+	}
+
+	edge, ok := graph.Edge(from, to)
+	if !ok {
+		return "(not in graph)"
+	}
+
+	var names []string
+	for _, step := range edge.Steps {
+		switch {
+		case step.Click != "":
+			names = append(names, step.Click)
+		case step.Swipe != "":
+			names = append(names, "swipe:"+step.Swipe)
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
+)
+
+// heroPlan prints the heroes worth upgrading next for every gamer, from the roster of the last hero scan.
+func main() {
+	statePath := flag.String("state", "./db/state.yaml", "state file of the bot")
+	limit := flag.Int("limit", 5, "heroes per gamer (0 – all)")
+	flag.Parse()
+
+	state, err := repository.NewFileStateRepository(*statePath).LoadState(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Failed to load state: %v", err)
+	}
+	catalog := config.DefaultHeroCatalog()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GAMER\tHERO\tSCORE\tREASON")
+	for _, gamer := range state.Gamers {
+		plan := gamer.Heroes.WithCatalog(catalog).PlanUpgrades(*limit)
+		if len(plan) == 0 {
+			fmt.Fprintf(w, "%s\t-\t-\tno scanned heroes\n", gamer.Nickname)
+			continue
+		}
+		for _, up := range plan {
+			fmt.Fprintf(w, "%s\t%s\t%.1f\t%s\n", gamer.Nickname, up.Name, up.Score, up.Reason)
+		}
+	}
+	_ = w.Flush()
+}
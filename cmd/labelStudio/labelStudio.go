@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+	"log"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/labelstudio"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+const usage = `usage: labelStudio <command> [flags]
+
+commands:
+  push       send screenshots with the area.json boxes as pre-annotations
+  pull       regenerate area.json from the reviewed annotations
+  ocr-push   send screenshots with the OCR readings of their regions for correction
+  ocr-pull   write the corrected readings as the OCR ground truth dataset`
+
+// labelStudio moves regions and OCR readings between the repository and a Label Studio project
+// (LABEL_STUDIO_URL, LABEL_STUDIO_TOKEN). See docs/labelstudio.md for the project setup.
+func main() {
+	viper.AutomaticEnv()
+
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	ctx := context.Background()
+	client := labelstudio.NewClient()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "push":
+		err = push(ctx, client, args)
+	case "pull":
+		err = pull(ctx, client, args)
+	case "ocr-push":
+		err = ocrPush(ctx, client, args)
+	case "ocr-pull":
+		err = ocrPull(ctx, client, args)
+	default:
+		log.Fatal(usage)
+	}
+	if err != nil {
+		log.Fatalf("❌ %s: %v", cmd, err)
+	}
+}
+
+// screenFlags are the flags of the commands sending screenshots.
+type screenFlags struct {
+	project     *int
+	screenshots *string
+	area        *string
+	imagePrefix *string
+	match       *string
+}
+
+func newScreenFlags(fl *flag.FlagSet) screenFlags {
+	return screenFlags{
+		project:     fl.Int("project", 0, "Label Studio project id"),
+		screenshots: fl.String("screenshots", "references/screenshots", "screenshot dir"),
+		area:        fl.String("area", "references/area.json", "area file"),
+		imagePrefix: fl.String("image-prefix", "/data/local-files/?d=screenshots/", "URL of the screenshot dir as Label Studio serves it"),
+		match:       fl.String("match", "*.png", "file name pattern of the screenshots to send"),
+	}
+}
+
+// screens lists the screenshots as slash paths relative to the dir.
+func (f screenFlags) screens() ([]string, error) {
+	var screens []string
+	err := filepath.WalkDir(*f.screenshots, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ok, _ := path.Match(*f.match, d.Name()); !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(*f.screenshots, p)
+		if err != nil {
+			return err
+		}
+		screens = append(screens, filepath.ToSlash(rel))
+		return nil
+	})
+	return screens, err
+}
+
+func (f screenFlags) refs() ([]domain.AreaReference, error) {
+	if *f.project == 0 {
+		return nil, fmt.Errorf("-project is required")
+	}
+	return readArea(*f.area)
+}
+
+func push(ctx context.Context, client *labelstudio.Client, args []string) error {
+	fl := flag.NewFlagSet("push", flag.ExitOnError)
+	f := newScreenFlags(fl)
+	unreviewed := fl.Bool("unreviewed", false, "only the screenshots with area.json entries nobody reviewed")
+	_ = fl.Parse(args)
+
+	refs, err := f.refs()
+	if err != nil {
+		return err
+	}
+	screens, err := f.screens()
+	if err != nil {
+		return err
+	}
+	if *unreviewed {
+		entries, err := readEntries(*f.area)
+		if err != nil {
+			return err
+		}
+		pending := labelstudio.UnreviewedScreens(entries)
+		screens = slices.DeleteFunc(screens, func(screen string) bool {
+			return !slices.Contains(pending, path.Base(screen))
+		})
+	}
+
+	tasks := make([]labelstudio.Task, 0, len(screens))
+	annotated := 0
+	for _, screen := range screens {
+		task := labelstudio.AreaTask(screen, *f.imagePrefix+screen, refs)
+		if len(task.Predictions) > 0 {
+			annotated++
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := client.ImportTasks(ctx, *f.project, tasks); err != nil {
+		return err
+	}
+	fmt.Printf("📤 %d screenshots sent, %d with area.json boxes\n", len(tasks), annotated)
+	return nil
+}
+
+func pull(ctx context.Context, client *labelstudio.Client, args []string) error {
+	fl := flag.NewFlagSet("pull", flag.ExitOnError)
+	project := fl.Int("project", 0, "Label Studio project id")
+	area := fl.String("area", "references/area.json", "area file to update")
+	out := fl.String("out", "", "output file (default: -area)")
+	_ = fl.Parse(args)
+
+	if *project == 0 {
+		return fmt.Errorf("-project is required")
+	}
+	if *out == "" {
+		*out = *area
+	}
+
+	refs, err := readEntries(*area)
+	if err != nil {
+		return err
+	}
+	tasks, err := client.ExportTasks(ctx, *project)
+	if err != nil {
+		return err
+	}
+
+	refs, reviewed := labelstudio.MergeArea(refs, tasks)
+	if reviewed == 0 {
+		fmt.Println("✅ No reviewed screenshots")
+		return nil
+	}
+	if err := writeJSON(*out, refs); err != nil {
+		return err
+	}
+	fmt.Printf("💾 %d reviewed screenshots written to %s\n", reviewed, *out)
+	if pending := labelstudio.UnreviewedScreens(refs); len(pending) > 0 {
+		fmt.Printf("⚠️ Entries nobody reviewed yet: %s\n", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func ocrPush(ctx context.Context, client *labelstudio.Client, args []string) error {
+	fl := flag.NewFlagSet("ocr-push", flag.ExitOnError)
+	f := newScreenFlags(fl)
+	engineName := fl.String("engine", domain.EngineTesseract, "OCR engine reading screenshot files: tesseract or recorded")
+	_ = fl.Parse(args)
+
+	refs, err := f.refs()
+	if err != nil {
+		return err
+	}
+	screens, err := f.screens()
+	if err != nil {
+		return err
+	}
+	engine, err := newFileEngine(*engineName)
+	if err != nil {
+		return err
+	}
+
+	var tasks []labelstudio.Task
+	for _, screen := range screens {
+		// the area boxes of the screen, read one by one
+		var regions []labelstudio.Region
+		for _, ref := range refs {
+			if labelstudio.ScreenName(ref.OCR) != path.Base(screen) {
+				continue
+			}
+			for i, name := range ref.Transcription {
+				if i < len(ref.BBox) {
+					regions = append(regions, labelstudio.Region{ID: name, BBox: ref.BBox[i]})
+				}
+			}
+		}
+		if len(regions) == 0 {
+			continue
+		}
+
+		img, err := readImage(filepath.Join(*f.screenshots, filepath.FromSlash(screen)))
+		if err != nil {
+			return err
+		}
+		for i := range regions {
+			if regions[i].Text, err = labelstudio.ReadRegion(ctx, engine, img, regions[i].BBox); err != nil {
+				return fmt.Errorf("%s %s: %w", screen, regions[i].ID, err)
+			}
+		}
+		tasks = append(tasks, labelstudio.OCRTask(screen, *f.imagePrefix+screen, regions))
+	}
+
+	if err := client.ImportTasks(ctx, *f.project, tasks); err != nil {
+		return err
+	}
+	fmt.Printf("📤 %d screenshots sent with %s readings\n", len(tasks), engine.Name())
+	return nil
+}
+
+func ocrPull(ctx context.Context, client *labelstudio.Client, args []string) error {
+	fl := flag.NewFlagSet("ocr-pull", flag.ExitOnError)
+	project := fl.Int("project", 0, "Label Studio project id")
+	out := fl.String("out", "references/ocr_groundtruth.json", "dataset file")
+	screenshots := fl.String("screenshots", "screenshots", "screenshot dir, relative to the dataset file")
+	engineName := fl.String("engine", domain.EngineTesseract, "engine the readings were pushed with")
+	minAccuracy := fl.Float64("min-accuracy", 0.9, "accuracy every region must keep in the regression test")
+	_ = fl.Parse(args)
+
+	if *project == 0 {
+		return fmt.Errorf("-project is required")
+	}
+
+	tasks, err := client.ExportTasks(ctx, *project)
+	if err != nil {
+		return err
+	}
+
+	gt := &labelstudio.GroundTruth{
+		Engine:      *engineName,
+		Screenshots: *screenshots,
+		MinAccuracy: *minAccuracy,
+		Samples:     labelstudio.Samples(tasks),
+	}
+	if len(gt.Samples) == 0 {
+		fmt.Println("✅ No reviewed readings")
+		return nil
+	}
+	if err := gt.Save(*out); err != nil {
+		return err
+	}
+
+	corrected := 0
+	for _, s := range gt.Samples {
+		if s.OCR != s.Text {
+			corrected++
+		}
+	}
+	fmt.Printf("💾 %d readings (%d corrected) written to %s\n", len(gt.Samples), corrected, *out)
+	return nil
+}
+
+// newFileEngine creates an engine that can read screenshot files; the OCR service reads device screens only.
+func newFileEngine(name string) (ocrclient.OCREngine, error) {
+	if name == domain.EngineHTTP {
+		return nil, fmt.Errorf("the %s engine reads device screens, use %s", name, domain.EngineTesseract)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	return ocrclient.NewEngine(name, nil, logger)
+}
+
+func readImage(file string) (image.Image, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", file, err)
+	}
+	return img, nil
+}
+
+func readArea(file string) ([]domain.AreaReference, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []domain.AreaReference
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return refs, nil
+}
+
+// readEntries reads area.json with the annotation fields.
+func readEntries(file string) ([]labelstudio.AreaEntry, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []labelstudio.AreaEntry
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return refs, nil
+}
+
+func writeJSON(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
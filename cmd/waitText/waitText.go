@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -21,7 +22,7 @@ func main() {
 	debugName := "screenState.titleFact"
 
 	// 3) Start waiting
-	results, err := client.WaitForText(stopWords, timeout, interval, debugName)
+	results, err := client.WaitForText(context.Background(), stopWords, timeout, interval, debugName)
 	if err != nil {
 		logger.Error("WaitForText failed", "error", err)
 		return
@@ -1,68 +1,91 @@
 package adb
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"log/slog"
 	"math/rand"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/spf13/viper"
+
 	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
 	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
 )
 
 // DeviceController defines the interface for interacting with an Android device via ADB.
+// Cancelling ctx interrupts the adb process of the call.
 type DeviceController interface {
-	ListDevices() ([]string, error)
+	ListDevices(ctx context.Context) ([]string, error)
 	SetActiveDevice(serial string)
 	GetActiveDevice() string
-	RestartApplication() error
+	RestartApplication(ctx context.Context) error
 
-	Click(region image.Rectangle) error
-	ClickRegion(name string, area *config.AreaLookup) error
-	ClickOCRResult(result *domain.OCRResult) error
+	Click(ctx context.Context, region image.Rectangle) error
+	ClickRegion(ctx context.Context, name string, area *config.AreaLookup) error
+	ClickOCRResult(ctx context.Context, result *domain.OCRResult) error
 
-	Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error
-	SwipeDirection(direction string, delta int, durationMs time.Duration) error
+	Swipe(ctx context.Context, x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error
+	SwipeDirection(ctx context.Context, direction string, delta int, durationMs time.Duration) error
 }
 
-// The Controller implements the DeviceController interface using the adb CLI tool.
+// The Controller implements the DeviceController interface using the adb CLI tool,
+// or a persistent shell session through the ADB server when ADB_TRANSPORT=socket.
 type Controller struct {
 	deviceID string
 	logger   *slog.Logger
+	socket   *SocketShell // nil – every command spawns `adb shell`
 }
 
 // NewController creates a new instance of the Controller.
 func NewController(logger *slog.Logger, name string) (*Controller, error) {
+	viper.SetDefault("ADB_TRANSPORT", TransportSocket)
+	viper.SetDefault("ADB_SERVER_ADDR", DefaultServerAddr)
+
 	c := &Controller{
 		logger:   logger,
 		deviceID: name,
 	}
 
+	if viper.GetString("ADB_TRANSPORT") == TransportSocket {
+		c.socket = NewSocketShell(viper.GetString("ADB_SERVER_ADDR"), name, logger)
+	}
+
+	ctx := context.Background()
+
 	// Verify device availability
-	if err := verifyDeviceAvailable(name); err != nil {
+	if err := verifyDeviceAvailable(ctx, name); err != nil {
 		panic(fmt.Sprintf("❌ %v", err))
 	}
 
 	// Set brightness to 70% on startup
-	if err := c.SetBrightness(70); err != nil {
+	if err := c.SetBrightness(ctx, 70); err != nil {
 		logger.Warn("Failed to set initial brightness", slog.Any("error", err))
 	}
 
-	if err := c.SetHeadsUpNotifications(false); err != nil {
+	if err := c.SetHeadsUpNotifications(ctx, false); err != nil {
 		logger.Warn("Failed to set initial heads-up notifications", slog.Any("error", err))
 	}
 
 	return c, nil
 }
 
+// Close closes the shell session of the socket transport.
+func (a *Controller) Close() error {
+	if a.socket == nil {
+		return nil
+	}
+	return a.socket.Close()
+}
+
 // ListDevices returns all connected ADB devices.
-func (a *Controller) ListDevices() ([]string, error) {
-	out, err := exec.Command("adb", "devices").Output()
+func (a *Controller) ListDevices(ctx context.Context) ([]string, error) {
+	out, err := run(ctx, "devices")
 	if err != nil {
 		return nil, fmt.Errorf("adb not found or failed to list devices: %w", err)
 	}
@@ -93,7 +116,7 @@ func (a *Controller) GetActiveDevice() string {
 
 // ClickRegion performs a tap action in the center of the named region with slight random offset,
 // clamping the result to stay inside the bounding box.
-func (a *Controller) ClickRegion(name string, area *config.AreaLookup) error {
+func (a *Controller) ClickRegion(ctx context.Context, name string, area *config.AreaLookup) error {
 	bbox, err := area.GetRegionByName(name)
 	if err != nil {
 		return fmt.Errorf("region '%s' not found: %w", name, err)
@@ -110,23 +133,12 @@ func (a *Controller) ClickRegion(name string, area *config.AreaLookup) error {
 	randX := clamp(centerX+randInt(-offsetX, offsetX), x, x+w-1)
 	randY := clamp(centerY+randInt(-offsetY, offsetY), y, y+h-1)
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "input", "tap",
-		strconv.Itoa(randX), strconv.Itoa(randY),
-	)
-	err = cmd.Run()
-	if err != nil {
-		a.logger.Error("Failed to execute tap command", slog.Any("error", err))
-		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "click").Inc()
-
-		return fmt.Errorf("failed to perform tap: %w", err)
-	}
-
-	return nil
+	return a.tap(ctx, randX, randY, name)
 }
 
 // Click performs a tap action in the center of the given region with slight random offset,
 // clamping the result to stay inside the bounding box.
-func (a *Controller) Click(region image.Rectangle) error {
+func (a *Controller) Click(ctx context.Context, region image.Rectangle) error {
 	x := region.Min.X
 	y := region.Min.Y
 	w := region.Dx()
@@ -141,23 +153,12 @@ func (a *Controller) Click(region image.Rectangle) error {
 	randX := clamp(centerX+randInt(-offsetX, offsetX), x, x+w-1)
 	randY := clamp(centerY+randInt(-offsetY, offsetY), y, y+h-1)
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "input", "tap",
-		strconv.Itoa(randX), strconv.Itoa(randY),
-	)
-	err := cmd.Run()
-	if err != nil {
-		a.logger.Error("Failed to execute tap command", slog.Any("error", err))
-		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "click").Inc()
-
-		return fmt.Errorf("failed to perform tap: %w", err)
-	}
-
-	return nil
+	return a.tap(ctx, randX, randY, "")
 }
 
 // ClickOCRResult performs a tap action in the center of the OCR result bounding box with slight random offset,
 // clamping the result to stay inside the bounding box.
-func (a *Controller) ClickOCRResult(result *domain.OCRResult) error {
+func (a *Controller) ClickOCRResult(ctx context.Context, result *domain.OCRResult) error {
 	x, y, w, h := result.X, result.Y, result.Width, result.Height
 
 	centerX := x + w/2
@@ -169,10 +170,12 @@ func (a *Controller) ClickOCRResult(result *domain.OCRResult) error {
 	randX := clamp(centerX+randInt(-offsetX, offsetX), x, x+w-1)
 	randY := clamp(centerY+randInt(-offsetY, offsetY), y, y+h-1)
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "input", "tap",
-		strconv.Itoa(randX), strconv.Itoa(randY),
-	)
-	err := cmd.Run()
+	return a.tap(ctx, randX, randY, result.Text)
+}
+
+// tap taps the screen point and adds it to the latest archived frame of the device.
+func (a *Controller) tap(ctx context.Context, x, y int, target string) error {
+	_, err := a.shell(ctx, "input", "tap", strconv.Itoa(x), strconv.Itoa(y))
 	if err != nil {
 		a.logger.Error("Failed to execute tap command", slog.Any("error", err))
 		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "click").Inc()
@@ -180,6 +183,9 @@ func (a *Controller) ClickOCRResult(result *domain.OCRResult) error {
 		return fmt.Errorf("failed to perform tap: %w", err)
 	}
 
+	if err := framearchive.Default().RecordClick(ctx, a.deviceID, x, y, target); err != nil {
+		a.logger.Warn("⚠️ Click not archived", slog.Any("error", err))
+	}
 	return nil
 }
 
@@ -193,7 +199,7 @@ func randInt(min, max int) int {
 
 // Swipe performs a swipe gesture from (x1, y1) to (x2, y2) in the given duration (ms),
 // adding slight randomness to simulate natural finger movement.
-func (a *Controller) Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error {
+func (a *Controller) Swipe(ctx context.Context, x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error {
 	// Add "jitter" ±2 pixels
 	jitter := func(v int) int {
 		return v + randInt(-2, 2)
@@ -204,12 +210,6 @@ func (a *Controller) Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Durat
 	endX := jitter(x2)
 	endY := jitter(y2)
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "input", "touchscreen", "swipe",
-		strconv.Itoa(startX), strconv.Itoa(startY),
-		strconv.Itoa(endX), strconv.Itoa(endY),
-		strconv.Itoa(int(durationMs.Milliseconds())),
-	)
-
 	a.logger.Info("Swipe with jitter",
 		slog.Int("startX", startX),
 		slog.Int("startY", startY),
@@ -218,7 +218,11 @@ func (a *Controller) Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Durat
 		slog.Duration("duration", durationMs),
 	)
 
-	err := cmd.Run()
+	_, err := a.shell(ctx, "input", "touchscreen", "swipe",
+		strconv.Itoa(startX), strconv.Itoa(startY),
+		strconv.Itoa(endX), strconv.Itoa(endY),
+		strconv.Itoa(int(durationMs.Milliseconds())),
+	)
 	if err != nil {
 		a.logger.Error("Failed to execute swipe command", slog.Any("error", err))
 		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "swipe").Inc()
@@ -230,7 +234,7 @@ func (a *Controller) Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Durat
 }
 
 // LongTapRegion performs a long press in the center of the named region with jitter using the Swipe method.
-func (a *Controller) LongTapRegion(name string, area *config.AreaLookup, durationMs time.Duration) error {
+func (a *Controller) LongTapRegion(ctx context.Context, name string, area *config.AreaLookup, durationMs time.Duration) error {
 	bbox, err := area.GetRegionByName(name)
 	if err != nil {
 		return fmt.Errorf("region '%s' not found: %w", name, err)
@@ -248,14 +252,13 @@ func (a *Controller) LongTapRegion(name string, area *config.AreaLookup, duratio
 	)
 
 	// Simply use Swipe with identical coordinates and built-in jitter
-	return a.Swipe(centerX, centerY, centerX, centerY, durationMs)
+	return a.Swipe(ctx, centerX, centerY, centerX, centerY, durationMs)
 }
 
 // GetScreenResolution calls the ADB shell command "wm size",
 // parses the result and returns the actual screen resolution (width, height).
-func (a *Controller) GetScreenResolution() (int, int, error) {
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "wm", "size")
-	out, err := cmd.Output()
+func (a *Controller) GetScreenResolution(ctx context.Context) (int, int, error) {
+	out, err := a.shell(ctx, "wm", "size")
 	if err != nil {
 		a.logger.Error("Failed to get screen resolution", slog.Any("error", err))
 		return 0, 0, fmt.Errorf("failed to get screen resolution: %w", err)
@@ -289,7 +292,10 @@ func (a *Controller) GetScreenResolution() (int, int, error) {
 					if err1 == nil && err2 == nil {
 						w, h = wi, hi
 						matched = true
-						break
+						// the override (wm size WxH) is what the screen actually renders
+						if strings.Contains(line, "Override size:") {
+							break
+						}
 					}
 				}
 			}
@@ -319,8 +325,8 @@ func clamp(val, min, max int) int {
 }
 
 // verifyDeviceAvailable checks if the given ADB device is connected and ready.
-func verifyDeviceAvailable(deviceID string) error {
-	output, err := exec.Command("adb", "devices").Output()
+func verifyDeviceAvailable(ctx context.Context, deviceID string) error {
+	output, err := run(ctx, "devices")
 	if err != nil {
 		return fmt.Errorf("failed to list devices: %w", err)
 	}
@@ -337,8 +343,8 @@ func verifyDeviceAvailable(deviceID string) error {
 }
 
 // SwipeDirection performs a swipe in the given direction (left, right, up, down) by delta pixels from the center of the screen.
-func (a *Controller) SwipeDirection(direction string, delta int, durationMs time.Duration) error {
-	width, height, err := a.GetScreenResolution()
+func (a *Controller) SwipeDirection(ctx context.Context, direction string, delta int, durationMs time.Duration) error {
+	width, height, err := a.GetScreenResolution(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get screen resolution: %w", err)
 	}
@@ -370,5 +376,5 @@ func (a *Controller) SwipeDirection(direction string, delta int, durationMs time
 		slog.Duration("duration", durationMs),
 	)
 
-	return a.Swipe(x1, y1, x2, y2, durationMs)
+	return a.Swipe(ctx, x1, y1, x2, y2, durationMs)
 }
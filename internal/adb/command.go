@@ -0,0 +1,63 @@
+package adb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("adb")
+
+// shell runs a shell command on the device: over the persistent socket session if the controller has one,
+// otherwise (or when no session can be opened) by spawning `adb shell`. A command sent over the socket
+// is never repeated by exec, even if its session broke: it may already have run.
+// Cancelling ctx kills a hung adb process.
+func (a *Controller) shell(ctx context.Context, args ...string) (out []byte, err error) {
+	ctx, span := startSpan(ctx, args)
+	defer func() { endSpan(span, err) }()
+
+	if a.socket != nil {
+		out, err = a.socket.Run(ctx, args...)
+		if !errors.Is(err, ErrSessionUnavailable) || ctx.Err() != nil {
+			return out, err
+		}
+
+		a.logger.Warn("⚠️ ADB socket unavailable, falling back to adb exec", slog.Any("error", err))
+		span.SetAttributes(attribute.Bool("adb.fallback", true))
+	}
+
+	return exec.CommandContext(ctx, "adb", append([]string{"-s", a.deviceID, "shell"}, args...)...).Output()
+}
+
+// run executes a host-level adb command, e.g. `adb devices`.
+func run(ctx context.Context, args ...string) (out []byte, err error) {
+	ctx, span := startSpan(ctx, args)
+	defer func() { endSpan(span, err) }()
+
+	return exec.CommandContext(ctx, "adb", args...).Output()
+}
+
+// startSpan starts a span named after the command, e.g. "adb input tap" or "adb devices".
+func startSpan(ctx context.Context, args []string) (context.Context, trace.Span) {
+	name := args
+	if len(name) > 2 {
+		name = name[:2]
+	}
+	return tracer.Start(ctx, "adb "+strings.Join(name, " "),
+		trace.WithAttributes(attribute.String("adb.args", strings.Join(args, " "))))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
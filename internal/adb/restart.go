@@ -1,30 +1,32 @@
 package adb
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"os/exec"
 	"time"
 )
 
 const gamePackageName = "com.gof.global"
 
 // RestartApplication restarts the application via adb commands.
-func (a *Controller) RestartApplication() error {
+func (a *Controller) RestartApplication(ctx context.Context) error {
 	a.logger.Warn("🔄 Restarting application", slog.String("package", gamePackageName))
 
 	// Close the application
-	closeCmd := exec.Command("adb", "-s", a.deviceID, "shell", "am", "force-stop", gamePackageName)
-	if err := closeCmd.Run(); err != nil {
+	if _, err := a.shell(ctx, "am", "force-stop", gamePackageName); err != nil {
 		a.logger.Error("❌ Error closing application", slog.String("package", gamePackageName), slog.Any("error", err))
 		return fmt.Errorf("failed to close app %s: %w", gamePackageName, err)
 	}
 
-	time.Sleep(2 * time.Second)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(2 * time.Second):
+	}
 
 	// Start the application again
-	startCmd := exec.Command("adb", "-s", a.deviceID, "shell", "monkey", "-p", gamePackageName, "-c", "android.intent.category.LAUNCHER", "1")
-	if err := startCmd.Run(); err != nil {
+	if _, err := a.shell(ctx, "monkey", "-p", gamePackageName, "-c", "android.intent.category.LAUNCHER", "1"); err != nil {
 		a.logger.Error("❌ Error starting application", slog.String("package", gamePackageName), slog.Any("error", err))
 		return fmt.Errorf("failed to start app %s: %w", gamePackageName, err)
 	}
@@ -1,23 +1,22 @@
 package adb
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"os/exec"
 	"strconv"
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
 )
 
 // SetHeadsUpNotifications enables or disables heads-up notifications.
-func (a *Controller) SetHeadsUpNotifications(enabled bool) error {
+func (a *Controller) SetHeadsUpNotifications(ctx context.Context, enabled bool) error {
 	value := "1"
 	if !enabled {
 		value = "0"
 	}
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "settings", "put", "global", "heads_up_notifications_enabled", value)
-	err := cmd.Run()
+	_, err := a.shell(ctx, "settings", "put", "global", "heads_up_notifications_enabled", value)
 	if err != nil {
 		a.logger.Error("Failed to set heads-up notifications", slog.Any("error", err), slog.String("value", value))
 		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "heads_up_notifications").Inc()
@@ -30,7 +29,7 @@ func (a *Controller) SetHeadsUpNotifications(enabled bool) error {
 }
 
 // SetBrightness sets the screen brightness on the device to the given percentage (0-100).
-func (a *Controller) SetBrightness(percent int) error {
+func (a *Controller) SetBrightness(ctx context.Context, percent int) error {
 	if percent < 0 {
 		percent = 0
 	}
@@ -39,8 +38,7 @@ func (a *Controller) SetBrightness(percent int) error {
 	}
 	value := int(float64(percent) / 100.0 * 255.0)
 
-	cmd := exec.Command("adb", "-s", a.deviceID, "shell", "settings", "put", "system", "screen_brightness", strconv.Itoa(value))
-	err := cmd.Run()
+	_, err := a.shell(ctx, "settings", "put", "system", "screen_brightness", strconv.Itoa(value))
 	if err != nil {
 		a.logger.Error("Failed to set brightness", slog.Any("error", err), slog.Int("value", value))
 		metrics.ADBErrorTotal.WithLabelValues(a.deviceID, "brightness").Inc()
@@ -0,0 +1,240 @@
+package adb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transports of the controller.
+const (
+	TransportExec   = "exec"   // spawn `adb shell` for every command
+	TransportSocket = "socket" // one persistent shell session through the ADB server
+)
+
+// DefaultServerAddr is where the local ADB server listens.
+const DefaultServerAddr = "127.0.0.1:5037"
+
+// ErrSessionUnavailable is returned when no shell session could be opened, i.e. the command was not sent.
+var ErrSessionUnavailable = errors.New("adb shell session unavailable")
+
+// ShellExitError is returned when a command ran on the device but exited with a non-zero status.
+type ShellExitError struct {
+	Command string
+	Code    int
+	Output  []byte
+}
+
+func (e *ShellExitError) Error() string {
+	return fmt.Sprintf("%q exited with status %d: %s", e.Command, e.Code, bytes.TrimSpace(e.Output))
+}
+
+// SocketShell runs shell commands through the ADB server protocol over TCP
+// (host:transport:<serial>, then exec:sh) and keeps the sh session open between commands,
+// so a tap costs one round trip instead of starting an adb process.
+type SocketShell struct {
+	addr     string
+	deviceID string
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	session *shellSession
+	seq     uint64
+}
+
+// NewSocketShell creates a shell for the device; the session is opened on the first command.
+func NewSocketShell(addr, deviceID string, logger *slog.Logger) *SocketShell {
+	if addr == "" {
+		addr = DefaultServerAddr
+	}
+	return &SocketShell{addr: addr, deviceID: deviceID, logger: logger}
+}
+
+// Run executes the command (arguments are joined with spaces, like `adb shell` does) and returns its output.
+// A session that died while idle is reopened before the command is sent; a command that was sent
+// is never repeated, a broken session fails it.
+func (s *SocketShell) Run(ctx context.Context, args ...string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session != nil && !s.session.alive() {
+		s.logger.Warn("🔌 ADB shell session dropped, reconnecting", slog.String("device", s.deviceID))
+		_ = s.session.close()
+		s.session = nil
+	}
+
+	if s.session == nil {
+		session, err := openSession(ctx, s.addr, s.deviceID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSessionUnavailable, err)
+		}
+		s.session = session
+	}
+
+	cmd := strings.Join(args, " ")
+	s.seq++
+	out, code, err := s.session.run(ctx, s.seq, cmd)
+	if err != nil {
+		// the session is out of sync after any failure
+		_ = s.session.close()
+		s.session = nil
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if code != 0 {
+		return out, &ShellExitError{Command: cmd, Code: code, Output: out}
+	}
+	return out, nil
+}
+
+// Close closes the shell session.
+func (s *SocketShell) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session == nil {
+		return nil
+	}
+	err := s.session.close()
+	s.session = nil
+	return err
+}
+
+// shellSession is an `sh` running on the device, fed through its stdin.
+type shellSession struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func openSession(ctx context.Context, addr, deviceID string) (*shellSession, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect adb server %s: %w", addr, err)
+	}
+
+	stop := bindContext(ctx, conn)
+	defer stop()
+
+	for _, service := range []string{"host:transport:" + deviceID, "exec:sh"} {
+		if err := request(conn, service); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return &shellSession{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// request sends one service request of the ADB server protocol and reads its OKAY/FAIL status.
+func request(conn net.Conn, service string) error {
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(service), service); err != nil {
+		return fmt.Errorf("adb %s: %w", service, err)
+	}
+
+	status := make([]byte, 4)
+	if _, err := io.ReadFull(conn, status); err != nil {
+		return fmt.Errorf("adb %s: read status: %w", service, err)
+	}
+
+	switch string(status) {
+	case "OKAY":
+		return nil
+	case "FAIL":
+		msg, err := readLengthPrefixed(conn)
+		if err != nil {
+			return fmt.Errorf("adb %s failed: %w", service, err)
+		}
+		return fmt.Errorf("adb %s failed: %s", service, msg)
+	default:
+		return fmt.Errorf("adb %s: unexpected status %q", service, status)
+	}
+}
+
+func readLengthPrefixed(r io.Reader) (string, error) {
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", err
+	}
+	n, err := strconv.ParseUint(string(size), 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("bad length %q", size)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return "", err
+	}
+	return string(msg), nil
+}
+
+// alive reports whether an idle session is still usable: the server hasn't closed it
+// and no stray output is waiting (which would put the markers out of sync).
+func (s *shellSession) alive() bool {
+	_ = s.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer func() { _ = s.conn.SetReadDeadline(time.Time{}) }()
+
+	_, err := s.r.Peek(1)
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// run sends the command followed by an echo of a unique marker with the exit status,
+// and reads the output up to the marker.
+func (s *shellSession) run(ctx context.Context, seq uint64, cmd string) (out []byte, code int, err error) {
+	stop := bindContext(ctx, s.conn)
+	defer stop()
+
+	marker := fmt.Sprintf("__adb_done_%d__", seq)
+	if _, err := fmt.Fprintf(s.conn, "%s </dev/null 2>&1; echo %s$?\n", cmd, marker); err != nil {
+		return nil, 0, fmt.Errorf("send %q: %w", cmd, err)
+	}
+
+	var buf bytes.Buffer
+	for {
+		line, err := s.r.ReadString('\n')
+		if i := strings.Index(line, marker); i >= 0 {
+			buf.WriteString(line[:i])
+			code, convErr := strconv.Atoi(strings.TrimSpace(line[i+len(marker):]))
+			if convErr != nil {
+				return buf.Bytes(), 0, fmt.Errorf("parse exit status of %q: %w", cmd, convErr)
+			}
+			return buf.Bytes(), code, nil
+		}
+		buf.WriteString(line)
+
+		if err != nil {
+			return buf.Bytes(), 0, fmt.Errorf("read output of %q: %w", cmd, err)
+		}
+	}
+}
+
+func (s *shellSession) close() error {
+	return s.conn.Close()
+}
+
+// bindContext interrupts blocked reads and writes on conn once ctx is done,
+// so callers see ctx.Err() rather than a bare i/o timeout.
+func bindContext(ctx context.Context, conn net.Conn) func() {
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+
+	return func() {
+		stop()
+		_ = conn.SetDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,305 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeADBServer speaks the host:transport + exec:sh part of the ADB server protocol.
+type fakeADBServer struct {
+	t        testing.TB
+	ln       net.Listener
+	dropFrom int // close a session after it answered this many commands (0 – never)
+
+	mu       sync.Mutex
+	commands []string
+	sessions int
+}
+
+var commandLine = regexp.MustCompile(`^(.*) </dev/null 2>&1; echo (__adb_done_\d+__)\$\?$`)
+
+func startFakeADBServer(t testing.TB) *fakeADBServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeADBServer{t: t, ln: ln}
+	go s.accept()
+	t.Cleanup(func() { _ = ln.Close() })
+
+	return s
+}
+
+func (s *fakeADBServer) Addr() string { return s.ln.Addr().String() }
+
+func (s *fakeADBServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeADBServer) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions
+}
+
+func (s *fakeADBServer) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *fakeADBServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for _, want := range []string{"host:transport:", "exec:sh"} {
+		service, err := readLengthPrefixed(conn)
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(service, want) {
+			msg := "unsupported service " + service
+			_, _ = fmt.Fprintf(conn, "FAIL%04x%s", len(msg), msg)
+			return
+		}
+		if service == "host:transport:offline" {
+			msg := "device offline"
+			_, _ = fmt.Fprintf(conn, "FAIL%04x%s", len(msg), msg)
+			return
+		}
+		_, _ = io.WriteString(conn, "OKAY")
+	}
+
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	for answered := 0; ; answered++ {
+		if s.dropFrom > 0 && answered == s.dropFrom {
+			return
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		m := commandLine.FindStringSubmatch(strings.TrimSuffix(line, "\n"))
+		if m == nil {
+			s.t.Errorf("unexpected shell input %q", line)
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, m[1])
+		s.mu.Unlock()
+
+		out, code := "", 0
+		switch {
+		case m[1] == "wm size":
+			out = "Physical size: 1080x2400\n"
+		case m[1] == "printf partial":
+			out = "partial"
+		case strings.HasPrefix(m[1], "false"):
+			out, code = "boom\n", 1
+		case strings.HasPrefix(m[1], "sleep"):
+			time.Sleep(time.Second)
+		case strings.HasPrefix(m[1], "reboot"):
+			return // the session breaks after the command was received
+		}
+		if _, err := fmt.Fprintf(conn, "%s%s%d\n", out, m[2], code); err != nil {
+			return
+		}
+	}
+}
+
+func newSocketController(addr string) *Controller {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &Controller{
+		deviceID: "emulator-5554",
+		logger:   logger,
+		socket:   NewSocketShell(addr, "emulator-5554", logger),
+	}
+}
+
+func TestSocketShell_KeepsOneSession(t *testing.T) {
+	server := startFakeADBServer(t)
+	c := newSocketController(server.Addr())
+	defer c.Close()
+
+	ctx := context.Background()
+	require.NoError(t, c.Click(ctx, image.Rect(10, 20, 11, 21)))
+	require.NoError(t, c.Swipe(ctx, 100, 200, 300, 400, 300*time.Millisecond))
+
+	w, h, err := c.GetScreenResolution(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1080, w)
+	assert.Equal(t, 2400, h)
+
+	commands := server.Commands()
+	require.Len(t, commands, 3)
+	assert.Equal(t, "input tap 10 20", commands[0])
+	assert.True(t, strings.HasPrefix(commands[1], "input touchscreen swipe "), commands[1])
+	assert.True(t, strings.HasSuffix(commands[1], " 300"), commands[1])
+	assert.Equal(t, "wm size", commands[2])
+	assert.Equal(t, 1, server.Sessions())
+}
+
+func TestSocketShell_OutputWithoutNewline(t *testing.T) {
+	server := startFakeADBServer(t)
+	shell := NewSocketShell(server.Addr(), "emulator-5554", slog.Default())
+	defer shell.Close()
+
+	out, err := shell.Run(context.Background(), "printf", "partial")
+	require.NoError(t, err)
+	assert.Equal(t, "partial", string(out))
+}
+
+func TestSocketShell_ExitStatus(t *testing.T) {
+	server := startFakeADBServer(t)
+	shell := NewSocketShell(server.Addr(), "emulator-5554", slog.Default())
+	defer shell.Close()
+
+	_, err := shell.Run(context.Background(), "false")
+
+	var exitErr *ShellExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.Code)
+	assert.Equal(t, "boom\n", string(exitErr.Output))
+
+	// the session survives a failed command
+	_, err = shell.Run(context.Background(), "wm", "size")
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.Sessions())
+}
+
+func TestSocketShell_ReconnectsDroppedSession(t *testing.T) {
+	server := startFakeADBServer(t)
+	server.dropFrom = 1
+	shell := NewSocketShell(server.Addr(), "emulator-5554", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer shell.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := shell.Run(ctx, "input", "tap", strconv.Itoa(i), "0")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"input tap 0 0", "input tap 1 0", "input tap 2 0"}, server.Commands())
+	assert.Equal(t, 3, server.Sessions())
+}
+
+func TestSocketShell_NoReplayAfterSend(t *testing.T) {
+	fakeADB(t)
+	server := startFakeADBServer(t)
+	c := newSocketController(server.Addr())
+	defer c.Close()
+
+	// neither the socket nor the exec fallback repeats a command that may already have run
+	_, err := c.shell(context.Background(), "reboot")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrSessionUnavailable)
+	assert.Equal(t, []string{"reboot"}, server.Commands())
+
+	_, err = c.shell(context.Background(), "wm", "size")
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.Sessions())
+}
+
+func TestSocketShell_DeviceFailure(t *testing.T) {
+	server := startFakeADBServer(t)
+	shell := NewSocketShell(server.Addr(), "offline", slog.Default())
+
+	_, err := shell.Run(context.Background(), "wm", "size")
+	require.ErrorIs(t, err, ErrSessionUnavailable)
+	assert.Contains(t, err.Error(), "device offline")
+}
+
+func TestSocketShell_Cancel(t *testing.T) {
+	server := startFakeADBServer(t)
+	shell := NewSocketShell(server.Addr(), "emulator-5554", slog.Default())
+	defer shell.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := shell.Run(ctx, "sleep", "1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// the next command gets a fresh session
+	_, err = shell.Run(context.Background(), "wm", "size")
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.Sessions())
+}
+
+func TestController_FallsBackToExec(t *testing.T) {
+	fakeADB(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close()) // nothing listens there anymore
+
+	c := newSocketController(addr)
+	w, h, err := c.GetScreenResolution(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 720, w)
+	assert.Equal(t, 1280, h)
+}
+
+// fakeADB puts an `adb` script on PATH that answers `wm size` and accepts everything else.
+func fakeADB(tb testing.TB) {
+	dir := tb.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in *\"wm size\"*) echo 'Physical size: 720x1280';; esac\n"
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "adb"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func BenchmarkClick_Socket(b *testing.B) {
+	server := startFakeADBServer(b)
+	c := newSocketController(server.Addr())
+	defer c.Close()
+
+	benchmarkClick(b, c)
+}
+
+func BenchmarkClick_Exec(b *testing.B) {
+	fakeADB(b)
+	c := &Controller{deviceID: "emulator-5554", logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	benchmarkClick(b, c)
+}
+
+func benchmarkClick(b *testing.B, c *Controller) {
+	ctx := context.Background()
+	rect := image.Rect(10, 20, 11, 21)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.Click(ctx, rect); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
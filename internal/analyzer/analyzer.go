@@ -5,15 +5,22 @@ import (
 	"fmt"
 	"image"
 	"log/slog"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/spf13/viper"
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
 	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
 	"github.com/batazor/whiteout-survival-autopilot/internal/parser"
 	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
 )
 
 type Analyzer struct {
@@ -22,48 +29,93 @@ type Analyzer struct {
 	triggerEvaluator config.TriggerEvaluator
 	usecaseLoader    config.UseCaseLoader
 	ocrClient        *ocrclient.Client
+	iconMatcher      *vision.IconMatcher
+	screen           vision.ScreenSource
+	defaultMatcher   string
+	history          *readingHistory
+	locale           parser.Locale
+	catalog          *config.Catalog
+	archive          *framearchive.Archive // nil – frames aren't archived
+
+	engineName     string  // OCR engine of rules without "engine"
+	fallbackEngine string  // re-reads weak regions of any engine, "" – none
+	minScore       float64 // boxes scored lower are re-read by the fallback engine
+	enginesMu      sync.Mutex
+	engines        map[string]ocrclient.OCREngine
 }
 
+// sharedHistory outlives the analyzers: every bot and FSM builds its own analyzer on a gamer switch,
+// and the readings are keyed by gamer, so votes and medians carry over.
+var sharedHistory = newReadingHistory()
+
 func NewAnalyzer(areas *config.AreaLookup, logger *slog.Logger, ocrClient *ocrclient.Client) *Analyzer {
-	return &Analyzer{
+	viper.SetDefault("ICON_MATCHER", domain.MatcherRemote)
+	viper.SetDefault("PATH_TO_ICONS", "references/icons")
+	viper.SetDefault("OCR_LOCALE", "en")
+	viper.SetDefault("OCR_ENGINE", domain.EngineHTTP)
+	viper.SetDefault("OCR_FALLBACK_ENGINE", "")
+	viper.SetDefault("OCR_FALLBACK_MIN_SCORE", 0.8)
+
+	locale, err := parser.LocaleByName(viper.GetString("OCR_LOCALE"))
+	if err != nil {
+		logger.Warn("⚠️ Unknown OCR locale, using en", slog.Any("error", err))
+		locale = parser.English
+	}
+
+	a := &Analyzer{
 		areas:            areas,
 		logger:           logger,
 		triggerEvaluator: config.NewTriggerEvaluator(),
 		usecaseLoader:    config.NewUseCaseLoader("./usecases"),
 		ocrClient:        ocrClient,
+		defaultMatcher:   viper.GetString("ICON_MATCHER"),
+		history:          sharedHistory,
+		locale:           locale,
+		catalog:          config.DefaultCatalog(),
+		archive:          framearchive.Default(),
+		engineName:       viper.GetString("OCR_ENGINE"),
+		fallbackEngine:   viper.GetString("OCR_FALLBACK_ENGINE"),
+		minScore:         viper.GetFloat64("OCR_FALLBACK_MIN_SCORE"),
 	}
+
+	if ocrClient != nil {
+		a.screen = vision.ADBScreen(ocrClient.DeviceID)
+		a.iconMatcher = vision.NewIconMatcher(viper.GetString("PATH_TO_ICONS"), a.screen)
+		a.iconMatcher.Scale = areas.Scale().Factor
+	}
+
+	return a
 }
 
-func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error) {
+func (a *Analyzer) AnalyzeAndUpdateState(ctx context.Context, oldState *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error) {
 	newGamer := *oldState
 	newChar := newGamer
 	charPtr := &newChar
 
-	// ========== 1️⃣ Perform unified full-screen OCR ==========
-	regions := make([]ocrclient.Region, 0)
-	for _, rule := range rules {
-		region, ok := a.areas.Get(rule.Name)
-		if !ok {
-			a.logger.Error("Region not found", slog.String("region", rule.Name))
-			continue
+	// ========== 1️⃣ Perform unified OCR, one request per engine ==========
+	// OCR, icon search and color checks of this pass evaluate the same screen
+	frame := a.captureFrame(ctx)
+	rec := a.archive.NewRecord(ctx, a.deviceID(), oldState.Nickname, oldState.ScreenState.CurrentState)
+
+	locale := a.localeFor(oldState)
+
+	var ocr map[string]domain.OCRResults
+	if needsOCR(rules) {
+		var err error
+		ocr, err = a.recognize(frame, locale, rules)
+		if err != nil {
+			a.logger.Error("Full OCR failed", slog.Any("error", err))
+			a.archiveFrame(frame, rec, err)
+			return nil, err
+		}
+		if rec != nil {
+			maps.Copy(rec.OCR, ocr)
 		}
-
-		regions = append(regions, ocrclient.Region{
-			X0: region.Zone.Min.X,
-			Y0: region.Zone.Min.Y,
-			X1: region.Zone.Max.X,
-			Y1: region.Zone.Max.Y,
-		})
-	}
-
-	fullOCR, fullErr := a.ocrClient.FetchOCR("", regions) // debugName can be omitted
-	if fullErr != nil {
-		a.logger.Error("Full OCR failed", slog.Any("error", fullErr))
-		return nil, fullErr
 	}
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	resourcesRead := false // guarded by mu
 
 	for _, rule := range rules {
 		wg.Add(1)
@@ -76,11 +128,17 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				threshold = 0.9
 			}
 
+			fullOCR := ocr[a.ruleEngine(rule)]
+
 			var value any
+			var updates []fieldValue    // set by rules reading several fields
+			missed := false             // nothing usable was read
+			var region *image.Rectangle // the box the value was read from
+			var icon *framearchive.Icon // the icon search
 
 			switch rule.Action {
 			case "exist":
-				resp, err := a.ocrClient.FindImage(rule.Name, float64(threshold), rule.Name)
+				resp, err := a.findImage(frame, rule, float64(threshold))
 				if err != nil {
 					a.logger.Error("FindImage failed",
 						slog.String("image", rule.Name),
@@ -90,10 +148,11 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 					return
 				}
 				value = resp.Found
+				icon = iconRecord(rule, resp)
 
 			case "findIcon":
-				// call OCR service — on the Python side ".png" will be automatically added to rule.Name
-				resp, err := a.ocrClient.FindImage(rule.Name, float64(threshold), rule.Name)
+				// ".png" is appended to rule.Name by both matchers
+				resp, err := a.findImage(frame, rule, float64(threshold))
 				if err != nil {
 					a.logger.Error("FindImage failed",
 						slog.String("icon", rule.Name),
@@ -110,12 +169,15 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 					slog.Int("matches", matches),
 				)
 				value = resp.Found
+				icon = iconRecord(rule, resp)
 
 				if rule.SaveAsRegion && resp.Found && matches > 0 {
 					// take the best (first) rectangle
 					newBbox := rects[0]
 					newRegion := config.Region{Zone: newBbox}
-					a.areas.AddTemporaryRegion(rule.Name, newRegion)
+					if err := a.saveRegion(ctx, rule, newRegion); err != nil {
+						return
+					}
 
 					x, y := newBbox.Min.X, newBbox.Min.Y
 					w, h := newBbox.Dx(), newBbox.Dy()
@@ -139,6 +201,9 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 					conf = 0.4
 				}
 
+				// the text in the game language of the gamer, or in English
+				texts := a.catalog.Variants(oldState.Language, rule.Text)
+
 				found := false
 				var bbox domain.OCRResult
 				for _, r := range fullOCR {
@@ -146,7 +211,9 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 						continue
 					}
 
-					if strings.Contains(strings.ToLower(r.Text), strings.ToLower(rule.Text)) {
+					if slices.ContainsFunc(texts, func(text string) bool {
+						return strings.Contains(strings.ToLower(r.Text), strings.ToLower(text))
+					}) {
 						found = true
 						bbox = r
 						break
@@ -154,11 +221,18 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 				value = found
 
+				if found {
+					box := image.Rect(bbox.X, bbox.Y, bbox.X+bbox.Width, bbox.Y+bbox.Height)
+					region = &box
+				}
+
 				if rule.SaveAsRegion && found {
 					newRegion := config.Region{
-						Zone: image.Rect(bbox.X, bbox.Y, bbox.X+bbox.Width, bbox.Y+bbox.Height),
+						Zone: *region,
+					}
+					if err := a.saveRegion(ctx, rule, newRegion); err != nil {
+						return
 					}
-					a.areas.AddTemporaryRegion(rule.Name, newRegion)
 
 					a.logger.Info("💾 Saved region from findText",
 						slog.String("name", rule.Name),
@@ -180,8 +254,10 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 
 				ocrZoneResults := fullOCR.FilterByBBox(zone)
+				region = a.regionRect(rule.Name)
 
 				if len(ocrZoneResults) == 0 {
+					missed = true
 					a.logger.Warn("No OCR results found in the specified region",
 						slog.String("region", rule.Name),
 						slog.String("expected_color_bg", rule.ExpectedColorBg),
@@ -206,6 +282,23 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 				value = found
 
+			case "color_sample":
+				found, err := a.sampleColor(frame, rule)
+				if err != nil {
+					a.logger.Error("color sampling failed", slog.String("region", rule.Name), slog.Any("error", err))
+					return
+				}
+				value = found
+				region = a.regionRect(rule.Name)
+
+			case "heroCard":
+				card, err := a.readHeroCard(frame, locale, rule, fullOCR)
+				if err != nil {
+					a.logger.Warn("hero card not read", slog.String("rule", rule.Name), slog.Any("error", err))
+					return
+				}
+				value = card
+
 			case "text":
 				zone, err := a.areas.GetRegionByName(rule.Name)
 				if err != nil {
@@ -217,6 +310,7 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 
 				ocrZoneResults := fullOCR.FilterByBBox(zone)
+				region = a.regionRect(rule.Name)
 
 				text := ""
 				if len(ocrZoneResults) == 0 {
@@ -229,17 +323,12 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 
 				a.logger.Info("text result", slog.String("region", rule.Name), slog.String("text", text))
-				switch rule.Type {
-				case "integer":
-					value = parser.ParseNumber(text)
-				case "string":
-					value = text
-				case "time_duration":
-					value = parseTimeDuration(text)
-				default:
-					a.logger.Warn("unsupported type", slog.String("type", rule.Type))
+				updates, missed, err = a.readText(locale, rule, ocrZoneResults)
+				if err != nil {
+					a.logger.Warn("unsupported type", slog.String("type", rule.Type), slog.Any("error", err))
 					return
 				}
+
 			default:
 				a.logger.Warn("unsupported action", slog.String("action", rule.Action))
 				return
@@ -248,18 +337,33 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 			mu.Lock()
 			defer mu.Unlock()
 
-			if value == nil {
-				value = false
+			if updates == nil {
+				if value == nil {
+					value = false
+				}
+				updates = []fieldValue{{path: rule.Name, value: value}}
 			}
 
-			if err := setFieldByPath(reflect.ValueOf(charPtr).Elem(), strings.Split(rule.Name, "."), value); err != nil {
-				panic(fmt.Sprintf("❌ failed to set field [%s]: %v", rule.Name, err))
+			if icon != nil {
+				rec.AddIcon(*icon)
+			}
+			for _, u := range updates {
+				rec.AddRule(framearchive.Rule{Name: u.path, Action: rule.Action, Value: u.value, Missed: missed, Region: region})
+				if a.update(locale, oldState, charPtr, rule, u, missed) && !missed && strings.HasPrefix(u.path, "resources.") {
+					resourcesRead = true
+				}
 			}
 		}(rule)
 	}
 
 	wg.Wait()
 	newGamer = *charPtr
+	// only accepted readings feed the income, not misses or values the policy kept
+	if resourcesRead && newGamer.Resources != oldState.Resources {
+		newGamer.ResourceIncome = newGamer.ResourceIncome.Record(time.Now(), newGamer.Resources)
+		a.logger.Debug("📦 Resource income", slog.Any("perHour", newGamer.ResourceIncome.PerHour))
+	}
+	a.archiveFrame(frame, rec, nil)
 
 	// Check pushUsecases after setting values
 	if queue == nil {
@@ -298,7 +402,7 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 				}
 
 				a.logger.Info("📥 Push usecase from analysis", slog.String("usecase", uc.Name))
-				if err := queue.Push(context.Background(), ucOriginal); err != nil {
+				if err := queue.Push(ctx, ucOriginal); err != nil {
 					a.logger.Error("❌ Failed to push usecase", slog.String("usecase", uc.Name), slog.Any("error", err))
 				}
 			}
@@ -308,6 +412,73 @@ func (a *Analyzer) AnalyzeAndUpdateState(oldState *domain.Gamer, rules []domain.
 	return &newGamer, nil
 }
 
+// localeFor returns the number format of the gamer's game language, OCR_LOCALE when it isn't set or known.
+func (a *Analyzer) localeFor(gamer *domain.Gamer) parser.Locale {
+	if gamer.Language == "" {
+		return a.locale
+	}
+	locale, err := parser.LocaleByName(gamer.Language)
+	if err != nil {
+		return a.locale
+	}
+	return locale
+}
+
+// update applies one reading to the new state unless the rule policy rejects it; false when it was rejected.
+func (a *Analyzer) update(loc parser.Locale, oldState, newState *domain.Gamer, rule domain.AnalyzeRule, u fieldValue, missed bool) bool {
+	path := strings.Split(u.path, ".")
+	old, err := getFieldByPath(reflect.ValueOf(oldState).Elem(), path)
+	if err != nil {
+		a.reject(rule, rejectField, u.value, err)
+		return false
+	}
+
+	value, err := a.convertFor(loc, old, u.value)
+	switch {
+	case err != nil && missed && old != nil:
+		value = reflect.Zero(reflect.TypeOf(old)).Interface() // nothing was read, the policy decides
+	case err != nil:
+		a.reject(rule, rejectField, u.value, err)
+		return false
+	}
+
+	key := fmt.Sprintf("%d/%s", oldState.ID, u.path)
+	if reason := a.history.check(key, rule.Policy, old, reading{value: value, missed: missed}); reason != "" {
+		a.reject(rule, reason, value, nil)
+		return false
+	}
+
+	if err := setFieldByPath(reflect.ValueOf(newState).Elem(), path, value); err != nil {
+		a.reject(rule, rejectField, value, err)
+		return false
+	}
+	return true
+}
+
+// getFieldByPath reads a nested field by string path, with the same name matching as setFieldByPath.
+// A nil pointer on the way reads as nil.
+func getFieldByPath(v reflect.Value, path []string) (any, error) {
+	for _, part := range path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("invalid field: %s", part)
+		}
+
+		v = v.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, part)
+		})
+		if !v.IsValid() {
+			return nil, fmt.Errorf("invalid field: %s", part)
+		}
+	}
+	return v.Interface(), nil
+}
+
 // setFieldByPath sets a nested field by string path using reflection.
 // If value == false and the target field type is int/uint/string, sets zero-value.
 func setFieldByPath(v reflect.Value, path []string, value any) error {
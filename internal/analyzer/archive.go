@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"image"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+// deviceID returns the device the analyzer reads.
+func (a *Analyzer) deviceID() string {
+	if a.ocrClient == nil {
+		return ""
+	}
+	return a.ocrClient.DeviceID
+}
+
+// iconRecord is the icon search of a rule as the frame archive keeps it.
+func iconRecord(rule domain.AnalyzeRule, resp *ocrclient.FindImageResponse) *framearchive.Icon {
+	return &framearchive.Icon{Name: rule.Name, Found: resp.Found, Boxes: resp.ToRects()}
+}
+
+// regionRect returns the device box of a named region, nil if unknown.
+func (a *Analyzer) regionRect(name string) *image.Rectangle {
+	region, ok := a.areas.Get(name)
+	if !ok {
+		return nil
+	}
+	return &region.Zone
+}
+
+// archiveFrame stores the frame of the pass with everything the rules read; err is the failure of the pass.
+func (a *Analyzer) archiveFrame(f *frame, rec *framearchive.Record, err error) {
+	if rec == nil {
+		return
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	img, errImage := f.image()
+	if errImage != nil {
+		a.logger.Warn("⚠️ Frame not archived, no screenshot", slog.Any("error", errImage))
+		return
+	}
+
+	if err := a.archive.Save(rec, img); err != nil {
+		a.logger.Warn("⚠️ Failed to archive frame", slog.Any("error", err))
+		return
+	}
+	a.logger.Debug("🗄️ Frame archived", slog.String("id", rec.ID), slog.String("trace_id", rec.TraceID))
+}
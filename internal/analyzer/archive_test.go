@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/framearchive"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+func TestAnalyze_ArchivesFrame(t *testing.T) {
+	a := newFrameTestAnalyzer(t, &fakeOCRService{})
+	a.archive = framearchive.New(t.TempDir(), 10, 0)
+	a.engines = map[string]ocrclient.OCREngine{
+		domain.EngineHTTP: &ocrclient.RecordedEngine{Results: domain.OCRResults{
+			{Text: "1,250", Score: 0.9, X: 900, Y: 110, Width: 80, Height: 30}, // gems
+		}},
+	}
+
+	rules := []domain.AnalyzeRule{
+		{Name: "gems", Action: "text", Type: "integer"},
+		{Name: "dailyMissions.state.isClaimButton", Action: "findIcon", Matcher: domain.MatcherLocal, Threshold: 0.95},
+	}
+	gamer := &domain.Gamer{Nickname: "batazor"}
+	gamer.ScreenState.CurrentState = "alliance_chest_gift"
+
+	_, err := a.AnalyzeAndUpdateState(context.Background(), gamer, rules, nil)
+	require.NoError(t, err)
+
+	records, err := a.archive.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	rec := records[0]
+	assert.Equal(t, "test-device", rec.Device)
+	assert.Equal(t, "batazor", rec.Gamer)
+	assert.Equal(t, "alliance_chest_gift", rec.Screen)
+	assert.Len(t, rec.OCR[domain.EngineHTTP], 1)
+
+	require.Len(t, rec.Rules, 2)
+	values := map[string]any{}
+	for _, r := range rec.Rules {
+		values[r.Name] = r.Value
+	}
+	assert.Equal(t, map[string]any{"gems": 1250.0, "dailyMissions.state.isClaimButton": true}, values)
+
+	require.Len(t, rec.Icons, 1)
+	assert.True(t, rec.Icons[0].Found)
+	assert.NotEmpty(t, rec.Icons[0].Boxes)
+	assert.FileExists(t, a.archive.Path(rec, ".png"))
+
+	require.NoError(t, a.archive.Flush())
+	assert.FileExists(t, a.archive.Path(rec, "_overlay.png"))
+}
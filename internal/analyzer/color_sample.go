@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// sampleColor checks the color of a region on the frame pixels, without OCR.
+// With a threshold the share of matching pixels must reach it (a red dot covers a small part of its region),
+// without one the palette color must be dominant (HSV ranges default to half of the region).
+// Rules with type "string" get the dominant color name instead of a flag, rules with segments
+// the number of matching columns.
+func (a *Analyzer) sampleColor(f *frame, rule domain.AnalyzeRule) (any, error) {
+	region, ok := a.areas.Get(rule.Name)
+	if !ok {
+		return nil, fmt.Errorf("region '%s' not found", rule.Name)
+	}
+
+	img, err := f.image()
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Segments > 0 {
+		ranges, err := segmentRanges(rule)
+		if err != nil {
+			return nil, err
+		}
+		count := vision.CountSegments(img, region.Zone, rule.Segments, ranges, segmentThreshold(rule))
+		a.logger.Debug("🎨 Color segments", slog.String("region", rule.Name), slog.Int("count", count))
+		return count, nil
+	}
+
+	if len(rule.HSV) > 0 {
+		threshold := rule.Threshold
+		if threshold == 0 {
+			threshold = 0.5
+		}
+
+		share := vision.MatchShare(img, region.Zone, rule.HSV)
+		a.logger.Debug("🎨 Color sample", slog.String("region", rule.Name), slog.Float64("share", share))
+		return share >= threshold, nil
+	}
+
+	stats := vision.SampleColors(img, region.Zone)
+	a.logger.Debug("🎨 Color sample",
+		slog.String("region", rule.Name),
+		slog.String("dominant", stats.Dominant),
+		slog.Float64("share", stats.Share(rule.Color)),
+	)
+
+	if rule.Type == "string" {
+		return stats.Dominant, nil
+	}
+	if rule.Threshold > 0 {
+		return stats.Share(rule.Color) >= rule.Threshold, nil
+	}
+	return stats.Dominant == rule.Color, nil
+}
+
+// segmentRanges returns the HSV ranges of a segment count: explicit ones or the palette color (blue by default).
+func segmentRanges(rule domain.AnalyzeRule) ([]domain.HSVRange, error) {
+	if len(rule.HSV) > 0 {
+		return rule.HSV, nil
+	}
+	color := rule.Color
+	if color == "" {
+		color = "blue"
+	}
+	r, ok := vision.PaletteRange(color)
+	if !ok {
+		return nil, fmt.Errorf("unknown palette color '%s' of '%s'", color, rule.Name)
+	}
+	return []domain.HSVRange{r}, nil
+}
+
+// segmentThreshold is the share of matching pixels that fills a segment; a full star covers about a quarter of its column.
+func segmentThreshold(rule domain.AnalyzeRule) float64 {
+	if rule.Threshold > 0 {
+		return rule.Threshold
+	}
+	return 0.2
+}
+
+// needsOCR reports whether any rule reads OCR results.
+func needsOCR(rules []domain.AnalyzeRule) bool {
+	for _, rule := range rules {
+		switch rule.Action {
+		case "text", "color_check", "findText", "heroCard":
+			return true
+		}
+	}
+	return false
+}
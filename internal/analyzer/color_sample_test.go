@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"image"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+func TestSampleColor(t *testing.T) {
+	areas, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	a := &Analyzer{
+		areas:  areas,
+		logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+
+	enabled := &frame{fallback: vision.PNGScreen("../../references/screenshots/alliance/alliance_tech_contribute.png")}
+	disabled := &frame{fallback: vision.PNGScreen("../../references/screenshots/alliance/alliance_tech_contribute_disabled.png")}
+
+	rule := domain.AnalyzeRule{Name: "alliance.state.isAllianceContributeButton", Action: "color_sample", Color: "blue"}
+
+	got, err := a.sampleColor(enabled, rule)
+	require.NoError(t, err)
+	require.Equal(t, true, got)
+
+	got, err = a.sampleColor(disabled, rule)
+	require.NoError(t, err)
+	require.Equal(t, false, got)
+
+	rule.Color, rule.Type = "", "string"
+	got, err = a.sampleColor(disabled, rule)
+	require.NoError(t, err)
+	require.Equal(t, "gray", got)
+
+	rule.Type = ""
+	rule.HSV = []domain.HSVRange{{SMax: 0.25, VMin: 0.2}}
+	rule.Threshold = 0.8
+	got, err = a.sampleColor(disabled, rule)
+	require.NoError(t, err)
+	require.Equal(t, true, got)
+
+	_, err = a.sampleColor(enabled, domain.AnalyzeRule{Name: "no_such_region", Color: "red"})
+	require.Error(t, err)
+}
+
+func TestSampleColor_Segments(t *testing.T) {
+	areas, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+	require.NoError(t, areas.AddTemporaryRegion("hero_card.stars", config.Region{Zone: image.Rect(820, 606, 1014, 644)}, config.TemporaryRegionOptions{}))
+
+	a := &Analyzer{
+		areas:  areas,
+		logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+	f := &frame{fallback: vision.PNGScreen("../../references/screenshots/heroes.png")}
+
+	got, err := a.sampleColor(f, domain.AnalyzeRule{
+		Name:     "hero_card.stars",
+		Action:   "color_sample",
+		HSV:      []domain.HSVRange{{HMin: 170, HMax: 200, SMin: 0.3, VMin: 0.75}},
+		Segments: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, got)
+
+	_, err = a.sampleColor(f, domain.AnalyzeRule{Name: "hero_card.stars", Action: "color_sample", Color: "teal", Segments: 5})
+	require.Error(t, err, "no HSV range for an unknown color")
+}
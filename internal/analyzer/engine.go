@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/parser"
+)
+
+// checkedTypes are the text types whose readings the fallback arbitration checks for a value.
+var checkedTypes = map[string]bool{
+	"integer":       true,
+	"amount":        true,
+	"percent":       true,
+	"fraction":      true,
+	"countdown":     true,
+	"time_duration": true,
+	"date":          true,
+}
+
+// ruleEngine is the name of the OCR engine the rule is read with.
+func (a *Analyzer) ruleEngine(rule domain.AnalyzeRule) string {
+	return cmp.Or(rule.Engine, a.engineName, domain.EngineHTTP)
+}
+
+// ocrEngine returns the engine registered under name, wrapped with the fallback engine if one is set.
+func (a *Analyzer) ocrEngine(name string) (ocrclient.OCREngine, error) {
+	a.enginesMu.Lock()
+	defer a.enginesMu.Unlock()
+
+	if engine, ok := a.engines[name]; ok {
+		return engine, nil
+	}
+
+	engine, err := ocrclient.NewEngine(name, a.ocrClient, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.fallbackEngine != "" && a.fallbackEngine != name {
+		fallback, err := ocrclient.NewEngine(a.fallbackEngine, a.ocrClient, a.logger)
+		if err != nil {
+			return nil, fmt.Errorf("fallback: %w", err)
+		}
+		engine = &ocrclient.CompositeEngine{
+			Primary:  engine,
+			Fallback: fallback,
+			MinScore: a.minScore,
+			Logger:   a.logger,
+		}
+	}
+
+	if a.engines == nil {
+		a.engines = make(map[string]ocrclient.OCREngine)
+	}
+	a.engines[name] = engine
+	return engine, nil
+}
+
+// recognize reads the regions of the rules on the frame, one request per OCR engine.
+// The results are keyed by engine name.
+func (a *Analyzer) recognize(f *frame, loc parser.Locale, rules []domain.AnalyzeRule) (map[string]domain.OCRResults, error) {
+	requests := make(map[string]*ocrclient.EngineRequest)
+	for _, rule := range rules {
+		if rule.Action == "color_sample" {
+			continue // sampled locally, no OCR needed
+		}
+
+		// a request without regions reads the whole screen
+		name := a.ruleEngine(rule)
+		req, ok := requests[name]
+		if !ok {
+			req = &ocrclient.EngineRequest{FrameID: f.id, Pixels: f.image, Types: make(map[ocrclient.Region]string), Parses: parses(loc)}
+			requests[name] = req
+		}
+
+		region, ok := a.areas.Get(rule.Name)
+		if !ok {
+			a.logger.Error("Region not found", slog.String("region", rule.Name))
+			continue
+		}
+
+		r := ocrclient.Region{
+			X0: region.Zone.Min.X,
+			Y0: region.Zone.Min.Y,
+			X1: region.Zone.Max.X,
+			Y1: region.Zone.Max.Y,
+		}
+		req.Regions = append(req.Regions, r)
+		if rule.Action == "text" && checkedTypes[rule.Type] {
+			req.Types[r] = rule.Type
+		}
+	}
+
+	results := make(map[string]domain.OCRResults, len(requests))
+	for name, req := range requests {
+		engine, err := a.ocrEngine(name)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := engine.Recognize(f.context(), *req)
+		if err != nil {
+			return nil, fmt.Errorf("%s OCR: %w", engine.Name(), err)
+		}
+		results[name] = res
+	}
+	return results, nil
+}
+
+// parses tells the fallback arbitration whether boxes hold a value of the text type.
+func parses(loc parser.Locale) func(typ string, boxes domain.OCRResults) bool {
+	return func(typ string, boxes domain.OCRResults) bool {
+		text := joinText(boxes)
+		if typ == "fraction" {
+			_, _, err := loc.ParseFraction(text)
+			return err == nil
+		}
+
+		_, err := parseValue(loc, typ, text)
+		return err == nil
+	}
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+func TestAnalyze_PinnedEngine(t *testing.T) {
+	service := &fakeOCRService{} // the OCR service finds nothing
+	a := newFrameTestAnalyzer(t, service)
+	a.engines = map[string]ocrclient.OCREngine{
+		domain.EngineRecorded: &ocrclient.RecordedEngine{Results: domain.OCRResults{
+			{Text: "1,250", Score: 0.9, X: 900, Y: 110, Width: 80, Height: 30}, // gems
+		}},
+	}
+
+	rules := []domain.AnalyzeRule{
+		{Name: "power", Action: "text", Type: "integer"},
+		{Name: "gems", Action: "text", Type: "integer", Engine: domain.EngineRecorded},
+	}
+
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{}, rules, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1250, gamer.Gems, "read by the pinned engine")
+	assert.Equal(t, 0, gamer.Power)
+	assert.Equal(t, []string{"f1"}, service.frameIDs, "one OCR service request for the other rules")
+}
+
+func TestAnalyze_FallbackEngine(t *testing.T) {
+	recording := filepath.Join(t.TempDir(), "ocr.json")
+	require.NoError(t, os.WriteFile(recording, []byte(`[
+		{"box": [[200, 170], [400, 210]], "text": "25,431,870", "score": 0.97}
+	]`), 0o644))
+	viper.Set("OCR_RECORDING", recording)
+	t.Cleanup(func() { viper.Set("OCR_RECORDING", "") })
+
+	a := newFrameTestAnalyzer(t, &fakeOCRService{})
+	a.fallbackEngine = domain.EngineRecorded
+
+	rules := []domain.AnalyzeRule{{Name: "power", Action: "text", Type: "integer"}}
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{}, rules, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 25_431_870, gamer.Power, "the empty reading is re-read by the fallback engine")
+
+	engine, err := a.ocrEngine(domain.EngineHTTP)
+	require.NoError(t, err)
+	assert.Equal(t, "http+recorded", engine.Name())
+}
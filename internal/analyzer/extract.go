@@ -0,0 +1,256 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/heroes"
+	"github.com/batazor/whiteout-survival-autopilot/internal/parser"
+)
+
+// fieldValue is a reading of one state field.
+type fieldValue struct {
+	path  string
+	value any
+}
+
+// tableRows are the rows of a table rule, column field → value; converted to the list field on update.
+type tableRows []map[string]any
+
+// readText extracts the value(s) of a text rule from the OCR boxes of its region.
+// missed is true when nothing usable was read.
+func (a *Analyzer) readText(loc parser.Locale, rule domain.AnalyzeRule, boxes domain.OCRResults) ([]fieldValue, bool, error) {
+	first := ""
+	if len(boxes) > 0 {
+		first = boxes[0].Text
+	}
+
+	switch rule.Type {
+	case "integer", "string", "time_duration":
+		// the first box only, as these rules were authored for
+		value, err := parseValue(loc, rule.Type, first)
+		return []fieldValue{{path: rule.Name, value: value}}, err != nil, nil
+
+	case "amount", "percent", "countdown", "date":
+		value, err := parseValue(loc, rule.Type, joinText(boxes))
+		return []fieldValue{{path: rule.Name, value: value}}, err != nil, nil
+
+	case "fraction":
+		current, max, err := loc.ParseFraction(joinText(boxes))
+		if len(rule.Fields) == 0 {
+			return []fieldValue{{path: rule.Name, value: current}}, err != nil, nil
+		}
+		return mapFields(rule.Fields, map[string]any{"current": current, "max": max}), err != nil, nil
+
+	case "regex":
+		return readRegex(rule, joinText(boxes))
+
+	case "table":
+		if rule.Table == nil || len(rule.Table.Columns) == 0 {
+			return nil, false, fmt.Errorf("type table requires 'table.columns'")
+		}
+		gap := int(float64(rule.Table.RowGap) * a.areas.Scale().Factor)
+		rows, err := readTable(loc, rule.Table.Columns, groupRows(boxes, gap))
+		if err != nil {
+			return nil, false, err
+		}
+		return []fieldValue{{path: rule.Name, value: rows}}, len(rows) == 0, nil
+	}
+
+	return nil, false, fmt.Errorf("unsupported type: %s", rule.Type)
+}
+
+// parseValue converts the text of a box to a single-valued type; the error tells a missed reading from zero.
+func parseValue(loc parser.Locale, typ, text string) (any, error) {
+	switch typ {
+	case "integer", "amount":
+		return loc.ParseNumber(text)
+	case "percent":
+		return loc.ParsePercent(text)
+	case "time_duration":
+		return loc.ParseDuration(text)
+	case "countdown":
+		d, err := loc.ParseDuration(text)
+		return time.Now().Add(d), err
+	case "date":
+		return loc.ParseDate(text, time.Now())
+	default:
+		if text == "" {
+			return text, errors.New("no text")
+		}
+		return text, nil
+	}
+}
+
+// readRegex maps the named captures of the pattern to fields; without fields the first capture
+// (or the whole match) is the value of the rule.
+func readRegex(rule domain.AnalyzeRule, text string) ([]fieldValue, bool, error) {
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return nil, false, err
+	}
+
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return []fieldValue{{path: rule.Name, value: ""}}, true, nil
+	}
+
+	if len(rule.Fields) == 0 {
+		value := m[0]
+		if len(m) > 1 {
+			value = m[1]
+		}
+		return []fieldValue{{path: rule.Name, value: strings.TrimSpace(value)}}, false, nil
+	}
+
+	captures := make(map[string]any, len(rule.Fields))
+	for name := range rule.Fields {
+		i := re.SubexpIndex(name)
+		if i < 0 {
+			return nil, false, fmt.Errorf("regex of %s has no group %q", rule.Name, name)
+		}
+		captures[name] = strings.TrimSpace(m[i])
+	}
+	return mapFields(rule.Fields, captures), false, nil
+}
+
+func mapFields(fields map[string]string, values map[string]any) []fieldValue {
+	out := make([]fieldValue, 0, len(fields))
+	for name, path := range fields {
+		out = append(out, fieldValue{path: path, value: values[name]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].path < out[j].path })
+	return out
+}
+
+// joinText joins the boxes in reading order, so "12" "/" "20" reads as one counter.
+func joinText(boxes domain.OCRResults) string {
+	var parts []string
+	for _, row := range groupRows(boxes, 0) {
+		for _, b := range row {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// groupRows groups boxes whose vertical spans overlap or are closer than gap into rows,
+// top to bottom; boxes of a row are left to right.
+func groupRows(boxes domain.OCRResults, gap int) []domain.OCRResults {
+	sorted := append(domain.OCRResults(nil), boxes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Y < sorted[j].Y })
+
+	var rows []domain.OCRResults
+	bottom := 0
+	for _, b := range sorted {
+		if len(rows) == 0 || b.Y > bottom+gap {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], b)
+		bottom = max(bottom, b.Y+b.Height)
+	}
+
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+	}
+	return rows
+}
+
+// readTable fills the columns of every row. The first column is the key: rows without it
+// (headers, buttons) are skipped.
+func readTable(loc parser.Locale, columns []domain.TableColumn, rows []domain.OCRResults) (tableRows, error) {
+	patterns := make([]*regexp.Regexp, len(columns))
+	for i, col := range columns {
+		if col.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(col.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s': invalid pattern: %w", col.Field, err)
+		}
+		patterns[i] = re
+	}
+
+	var out tableRows
+	for _, row := range rows {
+		used := make([]bool, len(row))
+		values := make(map[string]any)
+
+		for i, col := range columns {
+			for j, b := range row {
+				if used[j] || (patterns[i] != nil && !patterns[i].MatchString(b.Text)) {
+					continue
+				}
+				if v, err := parseValue(loc, col.Type, b.Text); err == nil {
+					values[col.Field] = v
+					used[j] = true
+					break
+				}
+			}
+		}
+
+		if _, ok := values[columns[0].Field]; ok {
+			out = append(out, values)
+		}
+	}
+	return out, nil
+}
+
+// convertFor converts a reading to the type of the field it replaces: texts of regex captures
+// to numbers, table rows to the elements of the list field, hero cards to the heroes they are recorded in.
+func (a *Analyzer) convertFor(loc parser.Locale, old, value any) (any, error) {
+	target := reflect.TypeOf(old)
+	if target == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			n, err := loc.ParseNumber(v)
+			if err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+
+	case tableRows:
+		if target.Kind() != reflect.Slice || target.Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("table needs a list of structs, the field is %s", target)
+		}
+		list := reflect.MakeSlice(target, 0, len(v))
+		for _, row := range v {
+			elem := reflect.New(target.Elem()).Elem()
+			for field, cell := range row {
+				if err := setFieldByPath(elem, []string{field}, cell); err != nil {
+					return nil, err
+				}
+			}
+			list = reflect.Append(list, elem)
+		}
+		return list.Interface(), nil
+
+	case heroes.Card:
+		h, ok := old.(heroes.Heroes)
+		if !ok {
+			return nil, fmt.Errorf("hero card needs the heroes field, the field is %s", target)
+		}
+		out, known := h.Record(v)
+		if !known {
+			a.logger.Warn("🦸 Unknown hero, only the scan advances", slog.String("name", v.Name))
+		}
+		return out, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,195 @@
+package analyzer
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/heroes"
+	"github.com/batazor/whiteout-survival-autopilot/internal/parser"
+)
+
+func box(text string, x, y int) domain.OCRResult {
+	return domain.OCRResult{Text: text, X: x, Y: y, Width: 80, Height: 30}
+}
+
+func TestReadText(t *testing.T) {
+	a := &Analyzer{}
+
+	tests := []struct {
+		name   string
+		rule   domain.AnalyzeRule
+		boxes  domain.OCRResults
+		want   []fieldValue
+		missed bool
+	}{
+		{
+			name:  "integer reads the first box",
+			rule:  domain.AnalyzeRule{Name: "power", Type: "integer"},
+			boxes: domain.OCRResults{box("1,234", 0, 0), box("99", 100, 0)},
+			want:  []fieldValue{{path: "power", value: 1234}},
+		},
+		{
+			name:  "amount",
+			rule:  domain.AnalyzeRule{Name: "power", Type: "amount"},
+			boxes: domain.OCRResults{box("Power", 0, 0), box("2.5B", 100, 0)},
+			want:  []fieldValue{{path: "power", value: 2_500_000_000}},
+		},
+		{
+			name:   "no percent",
+			rule:   domain.AnalyzeRule{Name: "progress", Type: "percent"},
+			boxes:  domain.OCRResults{box("Progress", 0, 0)},
+			want:   []fieldValue{{path: "progress", value: 0.0}},
+			missed: true,
+		},
+		{
+			name: "fraction split into boxes",
+			rule: domain.AnalyzeRule{Name: "alliance.members", Type: "fraction", Fields: map[string]string{
+				"current": "alliance.members.count",
+				"max":     "alliance.members.max",
+			}},
+			boxes: domain.OCRResults{box("/", 100, 2), box("87", 0, 0), box("100", 200, 1)},
+			want: []fieldValue{
+				{path: "alliance.members.count", value: 87},
+				{path: "alliance.members.max", value: 100},
+			},
+		},
+		{
+			name: "regex captures",
+			rule: domain.AnalyzeRule{Name: "alliance.header", Type: "regex",
+				Pattern: `\[(?P<tag>\w+)\]\s*(?P<name>.+)`,
+				Fields:  map[string]string{"name": "alliance.name"},
+			},
+			boxes: domain.OCRResults{box("[WOS] Polar Bears", 0, 0)},
+			want:  []fieldValue{{path: "alliance.name", value: "Polar Bears"}},
+		},
+		{
+			name:   "regex without a match",
+			rule:   domain.AnalyzeRule{Name: "alliance.name", Type: "regex", Pattern: `\[\w+\]\s*(.+)`},
+			boxes:  domain.OCRResults{box("Polar Bears", 0, 0)},
+			want:   []fieldValue{{path: "alliance.name", value: ""}},
+			missed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missed, err := a.readText(parser.English, tt.rule, tt.boxes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.missed, missed)
+		})
+	}
+}
+
+func TestReadText_Table(t *testing.T) {
+	a := &Analyzer{}
+	rule := domain.AnalyzeRule{Name: "arena.state.opponents", Type: "table", Table: &domain.TableSpec{
+		RowGap: 20,
+		Columns: []domain.TableColumn{
+			{Field: "power", Type: "amount", Pattern: `^[\d\s,.]+[KMB]?$`},
+			{Field: "name"},
+		},
+	}}
+
+	// name above power in every card, cards 190px apart
+	boxes := domain.OCRResults{
+		box("2.1M", 270, 845), box("Frosty", 270, 800),
+		box("Yeti", 270, 610), box("1,950,000", 270, 655),
+		box("Refresh", 350, 1000),
+	}
+
+	updates, missed, err := a.readText(parser.English, rule, boxes)
+	require.NoError(t, err)
+	require.False(t, missed)
+	require.Len(t, updates, 1)
+
+	list, err := a.convertFor(parser.English, []domain.ArenaOpponent(nil), updates[0].value)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ArenaOpponent{
+		{Name: "Yeti", Power: 1_950_000},
+		{Name: "Frosty", Power: 2_100_000},
+	}, list)
+}
+
+func TestReadText_TableUnvalidated(t *testing.T) {
+	a := &Analyzer{}
+	boxes := domain.OCRResults{box("Yeti", 270, 610)}
+
+	// rules that bypassed validation fail the reading instead of panicking
+	_, _, err := a.readText(parser.English, domain.AnalyzeRule{Name: "arena.state.opponents", Type: "table"}, boxes)
+	require.Error(t, err)
+
+	_, _, err = a.readText(parser.English, domain.AnalyzeRule{Name: "arena.state.opponents", Type: "table", Table: &domain.TableSpec{
+		Columns: []domain.TableColumn{{Field: "name", Pattern: "(["}},
+	}}, boxes)
+	require.ErrorContains(t, err, "invalid pattern")
+
+	_, _, err = a.readText(parser.English, domain.AnalyzeRule{Name: "hero_card", Type: "regex", Pattern: `Lv\.(\d+)`,
+		Fields: map[string]string{"level": "heroes.card.level"}}, domain.OCRResults{box("Lv.12", 270, 610)})
+	require.ErrorContains(t, err, `no group "level"`)
+}
+
+func TestConvertFor(t *testing.T) {
+	a := &Analyzer{}
+
+	v, err := a.convertFor(parser.English, 0, "12,500")
+	require.NoError(t, err)
+	assert.Equal(t, 12_500, v)
+
+	v, err = a.convertFor(parser.English, "", "12,500")
+	require.NoError(t, err)
+	assert.Equal(t, "12,500", v)
+
+	_, err = a.convertFor(parser.English, 0, "Lv.")
+	assert.ErrorIs(t, err, parser.ErrNoNumber, "a capture without digits is not a zero")
+
+	_, err = a.convertFor(parser.English, 0, tableRows{{"power": 1}})
+	assert.Error(t, err)
+}
+
+func TestConvertFor_HeroCard(t *testing.T) {
+	a := &Analyzer{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	roster := heroes.Heroes{List: map[string]heroes.Hero{"Smith": {Name: "Smith"}}}
+
+	v, err := a.convertFor(parser.English, roster, heroes.Card{Name: "Smlth", Level: 12, Stars: 2})
+	require.NoError(t, err)
+	got := v.(heroes.Heroes)
+	assert.Equal(t, heroes.State{Level: 12, Stars: 2, IsAvailable: true}, got.List["Smith"].State)
+	assert.Equal(t, 1, got.Scan.Count)
+
+	v, err = a.convertFor(parser.English, got, heroes.Card{Name: "Nobody"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.(heroes.Heroes).Scan.Count, "an unknown hero still advances the scan")
+
+	_, err = a.convertFor(parser.English, 0, heroes.Card{Name: "Smith"})
+	assert.Error(t, err)
+}
+
+func TestLocaleFor(t *testing.T) {
+	a := &Analyzer{locale: parser.English}
+	rule := domain.AnalyzeRule{Name: "power", Type: "amount"}
+	boxes := domain.OCRResults{box("4,3 млн", 270, 610)}
+
+	// the number format follows the gamer's language, OCR_LOCALE otherwise
+	for _, tt := range []struct {
+		language string
+		want     parser.Locale
+	}{
+		{"ru", parser.Russian},
+		{"", parser.English},
+		{"de", parser.English},
+	} {
+		loc := a.localeFor(&domain.Gamer{Language: tt.language})
+		assert.Equal(t, tt.want.Name, loc.Name, tt.language)
+	}
+
+	got, missed, err := a.readText(a.localeFor(&domain.Gamer{Language: "ru"}), rule, boxes)
+	require.NoError(t, err)
+	assert.False(t, missed)
+	assert.Equal(t, 4_300_000, got[0].value)
+}
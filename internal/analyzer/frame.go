@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"context"
+	"image"
+	"log/slog"
+	"sync"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// frame is the screenshot shared by every rule of one analysis pass.
+// It lives on the OCR service; pixels are downloaded only when a rule needs them locally.
+type frame struct {
+	ctx      context.Context
+	id       string
+	client   *ocrclient.Client
+	fallback vision.ScreenSource
+
+	once sync.Once
+	img  image.Image
+	err  error
+}
+
+// captureFrame captures the screen once for the pass.
+// If the service can't keep frames, rules fall back to their own screenshots.
+func (a *Analyzer) captureFrame(ctx context.Context) *frame {
+	f := &frame{ctx: ctx, client: a.ocrClient, fallback: a.screen}
+
+	captured, err := a.ocrClient.CaptureFrame(ctx)
+	if err != nil {
+		a.logger.Warn("⚠️ Frame capture failed, every rule takes its own screenshot", slog.Any("error", err))
+		return f
+	}
+
+	f.id = captured.ID
+	return f
+}
+
+// image returns the pixels of the frame (downloaded once).
+func (f *frame) image() (image.Image, error) {
+	f.once.Do(func() {
+		if f.id == "" {
+			f.img, f.err = f.fallback(f.context())
+			return
+		}
+		f.img, f.err = f.client.FrameImage(f.context(), f.id)
+	})
+	return f.img, f.err
+}
+
+// findImage searches an icon on the frame with the matcher chosen by the rule:
+// "local" runs template matching in Go, "remote" asks the OCR service.
+func (a *Analyzer) findImage(f *frame, rule domain.AnalyzeRule, threshold float64) (*ocrclient.FindImageResponse, error) {
+	matcher := rule.Matcher
+	if matcher == "" {
+		matcher = a.defaultMatcher
+	}
+
+	if matcher == domain.MatcherLocal && a.iconMatcher != nil {
+		img, err := f.image()
+		if err != nil {
+			return nil, err
+		}
+		return a.iconMatcher.FindImageIn(img, rule.Name, threshold)
+	}
+
+	return a.ocrClient.FindImageFrame(f.context(), f.id, rule.Name, threshold, rule.Name)
+}
+
+// context returns the context of the analysis pass.
+func (f *frame) context() context.Context {
+	if f.ctx == nil {
+		return context.Background()
+	}
+	return f.ctx
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// fakeOCRService records which frame every request refers to.
+type fakeOCRService struct {
+	frameStatus int
+
+	mu       sync.Mutex
+	captures int
+	loads    int
+	frameIDs []string
+}
+
+func (s *fakeOCRService) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /frame", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.captures++
+		s.mu.Unlock()
+
+		if s.frameStatus != 0 {
+			w.WriteHeader(s.frameStatus)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ocrclient.Frame{ID: "f1", Width: 1080, Height: 2400})
+	})
+
+	mux.HandleFunc("GET /frame/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.loads++
+		s.mu.Unlock()
+
+		data, err := os.ReadFile("../../references/screenshots/alliance/alliance_chest_gift.png")
+		require.NoError(t, err)
+		_, _ = w.Write(data)
+	})
+
+	mux.HandleFunc("POST /ocr", func(w http.ResponseWriter, r *http.Request) {
+		var req ocrclient.FetchOCRRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		s.record(req.FrameID)
+		_, _ = w.Write([]byte("[]"))
+	})
+
+	mux.HandleFunc("POST /find_image", func(w http.ResponseWriter, r *http.Request) {
+		var req ocrclient.FindImageRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		s.record(req.FrameID)
+		_ = json.NewEncoder(w).Encode(ocrclient.FindImageResponse{Found: true})
+	})
+
+	return mux
+}
+
+func (s *fakeOCRService) record(frameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frameIDs = append(s.frameIDs, frameID)
+}
+
+func newFrameTestAnalyzer(t *testing.T, service *fakeOCRService) *Analyzer {
+	t.Helper()
+
+	server := httptest.NewServer(service.handler(t))
+	t.Cleanup(server.Close)
+
+	areas, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := ocrclient.NewClient("test-device", logger)
+	client.ServiceURL = server.URL
+
+	a := NewAnalyzer(areas, logger, client)
+	a.iconMatcher = vision.NewIconMatcher("../../references/icons", a.screen)
+	a.history = newReadingHistory() // tests don't share readings
+	return a
+}
+
+var frameTestRules = []domain.AnalyzeRule{
+	{Name: "alliance.state.isNeedSupport", Action: "exist"},
+	{Name: "vip.state.isVIPAddAvailable", Action: "color_check", ExpectedColorBg: "green"},
+	{Name: "screenState.isWelcome", Action: "exist", Matcher: domain.MatcherRemote},
+	{Name: "dailyMissions.state.isClaimButton", Action: "findIcon", Matcher: domain.MatcherLocal, Threshold: 0.95},
+	{Name: "growthMissions.state.isClaimButton", Action: "findIcon", Matcher: domain.MatcherLocal, Threshold: 0.95},
+}
+
+func TestAnalyze_SharesOneFrame(t *testing.T) {
+	service := &fakeOCRService{}
+	a := newFrameTestAnalyzer(t, service)
+
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{}, frameTestRules, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, service.captures, "the screen is captured once per pass")
+	require.Equal(t, []string{"f1", "f1", "f1"}, service.frameIDs, "OCR and remote icons use the frame")
+	require.Equal(t, 1, service.loads, "local matchers download the frame once")
+
+	require.True(t, gamer.Alliance.State.IsNeedSupport)
+	require.True(t, gamer.DailyMissions.State.IsClaimButton, "found locally on the shared frame")
+}
+
+func TestAnalyze_FrameUnsupported(t *testing.T) {
+	service := &fakeOCRService{frameStatus: http.StatusNotFound}
+	a := newFrameTestAnalyzer(t, service)
+
+	_, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{}, frameTestRules[:3], nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"", "", ""}, service.frameIDs, "every request takes its own screenshot")
+}
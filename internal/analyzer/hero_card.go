@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/heroes"
+	"github.com/batazor/whiteout-survival-autopilot/internal/parser"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// Regions of the hero screen read by heroCard rules.
+const (
+	heroNameRegion  = "hero_card.name"
+	heroLevelRegion = "hero_card.level"
+	heroStarsRegion = "hero_card.stars"
+)
+
+// readHeroCard reads the name, level and stars of the open hero screen.
+// The stars are counted on the star bar like a color_sample rule with 5 segments (blue unless the rule sets a color).
+func (a *Analyzer) readHeroCard(f *frame, loc parser.Locale, rule domain.AnalyzeRule, boxes domain.OCRResults) (heroes.Card, error) {
+	var card heroes.Card
+
+	nameZone, err := a.areas.GetRegionByName(heroNameRegion)
+	if err != nil {
+		return card, err
+	}
+	card.Name = joinText(boxes.FilterByBBox(nameZone))
+	if card.Name == "" {
+		return card, fmt.Errorf("no hero name in '%s'", heroNameRegion)
+	}
+
+	if levelZone, err := a.areas.GetRegionByName(heroLevelRegion); err == nil {
+		card.Level, _ = loc.ParseNumber(joinText(boxes.FilterByBBox(levelZone)))
+	}
+
+	if stars, ok := a.areas.Get(heroStarsRegion); ok {
+		img, err := f.image()
+		if err != nil {
+			return card, err
+		}
+		ranges, err := segmentRanges(rule)
+		if err != nil {
+			return card, err
+		}
+		card.Stars = vision.CountSegments(img, stars.Zone, heroes.MaxStars, ranges, segmentThreshold(rule))
+	}
+
+	a.logger.Info("🦸 Hero card",
+		slog.String("name", card.Name),
+		slog.Int("level", card.Level),
+		slog.Int("stars", card.Stars),
+	)
+	return card, nil
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
+)
+
+// Reasons a reading is not applied.
+const (
+	rejectMiss      = "miss"
+	rejectBounds    = "bounds"
+	rejectDecrease  = "decrease"
+	rejectDeviation = "deviation"
+	rejectVote      = "vote"
+	rejectField     = "field" // the rule name is not a state field
+)
+
+// deviationWindow is how many recent readings the median of maxDeviation is taken from.
+const deviationWindow = 5
+
+// monotonicRepeats is how many earlier readings of the window must agree with a lower value
+// before it replaces a monotonic field, e.g. after a misread extra digit was accepted.
+const monotonicRepeats = 2
+
+// reading is the value a rule extracted from the frame.
+type reading struct {
+	value  any
+	missed bool // nothing usable was read
+}
+
+// readingHistory keeps recent readings per gamer and rule for voting and deviation checks.
+type readingHistory struct {
+	mu      sync.Mutex
+	votes   map[string][]string
+	numbers map[string][]float64
+}
+
+func newReadingHistory() *readingHistory {
+	return &readingHistory{
+		votes:   make(map[string][]string),
+		numbers: make(map[string][]float64),
+	}
+}
+
+// check returns the reason the reading must not replace old, empty when it may.
+func (h *readingHistory) check(key string, p *domain.UpdatePolicy, old any, r reading) string {
+	if p == nil {
+		return ""
+	}
+	if r.missed {
+		if p.KeepOnMiss {
+			return rejectMiss
+		}
+		return ""
+	}
+
+	num, isNum := toFloat(r.value)
+	if isNum && (p.Min != nil && num < *p.Min || p.Max != nil && num > *p.Max) {
+		return rejectBounds
+	}
+
+	// the history is recorded before the monotonic check, so a wrong high value that was
+	// accepted is replaced once the real one keeps coming back
+	var prev []float64
+	if isNum && (p.Monotonic || p.MaxDeviation > 0) {
+		prev = h.record(key, num)
+	}
+	deviation := isNum && p.MaxDeviation > 0 && deviates(prev, num, p.MaxDeviation)
+	vote := p.Votes <= 1 || h.wins(key, fmt.Sprint(r.value), p.Votes)
+
+	if last, ok := toFloat(old); isNum && p.Monotonic && ok && num < last && repeats(prev, num) < monotonicRepeats {
+		return rejectDecrease
+	}
+	if deviation {
+		return rejectDeviation
+	}
+	if !vote {
+		return rejectVote
+	}
+	return ""
+}
+
+// record adds num to the recent readings and returns the readings before it.
+func (h *readingHistory) record(key string, num float64) []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.numbers[key]
+	h.numbers[key] = appendWindow(prev, num, deviationWindow)
+	return prev
+}
+
+// deviates reports whether num is off the median of the previous readings.
+// A value that keeps coming back becomes the median and is accepted.
+func deviates(prev []float64, num, maxDeviation float64) bool {
+	if len(prev) == 0 {
+		return false
+	}
+	med := median(prev)
+	if med == 0 {
+		return false
+	}
+	return math.Abs(num-med)/math.Abs(med) > maxDeviation
+}
+
+// repeats counts the previous readings within 1% of num.
+func repeats(prev []float64, num float64) int {
+	n := 0
+	for _, v := range prev {
+		if math.Abs(v-num) <= math.Abs(num)*0.01 {
+			n++
+		}
+	}
+	return n
+}
+
+// wins records the value and reports whether it has the majority of the last n readings.
+func (h *readingHistory) wins(key, value string, n int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	window := appendWindow(h.votes[key], value, n)
+	h.votes[key] = window
+
+	count := 0
+	for _, v := range window {
+		if v == value {
+			count++
+		}
+	}
+	return count > n/2
+}
+
+func appendWindow[T any](window []T, v T, n int) []T {
+	window = append(window, v)
+	if len(window) > n {
+		window = window[len(window)-n:]
+	}
+	return window
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case time.Duration:
+		return float64(n), true
+	case nil, bool, string:
+		return 0, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// reject logs and counts a reading that is not applied.
+func (a *Analyzer) reject(rule domain.AnalyzeRule, reason string, value any, err error) {
+	metrics.AnalyzerRejectedTotal.WithLabelValues(rule.Name, reason).Inc()
+	a.logger.Warn("🚫 Reading rejected",
+		slog.String("rule", rule.Name),
+		slog.String("reason", reason),
+		slog.Any("value", value),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestReadingHistory_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *domain.UpdatePolicy
+		old      any
+		readings []reading
+		want     []string
+	}{
+		{
+			name:     "no policy",
+			readings: []reading{{value: 0, missed: true}},
+			want:     []string{""},
+		},
+		{
+			name:     "keep on miss",
+			policy:   &domain.UpdatePolicy{KeepOnMiss: true},
+			old:      1500,
+			readings: []reading{{value: 0, missed: true}, {value: 1600}},
+			want:     []string{rejectMiss, ""},
+		},
+		{
+			name:     "bounds",
+			policy:   &domain.UpdatePolicy{Min: ptr(1), Max: ptr(12)},
+			readings: []reading{{value: 0}, {value: 13}, {value: 7}},
+			want:     []string{rejectBounds, rejectBounds, ""},
+		},
+		{
+			name:     "monotonic",
+			policy:   &domain.UpdatePolicy{Monotonic: true},
+			old:      25_000_000,
+			readings: []reading{{value: 2_500_000}, {value: 25_000_000}, {value: 25_100_000}},
+			want:     []string{rejectDecrease, "", ""},
+		},
+		{
+			name:   "deviation until the new value repeats",
+			policy: &domain.UpdatePolicy{MaxDeviation: 0.5},
+			readings: []reading{
+				{value: 1000}, {value: 1010}, {value: 9000}, {value: 1020},
+				{value: 5000}, {value: 5000}, {value: 5000},
+			},
+			want: []string{"", "", rejectDeviation, "", rejectDeviation, rejectDeviation, ""},
+		},
+		{
+			name:     "majority vote",
+			policy:   &domain.UpdatePolicy{Votes: 3},
+			readings: []reading{{value: 120}, {value: 720}, {value: 120}, {value: 720}, {value: 720}},
+			want:     []string{rejectVote, rejectVote, "", "", ""},
+		},
+		{
+			name:   "a misread extra digit after a restart doesn't lock a monotonic field",
+			policy: &domain.UpdatePolicy{Monotonic: true, MaxDeviation: 0.5},
+			old:    25_000_000,
+			readings: []reading{
+				{value: 250_000_000}, {value: 25_000_000}, {value: 25_000_000}, {value: 25_000_000}, {value: 25_100_000},
+			},
+			want: []string{"", rejectDecrease, rejectDecrease, "", ""},
+		},
+		{
+			name:     "monotonic without deviation",
+			policy:   &domain.UpdatePolicy{Monotonic: true},
+			old:      9,
+			readings: []reading{{value: 90}, {value: 9}, {value: 9}, {value: 9}},
+			want:     []string{"", rejectDecrease, rejectDecrease, ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newReadingHistory()
+
+			old := tt.old
+			var got []string
+			for _, r := range tt.readings {
+				reason := h.check("1/power", tt.policy, old, r)
+				if reason == "" {
+					old = r.value
+				}
+				got = append(got, reason)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAnalyze_UpdatePolicy(t *testing.T) {
+	a := newFrameTestAnalyzer(t, &fakeOCRService{}) // OCR finds nothing
+
+	rules := []domain.AnalyzeRule{
+		{Name: "power", Action: "text", Type: "integer", Policy: &domain.UpdatePolicy{KeepOnMiss: true}},
+		{Name: "gems", Action: "text", Type: "integer"},
+		{Name: "no.such.field", Action: "text", Type: "integer"},
+	}
+
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{Power: 1500, Gems: 300}, rules, nil)
+	require.NoError(t, err, "a bad field path is rejected, not a panic")
+
+	assert.Equal(t, 1500, gamer.Power, "a missed reading keeps the value")
+	assert.Equal(t, 0, gamer.Gems, "without a policy the reading is applied as before")
+}
+
+func TestAnalyze_ResourceIncome(t *testing.T) {
+	a := newFrameTestAnalyzer(t, &fakeOCRService{}) // OCR finds nothing
+	rules := []domain.AnalyzeRule{{Name: "resources.coal", Action: "text", Type: "amount"}}
+
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{Resources: domain.Resources{Coal: 900}}, rules, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, gamer.Resources.Coal, "the missed reading is applied")
+	assert.Empty(t, gamer.ResourceIncome.Samples, "but doesn't feed the income")
+
+	a.engines = map[string]ocrclient.OCREngine{
+		domain.EngineHTTP: &ocrclient.RecordedEngine{Results: domain.OCRResults{
+			{Text: "906.2K", Score: 0.9, X: 660, Y: 110, Width: 100, Height: 30},
+		}},
+	}
+	gamer, err = a.AnalyzeAndUpdateState(context.Background(), gamer, rules, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 906200, gamer.Resources.Coal)
+	assert.Len(t, gamer.ResourceIncome.Samples, 1)
+}
+
+func TestAnalyze_HistoryOutlivesAnalyzer(t *testing.T) {
+	history := newReadingHistory()
+	rules := []domain.AnalyzeRule{{Name: "gems", Action: "text", Type: "integer", Policy: &domain.UpdatePolicy{Votes: 3}}}
+	engines := map[string]ocrclient.OCREngine{
+		domain.EngineHTTP: &ocrclient.RecordedEngine{Results: domain.OCRResults{
+			{Text: "1,250", Score: 0.9, X: 900, Y: 110, Width: 80, Height: 30},
+		}},
+	}
+
+	gamer := &domain.Gamer{ID: 7, Gems: 300}
+	for i, want := range []int{300, 1250, 1250} {
+		// a gamer switch builds a new analyzer every pass
+		a := newFrameTestAnalyzer(t, &fakeOCRService{})
+		a.history, a.engines = history, engines
+
+		var err error
+		gamer, err = a.AnalyzeAndUpdateState(context.Background(), gamer, rules, nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, gamer.Gems, "pass %d", i+1)
+	}
+
+	assert.Same(t, NewAnalyzer(nil, nil, nil).history, NewAnalyzer(nil, nil, nil).history)
+}
+
+// TestAnalyze_BuildingRequires runs the Requires rule of the queue usecases on the rows of
+// references/screenshots/building/building_main.png.
+func TestAnalyze_BuildingRequires(t *testing.T) {
+	uc, err := config.LoadUseCase(context.Background(), "../../usecases/building/queue1.yaml")
+	require.NoError(t, err)
+	var rules []domain.AnalyzeRule
+	for _, step := range uc.Steps {
+		if step.If == nil {
+			continue
+		}
+		for _, s := range step.If.Then {
+			for _, rule := range s.Analyze {
+				if rule.Name == "building.requires" {
+					rules = append(rules, rule)
+				}
+			}
+		}
+	}
+	require.Len(t, rules, 1)
+
+	a := newFrameTestAnalyzer(t, &fakeOCRService{})
+	a.engines = map[string]ocrclient.OCREngine{
+		domain.EngineHTTP: &ocrclient.RecordedEngine{Results: domain.OCRResults{
+			{Text: "Furnace  Level 22", Score: 0.9, X: 192, Y: 1188, Width: 320, Height: 36},
+			{Text: "20.95M/8.46M", Score: 0.9, X: 192, Y: 1288, Width: 290, Height: 36},
+			{Text: "19.77M/8.46M", Score: 0.9, X: 192, Y: 1389, Width: 290, Height: 36},
+			{Text: "4.71M/1.69M", Score: 0.9, X: 192, Y: 1490, Width: 250, Height: 36},
+			{Text: "678.37K/423K", Score: 0.9, X: 192, Y: 1591, Width: 280, Height: 36},
+		}},
+	}
+
+	gamer, err := a.AnalyzeAndUpdateState(context.Background(), &domain.Gamer{}, rules, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Resources{Meat: 20_950_000, Wood: 19_770_000, Coal: 4_710_000, Iron: 678_370}, gamer.Resources)
+	assert.Equal(t, domain.Resources{Meat: 8_460_000, Wood: 8_460_000, Coal: 1_690_000, Iron: 423_000}, gamer.Buildings.State.Cost)
+}
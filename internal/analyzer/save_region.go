@@ -0,0 +1,25 @@
+package analyzer
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// saveRegion stores a found box as a temporary region of the current usecase execution.
+func (a *Analyzer) saveRegion(ctx context.Context, rule domain.AnalyzeRule, region config.Region) error {
+	err := a.areas.AddTemporaryRegion(rule.Name, region, config.TemporaryRegionOptions{
+		Scope:    config.RegionScope(ctx),
+		TTL:      rule.RegionTTL,
+		Override: rule.OverrideRegion,
+	})
+	if err != nil {
+		a.logger.Warn("⚠️ Region not saved, set overrideRegion to replace it",
+			slog.String("region", rule.Name),
+			slog.Any("error", err),
+		)
+	}
+	return err
+}
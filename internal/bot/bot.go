@@ -32,6 +32,8 @@ type Bot struct {
 
 func NewBot(dev *device.Device, gamer *domain.Gamer, email string, rdb *redis.Client, rules config.ScreenAnalyzeRules, log *slog.Logger, repo repository.StateRepository) *Bot {
 	queue := redis_queue.NewGamerQueue(rdb, gamer.ID)
+	// classes, roles and buffs of the heroes for hero selection and scans
+	gamer.Heroes = gamer.Heroes.WithCatalog(config.DefaultHeroCatalog())
 
 	exec := executor.NewUseCaseExecutor(
 		log,
@@ -57,7 +59,7 @@ func NewBot(dev *device.Device, gamer *domain.Gamer, email string, rdb *redis.Cl
 
 func (b *Bot) Play(ctx context.Context) {
 	// 📸 Analyze state on the main screen
-	b.updateStateFromScreen(ctx, "main_city", "out/bot_"+b.Gamer.Nickname+"_start_main_city.png")
+	b.updateStateFromScreen(ctx, "main_city")
 
 	for {
 		select {
@@ -97,7 +99,7 @@ func (b *Bot) Play(ctx context.Context) {
 		switchedScreen := false
 		if b.Gamer.ScreenState.CurrentState != uc.Node {
 			b.logger.Info("🔁 Switching to usecase screen", slog.String("name", uc.Name), slog.String("screen", uc.Node))
-			errForceTo := b.Device.FSM.ForceTo(uc.Node, b.updateStateFromScreen)
+			errForceTo := b.Device.FSM.ForceTo(ctx, uc.Node, b.updateStateFromScreen)
 			if errForceTo != nil {
 				if errors.Is(errForceTo, fsm.EventNotActive) {
 					b.logger.Info("⏭️ UseCase skipped because event is not active", slog.String("name", uc.Name))
@@ -121,7 +123,7 @@ func (b *Bot) Play(ctx context.Context) {
 
 		// Call updateStateFromScreen only if FSM didn't do it in ForceTo, or if there was no transition
 		if !switchedScreen {
-			b.updateStateFromScreen(ctx, uc.Node, "out/bot_"+b.Gamer.Nickname+"_before_trigger.png")
+			b.updateStateFromScreen(ctx, uc.Node)
 		}
 
 		b.executor.ExecuteUseCase(ctx, uc, b.Gamer, b.Queue)
@@ -134,7 +136,7 @@ func (b *Bot) Play(ctx context.Context) {
 	time.Sleep(2 * time.Second)
 
 	// 🔁 Return to main screen
-	b.Device.FSM.ForceTo(state.StateMainCity, nil)
+	b.Device.FSM.ForceTo(ctx, state.StateMainCity, nil)
 
 	// Time for screen rendering
 	time.Sleep(2 * time.Second)
@@ -5,9 +5,10 @@ import (
 	"log/slog"
 )
 
-func (b *Bot) updateStateFromScreen(ctx context.Context, screen string, filename string) {
+// updateStateFromScreen analyzes the screen; with FRAME_ARCHIVE_DIR set the frame is archived by the analyzer.
+func (b *Bot) updateStateFromScreen(ctx context.Context, screen string) {
 	rules := b.Rules[screen]
-	newState, err := b.executor.Analyzer().AnalyzeAndUpdateState(b.Gamer, rules, b.Queue)
+	newState, err := b.executor.Analyzer().AnalyzeAndUpdateState(ctx, b.Gamer, rules, b.Queue)
 	if err != nil {
 		b.logger.Warn("⚠️ Screen analysis error", slog.String("screen", screen), slog.Any("error", err))
 		return
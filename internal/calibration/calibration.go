@@ -0,0 +1,175 @@
+// Package calibration locates anchor elements on a device screenshot and proposes
+// corrected boxes for regions that moved compared to area.json.
+package calibration
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Anchor is a region whose position can be found on the screen by its text or icon.
+type Anchor struct {
+	Region    string   `yaml:"region"`
+	Text      string   `yaml:"text"`      // OCR text inside the region
+	Icon      string   `yaml:"icon"`      // or an icon from references/icons
+	Threshold float64  `yaml:"threshold"` // of the icon match, 0.8 by default
+	Follow    []string `yaml:"follow"`    // regions that move together with the anchor
+}
+
+// Status of a proposal.
+type Status string
+
+const (
+	StatusMoved     Status = "moved"
+	StatusUnchanged Status = "unchanged"
+	StatusNotFound  Status = "not found" // the anchor is not on the screen
+	StatusUnknown   Status = "unknown"   // the region is not in the area lookup
+)
+
+// Proposal is the corrected box of one region, in device pixels.
+type Proposal struct {
+	Region string
+	Anchor string
+	Layer  string
+	Old    image.Rectangle
+	New    image.Rectangle
+	Status Status
+}
+
+// Shift returns how far the region moved.
+func (p Proposal) Shift() image.Point {
+	return p.New.Min.Sub(p.Old.Min)
+}
+
+// Locator finds anchors on the captured screen; the match nearest to the expected box wins.
+type Locator interface {
+	FindText(text string, near image.Rectangle) (image.Rectangle, bool)
+	FindIcon(name string, threshold float64, near image.Rectangle) (image.Rectangle, bool, error)
+}
+
+// LoadAnchors reads the anchor list.
+func LoadAnchors(path string) ([]Anchor, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anchors: %w", err)
+	}
+
+	var anchors []Anchor
+	if err := yaml.Unmarshal(data, &anchors); err != nil {
+		return nil, fmt.Errorf("failed to parse anchors: %w", err)
+	}
+
+	for i, a := range anchors {
+		if a.Region == "" || (a.Text == "") == (a.Icon == "") {
+			return nil, fmt.Errorf("anchor #%d: needs a region and either text or icon", i+1)
+		}
+	}
+	return anchors, nil
+}
+
+// Propose locates every anchor and moves the anchor region and its followers by the same offset.
+// Shifts below minShift pixels are reported as unchanged.
+func Propose(areas *config.AreaLookup, anchors []Anchor, loc Locator, minShift int) ([]Proposal, error) {
+	var proposals []Proposal
+
+	for _, anchor := range anchors {
+		old, ok := areas.Get(anchor.Region)
+		if !ok {
+			proposals = append(proposals, Proposal{Region: anchor.Region, Anchor: anchor.Region, Status: StatusUnknown})
+			continue
+		}
+
+		found, ok, err := locate(loc, anchor, old.Zone)
+		if err != nil {
+			return nil, fmt.Errorf("anchor %s: %w", anchor.Region, err)
+		}
+
+		var shift image.Point
+		status := StatusNotFound
+		if ok {
+			shift = center(found).Sub(center(old.Zone))
+			status = StatusMoved
+			if abs(shift.X) < minShift && abs(shift.Y) < minShift {
+				shift, status = image.Point{}, StatusUnchanged
+			}
+		}
+
+		for _, name := range append([]string{anchor.Region}, anchor.Follow...) {
+			p := Proposal{Region: name, Anchor: anchor.Region, Layer: areas.Layer(name), Status: status}
+			region, known := areas.Get(name)
+			if !known {
+				p.Status = StatusUnknown
+			} else {
+				p.Old = region.Zone
+				p.New = region.Zone.Add(shift)
+			}
+			proposals = append(proposals, p)
+		}
+	}
+
+	return proposals, nil
+}
+
+func locate(loc Locator, anchor Anchor, expected image.Rectangle) (image.Rectangle, bool, error) {
+	if anchor.Text != "" {
+		r, ok := loc.FindText(anchor.Text, expected)
+		return r, ok, nil
+	}
+
+	threshold := anchor.Threshold
+	if threshold == 0 {
+		threshold = 0.8
+	}
+	return loc.FindIcon(anchor.Icon, threshold, expected)
+}
+
+// Apply stores the moved regions as device overrides and returns how many were changed.
+func Apply(areas *config.AreaLookup, proposals []Proposal) int {
+	scale := areas.Scale()
+
+	n := 0
+	for _, p := range proposals {
+		if p.Status != StatusMoved {
+			continue
+		}
+		areas.SetOverride(p.Region, domain.NewBBoxFromRect(scale.Unscale(p.New), domain.ReferenceWidth, domain.ReferenceHeight))
+		n++
+	}
+	return n
+}
+
+// WriteReport prints what moved.
+func WriteReport(w io.Writer, proposals []Proposal) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REGION\tANCHOR\tLAYER\tSTATUS\tSHIFT\tOLD\tNEW")
+	for _, p := range proposals {
+		shift, old, next := "-", "-", "-"
+		if p.Status == StatusMoved || p.Status == StatusUnchanged {
+			s := p.Shift()
+			shift = fmt.Sprintf("%+d,%+d", s.X, s.Y)
+			old, next = p.Old.String(), p.New.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Region, p.Anchor, p.Layer, p.Status, shift, old, next)
+	}
+	return tw.Flush()
+}
+
+func center(r image.Rectangle) image.Point {
+	return image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
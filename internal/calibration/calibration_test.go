@@ -0,0 +1,90 @@
+package calibration_test
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/calibration"
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// to_mail: (108,1200)-(324,1320), mail_badge: (300,1180)-(340,1220), to_shop: (540,2160)-(756,2280)
+const areaJSON = `[{"ocr": "main.png", "id": 1, "transcription": ["to_mail", "mail_badge", "to_shop"],
+  "bbox": [
+    {"x": 10, "y": 50, "width": 20, "height": 5, "original_width": 1080, "original_height": 2400},
+    {"x": 27.777777, "y": 49.166667, "width": 3.703704, "height": 1.666667, "original_width": 1080, "original_height": 2400},
+    {"x": 50, "y": 90, "width": 20, "height": 5, "original_width": 1080, "original_height": 2400}]}]`
+
+func loadAreas(t *testing.T) *config.AreaLookup {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "area.json")
+	require.NoError(t, os.WriteFile(path, []byte(areaJSON), 0o644))
+
+	areas, err := config.LoadAreaReferences(path)
+	require.NoError(t, err)
+	return areas
+}
+
+func TestPropose(t *testing.T) {
+	areas := loadAreas(t)
+	loc := calibration.ScreenLocator{OCR: domain.OCRResults{
+		{Text: "Mail", X: 150, Y: 1240, Width: 60, Height: 40}, // 36px left of the expected center
+		{Text: "Mail", X: 900, Y: 100, Width: 60, Height: 40},  // far away
+		{Text: "Shop", X: 620, Y: 2200, Width: 60, Height: 40}, // 2px off
+		{Text: "Mali", X: 150, Y: 840, Width: 60, Height: 40},  // typo, but farther
+	}}
+
+	anchors := []calibration.Anchor{
+		{Region: "to_mail", Text: "Mail", Follow: []string{"mail_badge"}},
+		{Region: "to_shop", Text: "Shop"},
+		{Region: "to_arena", Text: "Arena"},
+	}
+
+	proposals, err := calibration.Propose(areas, anchors, loc, 4)
+	require.NoError(t, err)
+	require.Len(t, proposals, 4)
+
+	mail := proposals[0]
+	assert.Equal(t, calibration.StatusMoved, mail.Status)
+	assert.Equal(t, config.AreaLayerBase, mail.Layer)
+	assert.Equal(t, image.Pt(-36, 0), mail.Shift()) // the nearest "Mail" is centered at (180,1260), the region at (216,1260)
+
+	badge := proposals[1]
+	assert.Equal(t, "to_mail", badge.Anchor)
+	assert.Equal(t, mail.Shift(), badge.Shift())
+
+	assert.Equal(t, calibration.StatusUnchanged, proposals[2].Status)
+	assert.Equal(t, calibration.StatusUnknown, proposals[3].Status)
+
+	assert.Equal(t, 2, calibration.Apply(areas, proposals))
+	assert.Equal(t, config.AreaLayerOverride, areas.Layer("to_mail"))
+	assert.Equal(t, config.AreaLayerBase, areas.Layer("to_shop"))
+
+	region, _ := areas.Get("to_mail")
+	assert.InDelta(t, mail.New.Min.X, region.Zone.Min.X, 1)
+
+	var report bytes.Buffer
+	require.NoError(t, calibration.WriteReport(&report, proposals))
+	assert.Contains(t, report.String(), "mail_badge")
+	assert.Contains(t, report.String(), "-36,+0")
+}
+
+func TestLoadAnchors_Validates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anchors.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- region: to_mail\n  text: Mail\n  icon: mail\n"), 0o644))
+
+	_, err := calibration.LoadAnchors(path)
+	assert.Error(t, err)
+
+	anchors, err := calibration.LoadAnchors("../../references/anchors.yaml")
+	require.NoError(t, err)
+	assert.NotEmpty(t, anchors)
+}
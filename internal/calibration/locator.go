@@ -0,0 +1,49 @@
+package calibration
+
+import (
+	"image"
+	"math"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// ScreenLocator finds anchors in the OCR results and the image of one captured frame.
+type ScreenLocator struct {
+	OCR    domain.OCRResults
+	Screen image.Image
+	Icons  *vision.IconMatcher
+}
+
+// FindText returns the OCR box containing the text (one typo allowed).
+func (l ScreenLocator) FindText(text string, near image.Rectangle) (image.Rectangle, bool) {
+	var candidates []image.Rectangle
+	for _, r := range l.OCR {
+		if vision.FuzzySubstringMatch(r.Text, text, 1) {
+			candidates = append(candidates, image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height))
+		}
+	}
+	return nearest(candidates, near)
+}
+
+// FindIcon matches the icon on the screen image.
+func (l ScreenLocator) FindIcon(name string, threshold float64, near image.Rectangle) (image.Rectangle, bool, error) {
+	resp, err := l.Icons.FindImageIn(l.Screen, name, threshold)
+	if err != nil {
+		return image.Rectangle{}, false, err
+	}
+	r, ok := nearest(resp.ToRects(), near)
+	return r, ok, nil
+}
+
+// nearest picks the candidate whose center is closest to the expected box.
+func nearest(candidates []image.Rectangle, near image.Rectangle) (image.Rectangle, bool) {
+	best, bestDist := image.Rectangle{}, math.MaxFloat64
+	for _, c := range candidates {
+		d := center(c).Sub(center(near))
+		if dist := math.Hypot(float64(d.X), float64(d.Y)); dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best, len(candidates) > 0
+}
@@ -0,0 +1,333 @@
+// Package classifier identifies the current game screen from several signals:
+// the OCR'd title, the city/world switch, OCR tokens, anchor icons and highlighted tabs.
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
+)
+
+// Signal weights: the title decides the group, the rest tells sub-screens apart.
+const (
+	weightTitle     = 3
+	weightToken     = 1
+	weightAbsent    = 1
+	weightIcon      = 2
+	weightHighlight = 2
+
+	defaultIconThreshold = 0.8
+	defaultMinConfidence = 0.5
+)
+
+var ErrUnknownScreen = errors.New("unknown screen")
+
+// familyTitles – pseudo titles picked by the city/world switch.
+// The switch shows where you can go, so "world" means we are in the city.
+var familyTitles = map[string]string{
+	"MainCity": "world",
+	"World":    "city",
+}
+
+// IconFinder searches an icon on the current screen (implemented by ocrclient.Client).
+type IconFinder interface {
+	FindImage(ctx context.Context, imageName string, threshold float64, debugName string) (*ocrclient.FindImageResponse, error)
+}
+
+// Signals describes the current screen. OCR and icons are requested lazily, only if a candidate needs them.
+type Signals struct {
+	Title    string                            // screenState.titleFact
+	Family   string                            // screenState.isMainCity
+	OCR      func() (domain.OCRResults, error) // full screen OCR (optional)
+	Icons    IconFinder                        // optional
+	Language string                            // game language; titles and tokens also match their translations
+}
+
+// Candidate is a scored screen.
+type Candidate struct {
+	Screen     string
+	Score      int
+	MaxScore   int
+	Confidence float64
+	Matched    []string
+	Missed     []string
+}
+
+// Result of a classification.
+type Result struct {
+	Screen     string
+	Confidence float64
+	Ambiguous  bool        // the best candidates can't be told apart
+	Candidates []Candidate // sorted, best first
+}
+
+type Classifier struct {
+	areas         *config.AreaLookup
+	logger        *slog.Logger
+	MinConfidence float64
+	Catalog       *config.Catalog // translations of titles and tokens
+}
+
+func New(areas *config.AreaLookup, logger *slog.Logger) *Classifier {
+	return &Classifier{
+		areas:         areas,
+		logger:        logger,
+		MinConfidence: defaultMinConfidence,
+		Catalog:       config.DefaultCatalog(),
+	}
+}
+
+// Classify scores every candidate screen of the graph and returns the best one.
+// hint (the expected or last known screen) wins ties.
+// ErrUnknownScreen is returned if no screen matches with enough confidence.
+func (c *Classifier) Classify(ctx context.Context, graph *config.FSMGraph, signals Signals, hint string) (Result, error) {
+	probe := &probe{ctx: ctx, signals: signals, catalog: c.Catalog, icons: make(map[string]bool)}
+	title := c.matchTitle(graph, signals)
+
+	var candidates []Candidate
+	for _, screen := range graph.Screens {
+		// untitled screens are only considered when the title tells nothing
+		switch {
+		case screen.Title != "" && screen.Title == title:
+		case screen.Title == "" && screen.Identify != nil && title == "":
+		default:
+			continue
+		}
+
+		candidate := c.score(screen, title, probe)
+
+		// an untitled screen needs at least one positive signal
+		if screen.Title == "" && !hasPositive(candidate) {
+			continue
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	order := make(map[string]int, len(graph.Screens))
+	for i, name := range graph.ScreenNames() {
+		order[name] = i
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if (a.Screen == hint) != (b.Screen == hint) {
+			return a.Screen == hint
+		}
+		return order[a.Screen] < order[b.Screen]
+	})
+
+	result := Result{Candidates: candidates}
+	if len(candidates) == 0 {
+		return result, fmt.Errorf("%w: title %q, family %q", ErrUnknownScreen, signals.Title, signals.Family)
+	}
+
+	best := candidates[0]
+	result.Screen = best.Screen
+	result.Confidence = best.Confidence
+	result.Ambiguous = len(candidates) > 1 &&
+		candidates[1].Score == best.Score && candidates[1].Confidence == best.Confidence
+
+	if best.Confidence < c.MinConfidence {
+		return result, fmt.Errorf("%w: best candidate %s with confidence %.2f", ErrUnknownScreen, best.Screen, best.Confidence)
+	}
+
+	if probe.err != nil && c.logger != nil {
+		c.logger.Warn("classifier: some signals are unavailable", slog.Any("error", probe.err))
+	}
+
+	return result, nil
+}
+
+// score evaluates all signals of a screen.
+func (c *Classifier) score(screen config.GraphScreen, title string, p *probe) Candidate {
+	cand := Candidate{Screen: screen.Name}
+	check := func(name string, weight int, ok bool) {
+		cand.MaxScore += weight
+		if ok {
+			cand.Score += weight
+			cand.Matched = append(cand.Matched, name)
+		} else {
+			cand.Missed = append(cand.Missed, name)
+		}
+	}
+
+	if screen.Title != "" {
+		check("title:"+screen.Title, weightTitle, screen.Title == title)
+	}
+
+	if id := screen.Identify; id != nil {
+		for _, token := range id.Tokens {
+			check("token:"+token, weightToken, p.hasToken(token))
+		}
+		for _, token := range id.Absent {
+			check("absent:"+token, weightAbsent, p.ocrAvailable() && !p.hasToken(token))
+		}
+
+		threshold := id.IconThreshold
+		if threshold == 0 {
+			threshold = defaultIconThreshold
+		}
+		for _, icon := range id.Icons {
+			check("icon:"+icon, weightIcon, p.hasIcon(icon, threshold))
+		}
+
+		for _, h := range id.Highlights {
+			check("highlight:"+h.Region, weightHighlight, c.highlighted(h, p))
+		}
+	}
+
+	if cand.MaxScore > 0 {
+		cand.Confidence = float64(cand.Score) / float64(cand.MaxScore)
+	}
+
+	return cand
+}
+
+// highlighted reports whether the region is painted in the expected color.
+func (c *Classifier) highlighted(h config.GraphHighlight, p *probe) bool {
+	if c.areas == nil {
+		return false
+	}
+
+	bbox, err := c.areas.GetRegionByName(h.Region)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range p.ocr().FilterByBBox(bbox) {
+		if h.Bg != "" && r.BgColor == h.Bg {
+			return true
+		}
+		if h.Text != "" && r.AvgColor == h.Text {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTitle returns the title of the screen group: the city/world switch wins,
+// otherwise the most specific title found in the OCR'd title.
+func (c *Classifier) matchTitle(graph *config.FSMGraph, signals Signals) string {
+	for _, title := range []string{"MainCity", "World"} {
+		for _, text := range c.Catalog.Variants(signals.Language, familyTitles[title]) {
+			if vision.FuzzySubstringMatch(signals.Family, text, 1) {
+				return title
+			}
+		}
+	}
+
+	if signals.Title == "" {
+		return ""
+	}
+
+	for _, title := range graph.Titles() {
+		if _, ok := familyTitles[title]; ok {
+			continue
+		}
+		for _, text := range c.Catalog.Variants(signals.Language, title) {
+			if vision.FuzzySubstringMatch(signals.Title, text, 0) {
+				return title
+			}
+		}
+	}
+	return ""
+}
+
+func hasPositive(c Candidate) bool {
+	for _, m := range c.Matched {
+		if !strings.HasPrefix(m, "absent:") {
+			return true
+		}
+	}
+	return false
+}
+
+// probe fetches expensive signals at most once per classification.
+type probe struct {
+	ctx     context.Context
+	signals Signals
+	catalog *config.Catalog
+
+	ocrDone bool
+	ocrRes  domain.OCRResults
+	ocrOK   bool
+	icons   map[string]bool
+	err     error
+}
+
+func (p *probe) ocr() domain.OCRResults {
+	if p.ocrDone {
+		return p.ocrRes
+	}
+	p.ocrDone = true
+
+	if p.signals.OCR == nil {
+		return nil
+	}
+
+	res, err := p.signals.OCR()
+	if err != nil {
+		p.err = errors.Join(p.err, fmt.Errorf("ocr: %w", err))
+		return nil
+	}
+
+	p.ocrRes, p.ocrOK = res, true
+	return res
+}
+
+func (p *probe) ocrAvailable() bool {
+	p.ocr()
+	return p.ocrOK
+}
+
+func (p *probe) hasToken(token string) bool {
+	// short tokens must match exactly, otherwise one OCR typo is tolerated
+	maxDistance := 1
+	if len(token) < 6 {
+		maxDistance = 0
+	}
+
+	variants := p.catalog.Variants(p.signals.Language, token)
+	for _, r := range p.ocr() {
+		for _, text := range variants {
+			if vision.FuzzySubstringMatch(r.Text, text, maxDistance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *probe) hasIcon(name string, threshold float64) bool {
+	if found, ok := p.icons[name]; ok {
+		return found
+	}
+
+	found := false
+	if p.signals.Icons != nil {
+		resp, err := p.signals.Icons.FindImage(p.ctx, name, threshold, "classifier."+name)
+		if err != nil {
+			p.err = errors.Join(p.err, fmt.Errorf("icon %s: %w", name, err))
+		} else {
+			found = resp.Found
+		}
+	}
+
+	p.icons[name] = found
+	return found
+}
@@ -0,0 +1,253 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+const testGraph = `
+screens:
+  - name: main_city
+    title: MainCity
+    edges:
+      - to: world
+        steps: [{ click: to_world }]
+      - to: alliance_chests
+        steps: [{ click: to_alliance_chests }]
+      - to: mail
+        steps: [{ click: to_mail }]
+      - to: daily_missions
+        steps: [{ click: to_daily_missions }]
+  - name: world
+    title: World
+    edges:
+      - to: main_city
+        steps: [{ click: to_main_city }]
+  - name: alliance_chests
+    title: Chests
+    edges:
+      - to: alliance_chest_gift
+        steps: [{ click: to_alliance_chest_gift }]
+      - to: main_city
+        steps: [{ click: page_back }]
+  - name: alliance_chest_gift
+    title: Chests
+    identify:
+      tokens: ["Send Anonymous"]
+    edges:
+      - to: alliance_chests
+        steps: [{ click: to_alliance_chest_loot }]
+  - name: alliance_chest_loot
+    title: Chests
+    external: true
+    edges:
+      - to: alliance_chests
+        steps: [{ click: page_back }]
+  - name: mail
+    title: Mail
+    edges:
+      - to: mail_reports
+        steps: [{ click: to_mail_reports }]
+      - to: main_city
+        steps: [{ click: mail_close }]
+  - name: mail_reports
+    title: Mail
+    identify:
+      highlights:
+        - { region: to_mail_reports, text: blue }
+    edges:
+      - to: mail
+        steps: [{ click: mail_close }]
+  - name: daily_missions
+    identify:
+      tokens: ["Refreshes In"]
+      icons: [daily_missions_chest]
+    edges:
+      - to: main_city
+        steps: [{ click: page_back }]
+`
+
+type fakeIcons map[string]bool
+
+func (f fakeIcons) FindImage(_ context.Context, imageName string, _ float64, _ string) (*ocrclient.FindImageResponse, error) {
+	return &ocrclient.FindImageResponse{Found: f[imageName]}, nil
+}
+
+func newTestGraph(t *testing.T) *config.FSMGraph {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fsmGraph.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testGraph), 0644))
+
+	graph, err := config.LoadFSMGraph(path, nil)
+	require.NoError(t, err)
+	return graph
+}
+
+func ocr(words ...domain.OCRResult) func() (domain.OCRResults, error) {
+	return func() (domain.OCRResults, error) { return words, nil }
+}
+
+func TestClassify_SubTabByToken(t *testing.T) {
+	c := New(nil, nil)
+	graph := newTestGraph(t)
+
+	res, err := c.Classify(context.Background(), graph, Signals{
+		Title: "Chests",
+		OCR:   ocr(domain.OCRResult{Text: "Send Anonymous Alliance Gift"}),
+	}, "alliance_chests")
+	require.NoError(t, err)
+	require.Equal(t, "alliance_chest_gift", res.Screen)
+	require.False(t, res.Ambiguous)
+
+	res, err = c.Classify(context.Background(), graph, Signals{
+		Title: "Chests",
+		OCR:   ocr(domain.OCRResult{Text: "Claim"}),
+	}, "alliance_chest_gift")
+	require.NoError(t, err)
+	require.Equal(t, "alliance_chests", res.Screen, "the gift tab misses its token")
+}
+
+func TestClassify_SubTabByHighlight(t *testing.T) {
+	areas, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	bbox, err := areas.GetRegionByName("to_mail_reports")
+	require.NoError(t, err)
+	rect := bbox.ToRectangle()
+
+	word := domain.OCRResult{
+		Text:     "Reports",
+		X:        rect.Min.X + 5,
+		Y:        rect.Min.Y + 5,
+		Width:    rect.Dx() - 10,
+		Height:   rect.Dy() - 10,
+		AvgColor: "blue",
+	}
+
+	res, err := New(areas, nil).Classify(context.Background(), newTestGraph(t), Signals{Title: "Mail", OCR: ocr(word)}, "")
+	require.NoError(t, err)
+	require.Equal(t, "mail_reports", res.Screen)
+
+	word.AvgColor = "white"
+	res, err = New(areas, nil).Classify(context.Background(), newTestGraph(t), Signals{Title: "Mail", OCR: ocr(word)}, "")
+	require.NoError(t, err)
+	require.Equal(t, "mail", res.Screen)
+}
+
+func TestClassify_HintBreaksTies(t *testing.T) {
+	c := New(nil, nil)
+	graph := newTestGraph(t)
+
+	res, err := c.Classify(context.Background(), graph, Signals{Title: "Chests"}, "alliance_chest_loot")
+	require.NoError(t, err)
+	require.Equal(t, "alliance_chest_loot", res.Screen)
+	require.True(t, res.Ambiguous)
+
+	res, err = c.Classify(context.Background(), graph, Signals{Title: "Chests"}, "")
+	require.NoError(t, err)
+	require.Equal(t, "alliance_chests", res.Screen, "declaration order without a hint")
+
+	res, err = c.Classify(context.Background(), graph, Signals{Title: "Mail"}, "mail_reports")
+	require.NoError(t, err)
+	require.Equal(t, "mail", res.Screen, "the hint doesn't beat a missing highlight")
+}
+
+func TestClassify_Family(t *testing.T) {
+	c := New(nil, nil)
+	graph := newTestGraph(t)
+
+	res, err := c.Classify(context.Background(), graph, Signals{Title: "Mail", Family: "World"}, "")
+	require.NoError(t, err)
+	require.Equal(t, "main_city", res.Screen, "the switch shows world, so we are in the city")
+
+	res, err = c.Classify(context.Background(), graph, Signals{Family: "City"}, "")
+	require.NoError(t, err)
+	require.Equal(t, "world", res.Screen)
+}
+
+func TestClassify_UntitledScreen(t *testing.T) {
+	c := New(nil, nil)
+	graph := newTestGraph(t)
+
+	res, err := c.Classify(context.Background(), graph, Signals{
+		OCR:   ocr(domain.OCRResult{Text: "Refreshes In: 10:00:00"}),
+		Icons: fakeIcons{"daily_missions_chest": true},
+	}, "")
+	require.NoError(t, err)
+	require.Equal(t, "daily_missions", res.Screen)
+	require.Equal(t, 1.0, res.Confidence)
+
+	res, err = c.Classify(context.Background(), graph, Signals{Title: "Mail", OCR: ocr(domain.OCRResult{Text: "Refreshes In"})}, "")
+	require.NoError(t, err)
+	require.Equal(t, "mail", res.Screen, "a matched title hides untitled screens")
+}
+
+func TestClassify_UnknownScreen(t *testing.T) {
+	c := New(nil, nil)
+	graph := newTestGraph(t)
+
+	_, err := c.Classify(context.Background(), graph, Signals{Title: "Something new", OCR: ocr()}, "mail")
+	require.True(t, errors.Is(err, ErrUnknownScreen))
+
+	_, err = c.Classify(context.Background(), graph, Signals{
+		OCR:   ocr(domain.OCRResult{Text: "Refreshes In"}),
+		Icons: fakeIcons{},
+	}, "")
+	require.True(t, errors.Is(err, ErrUnknownScreen), "one weak signal is not enough")
+}
+
+func TestClassify_OCRIsLazy(t *testing.T) {
+	calls := 0
+	signals := Signals{
+		Family: "City",
+		OCR: func() (domain.OCRResults, error) {
+			calls++
+			return nil, nil
+		},
+	}
+
+	graph := newTestGraph(t)
+	_, err := New(nil, nil).Classify(context.Background(), graph, signals, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, calls, "no candidate needs OCR")
+
+	signals.Family = ""
+	signals.Title = "Chests"
+	_, err = New(nil, nil).Classify(context.Background(), graph, signals, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls, "OCR is fetched once per classification")
+}
+
+func TestClassify_Language(t *testing.T) {
+	catalog, err := config.LoadCatalog("../../references/i18n.yaml")
+	require.NoError(t, err)
+
+	c := New(nil, nil)
+	c.Catalog = catalog
+	graph := newTestGraph(t)
+
+	res, err := c.Classify(context.Background(), graph, Signals{
+		Title:    "Сундуки",
+		OCR:      ocr(domain.OCRResult{Text: "Отправить анонимно"}),
+		Language: "ru",
+	}, "")
+	require.NoError(t, err)
+	require.Equal(t, "alliance_chest_gift", res.Screen)
+
+	res, err = c.Classify(context.Background(), graph, Signals{Family: "Мир", Language: "ru"}, "")
+	require.NoError(t, err)
+	require.Equal(t, "main_city", res.Screen)
+
+	_, err = c.Classify(context.Background(), graph, Signals{Title: "Сундуки", OCR: ocr()}, "")
+	require.ErrorIs(t, err, ErrUnknownScreen, "without the language the title is unknown")
+}
@@ -7,6 +7,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
 )
 
 type ScreenAnalyzeRules map[string][]domain.AnalyzeRule
@@ -25,7 +26,7 @@ func LoadAnalyzeRules(path string) (ScreenAnalyzeRules, error) {
 	// Validate actions
 	for screen, ruleList := range rules {
 		for _, rule := range ruleList {
-			if err := rule.Validate(); err != nil {
+			if err := ValidateAnalyzeRule(rule); err != nil {
 				return nil, fmt.Errorf("screen '%s': %w", screen, err)
 			}
 		}
@@ -33,3 +34,17 @@ func LoadAnalyzeRules(path string) (ScreenAnalyzeRules, error) {
 
 	return rules, nil
 }
+
+// ValidateAnalyzeRule checks a rule like AnalyzeRule.Validate and, in addition, the palette colors
+// of color_sample rules (the palette lives in vision, which domain can't import).
+func ValidateAnalyzeRule(rule domain.AnalyzeRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if rule.Action == "color_sample" && rule.Color != "" && !vision.IsPaletteColor(rule.Color) {
+		return fmt.Errorf("color_sample rule '%s': unknown color '%s'", rule.Name, rule.Color)
+	}
+
+	return nil
+}
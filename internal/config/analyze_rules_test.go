@@ -0,0 +1,26 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+func TestValidateAnalyzeRule_PaletteColor(t *testing.T) {
+	rule := domain.AnalyzeRule{Name: "shop.state.isNotify", Action: "color_sample", Color: "red"}
+	require.NoError(t, config.ValidateAnalyzeRule(rule))
+
+	rule.Color = "crimson"
+	require.ErrorContains(t, config.ValidateAnalyzeRule(rule), "unknown color 'crimson'")
+
+	rule.Action = "color_check" // colors of OCR boxes are not palette names
+	require.NoError(t, config.ValidateAnalyzeRule(rule))
+}
+
+func TestLoadAnalyzeRules_References(t *testing.T) {
+	_, err := config.LoadAnalyzeRules("../../references/analyze.yaml")
+	require.NoError(t, err)
+}
@@ -1,116 +1,352 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 )
 
+// Layers of the area lookup, from the lowest priority.
+const (
+	AreaLayerBase      = "base"      // references/area.json
+	AreaLayerOverride  = "override"  // per-device corrections
+	AreaLayerTemporary = "temporary" // regions saved at runtime from OCR/icon matches
+)
+
+// DefaultTemporaryRegionTTL is how long a region saved at runtime stays valid.
+const DefaultTemporaryRegionTTL = 2 * time.Minute
+
+var (
+	// ErrRegionExpired is returned for a temporary region whose TTL has passed: its coordinates are stale.
+	ErrRegionExpired = errors.New("temporary region expired")
+	// ErrRegionDefined is returned when a temporary region would shadow a region of area.json or the overrides.
+	ErrRegionDefined = errors.New("region is defined in the area file")
+)
+
+// TemporaryRegionOptions control the lifetime of a region saved at runtime.
+type TemporaryRegionOptions struct {
+	Scope    string        // released by ReleaseScope, e.g. one usecase execution
+	TTL      time.Duration // 0 – DefaultTemporaryRegionTTL
+	Override bool          // allow shadowing a region of the area file
+}
+
+type regionScopeKey struct{}
+
+// WithRegionScope returns ctx whose temporary regions belong to scope.
+func WithRegionScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, regionScopeKey{}, scope)
+}
+
+// RegionScope returns the temporary region scope of ctx, empty outside of a scope.
+func RegionScope(ctx context.Context) string {
+	scope, _ := ctx.Value(regionScopeKey{}).(string)
+	return scope
+}
+
 type Region struct {
 	Zone image.Rectangle
 }
 
+// AreaLookup holds the labelled regions in reference coordinates and returns them scaled to the device.
+// A region is looked up in the temporary layer first, then in the device overrides, then in the base file.
 type AreaLookup struct {
-	refs atomic.Value
+	layers atomic.Pointer[areaLayers]
+	scale  atomic.Pointer[domain.ScreenScale]
 }
 
-// LoadAreaReferences reads the file and seeds the atomic.Value
+// areaLayers is replaced as a whole on every change (copy-on-write).
+type areaLayers struct {
+	base      []domain.AreaReference
+	override  []domain.AreaReference
+	temporary map[string]temporaryRegion
+}
+
+type temporaryRegion struct {
+	bbox    domain.BBox
+	scope   string
+	expires time.Time
+}
+
+// LoadAreaReferences reads the base file and seeds the lookup
 func LoadAreaReferences(file string) (*AreaLookup, error) {
+	base, err := readAreaFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	al := &AreaLookup{}
+	al.layers.Store(&areaLayers{base: base})
+
+	return al, nil
+}
+
+func readAreaFile(file string) ([]domain.AreaReference, error) {
 	data, err := os.ReadFile(filepath.Clean(file))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read area file: %w", err)
 	}
 
-	var initial []domain.AreaReference
-	if err := json.Unmarshal(data, &initial); err != nil {
+	var refs []domain.AreaReference
+	if err := json.Unmarshal(data, &refs); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal area json: %w", err)
 	}
+	return refs, nil
+}
 
-	al := &AreaLookup{}
-	// Store a copy (just in case)
-	cp := make([]domain.AreaReference, len(initial))
-	copy(cp, initial)
-	al.refs.Store(cp)
+// AreaOverridePath returns the override file of the device in PATH_TO_AREA_OVERRIDES.
+func AreaOverridePath(deviceID string) string {
+	viper.SetDefault("PATH_TO_AREA_OVERRIDES", "db/areas")
 
-	return al, nil
+	name := strings.NewReplacer(":", "_", "/", "_").Replace(deviceID)
+	return filepath.Join(viper.GetString("PATH_TO_AREA_OVERRIDES"), name+".json")
 }
 
-// snapshot returns the current slice snapshot
-func (a *AreaLookup) snapshot() []domain.AreaReference {
-	return a.refs.Load().([]domain.AreaReference)
+// LoadOverrides reads the per-device override file (same format as area.json); a missing file means no overrides.
+func (a *AreaLookup) LoadOverrides(file string) error {
+	override, err := readAreaFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	next := *a.snapshot()
+	next.override = override
+	a.layers.Store(&next)
+
+	slog.Info("🗺️ Loaded area overrides", slog.String("file", file), slog.Int("regions", countRegions(override)))
+	return nil
 }
 
-// Get returns Region for a given transcription name (lock-free)
-func (a *AreaLookup) Get(name string) (Region, bool) {
-	// load a consistent snapshot of your []AreaReference
-	for _, area := range a.refs.Load().([]domain.AreaReference) {
-		for i, label := range area.Transcription {
-			if label == name && i < len(area.BBox) {
-				b := area.BBox[i]
-				x, y, w, h := b.ToPixels()
-				return Region{Zone: image.Rect(x, y, x+w, y+h)}, true
-			}
+// SetOverride replaces the device override of the region; the box is in reference coordinates.
+func (a *AreaLookup) SetOverride(name string, bbox domain.BBox) {
+	cur := a.snapshot()
+
+	override := make([]domain.AreaReference, 0, len(cur.override)+1)
+	for _, ref := range cur.override {
+		if !ref.Has(name) {
+			override = append(override, ref)
 		}
 	}
-	return Region{}, false
+	override = append(override, domain.AreaReference{
+		OCR:           "calibrated",
+		ID:            -1,
+		BBox:          []domain.BBox{bbox},
+		Transcription: []string{name},
+	})
+	sort.Slice(override, func(i, j int) bool {
+		return override[i].Transcription[0] < override[j].Transcription[0]
+	})
+
+	next := *cur
+	next.override = override
+	a.layers.Store(&next)
+}
+
+// SaveOverrides writes the override layer to file.
+func (a *AreaLookup) SaveOverrides(file string) error {
+	data, err := json.MarshalIndent(a.snapshot().override, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("failed to create override dir: %w", err)
+	}
+	return os.WriteFile(file, data, 0o644)
 }
 
-// GetRegionByName does an atomic Load and is lock-free
-func (a *AreaLookup) GetRegionByName(name string) (*domain.BBox, error) {
-	for _, area := range a.snapshot() {
+// SetScale sets the screen of the device regions are returned for.
+func (a *AreaLookup) SetScale(scale domain.ScreenScale) {
+	a.scale.Store(&scale)
+}
+
+// Scale returns the screen scale of the device, the identity until SetScale is called.
+func (a *AreaLookup) Scale() domain.ScreenScale {
+	if a == nil {
+		return domain.IdentityScale()
+	}
+	if scale := a.scale.Load(); scale != nil {
+		return *scale
+	}
+	return domain.IdentityScale()
+}
+
+// snapshot returns the current layers
+func (a *AreaLookup) snapshot() *areaLayers {
+	return a.layers.Load()
+}
+
+// lookup returns the reference box of the region and the layer it comes from.
+// An expired temporary region is an error rather than a fallback to the area file.
+func (a *AreaLookup) lookup(name string) (domain.BBox, string, error) {
+	layers := a.snapshot()
+
+	if t, ok := layers.temporary[name]; ok {
+		if time.Now().After(t.expires) {
+			return domain.BBox{}, AreaLayerTemporary, fmt.Errorf("region '%s': %w", name, ErrRegionExpired)
+		}
+		return t.bbox, AreaLayerTemporary, nil
+	}
+	if b, ok := findBBox(layers.override, name); ok {
+		return b, AreaLayerOverride, nil
+	}
+	if b, ok := findBBox(layers.base, name); ok {
+		return b, AreaLayerBase, nil
+	}
+	return domain.BBox{}, "", fmt.Errorf("region '%s' not found", name)
+}
+
+func findBBox(refs []domain.AreaReference, name string) (domain.BBox, bool) {
+	for _, area := range refs {
 		for i, label := range area.Transcription {
 			if label == name && i < len(area.BBox) {
-				return &area.BBox[i], nil
+				return area.BBox[i], true
 			}
 		}
 	}
-	return nil, fmt.Errorf("region '%s' not found", name)
-}
-
-// AddTemporaryRegion does copy-on-write and then a single atomic Store
-func (a *AreaLookup) AddTemporaryRegion(name string, region Region) {
-	old := a.snapshot()
-	// make a new slice so we don't mutate the old one in-place
-	newSlice := make([]domain.AreaReference, len(old))
-	copy(newSlice, old)
-
-	bbox := domain.NewBBoxFromRect(region.Zone, 1080, 2400)
-	updated := false
-
-	// try to update an existing entry
-	for i := range newSlice {
-		for j, label := range newSlice[i].Transcription {
-			if label == name {
-				if j < len(newSlice[i].BBox) {
-					newSlice[i].BBox[j] = bbox
-					updated = true
-					slog.Info("🛠️ Updated temporary region", slog.String("name", name), slog.Float64("x", bbox.X), slog.Float64("y", bbox.Y))
-				}
-				break
+	return domain.BBox{}, false
+}
+
+func countRegions(refs []domain.AreaReference) int {
+	n := 0
+	for _, ref := range refs {
+		n += len(ref.Transcription)
+	}
+	return n
+}
+
+// Layer returns which layer the region is taken from, empty if it is unknown.
+func (a *AreaLookup) Layer(name string) string {
+	_, layer, _ := a.lookup(name)
+	return layer
+}
+
+// Names returns every region name of all layers, sorted.
+func (a *AreaLookup) Names() []string {
+	layers := a.snapshot()
+
+	seen := make(map[string]struct{})
+	for _, refs := range [][]domain.AreaReference{layers.base, layers.override} {
+		for _, ref := range refs {
+			for _, label := range ref.Transcription {
+				seen[label] = struct{}{}
 			}
 		}
-		if updated {
-			break
+	}
+	for name := range layers.temporary {
+		seen[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns Region in device pixels for a given transcription name (lock-free)
+func (a *AreaLookup) Get(name string) (Region, bool) {
+	b, err := a.GetRegionByName(name)
+	if err != nil {
+		return Region{}, false
+	}
+	x, y, w, h := b.ToPixels()
+	return Region{Zone: image.Rect(x, y, x+w, y+h)}, true
+}
+
+// GetRegionByName does an atomic Load and is lock-free; the box is scaled to the device screen
+func (a *AreaLookup) GetRegionByName(name string) (*domain.BBox, error) {
+	b, _, err := a.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	b = a.Scale().BBox(b)
+	return &b, nil
+}
+
+// AddTemporaryRegion does copy-on-write and then a single atomic Store.
+// The region is in device pixels (an OCR or icon match box).
+func (a *AreaLookup) AddTemporaryRegion(name string, region Region, opts TemporaryRegionOptions) error {
+	cur := a.snapshot()
+
+	if !opts.Override {
+		if _, ok := findBBox(cur.override, name); ok {
+			return fmt.Errorf("region '%s': %w", name, ErrRegionDefined)
+		}
+		if _, ok := findBBox(cur.base, name); ok {
+			return fmt.Errorf("region '%s': %w", name, ErrRegionDefined)
 		}
 	}
 
-	// if we didn’t find it, append
-	if !updated {
-		newSlice = append(newSlice, domain.AreaReference{
-			OCR:           "generated",
-			ID:            -1,
-			BBox:          []domain.BBox{bbox},
-			Transcription: []string{name},
-		})
-		slog.Info("🗺️ Added temporary zone", slog.String("name", name), slog.Float64("x", bbox.X), slog.Float64("y", bbox.Y))
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultTemporaryRegionTTL
+	}
+	bbox := domain.NewBBoxFromRect(a.Scale().Unscale(region.Zone), domain.ReferenceWidth, domain.ReferenceHeight)
+
+	temporary := make(map[string]temporaryRegion, len(cur.temporary)+1)
+	for k, v := range cur.temporary {
+		temporary[k] = v
+	}
+	_, updated := temporary[name]
+	temporary[name] = temporaryRegion{bbox: bbox, scope: opts.Scope, expires: time.Now().Add(ttl)}
+
+	next := *cur
+	next.temporary = temporary
+	a.layers.Store(&next)
+
+	attrs := []any{slog.String("name", name), slog.String("scope", opts.Scope), slog.Float64("x", bbox.X), slog.Float64("y", bbox.Y)}
+	if updated {
+		slog.Info("🛠️ Updated temporary region", attrs...)
+	} else {
+		slog.Info("🗺️ Added temporary zone", attrs...)
+	}
+	return nil
+}
+
+// ReleaseScope drops the temporary regions saved in the scope.
+func (a *AreaLookup) ReleaseScope(scope string) {
+	a.dropTemporary(func(t temporaryRegion) bool { return t.scope == scope })
+}
+
+// ClearTemporary drops all temporary regions, e.g. when another account is opened.
+func (a *AreaLookup) ClearTemporary() {
+	a.dropTemporary(func(temporaryRegion) bool { return true })
+}
+
+func (a *AreaLookup) dropTemporary(drop func(temporaryRegion) bool) {
+	if a == nil {
+		return
+	}
+	cur := a.snapshot()
+
+	temporary := make(map[string]temporaryRegion, len(cur.temporary))
+	for k, v := range cur.temporary {
+		if !drop(v) {
+			temporary[k] = v
+		}
+	}
+	if len(temporary) == len(cur.temporary) {
+		return
 	}
 
-	// one atomic swap
-	a.refs.Store(newSlice)
+	next := *cur
+	next.temporary = temporary
+	a.layers.Store(&next)
 }
@@ -0,0 +1,122 @@
+package config_test
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+const areaJSON = `[{"ocr": "main.png", "id": 1, "transcription": ["to_mail"],
+  "bbox": [{"x": 10, "y": 50, "width": 20, "height": 5, "original_width": 1080, "original_height": 2400}]}]`
+
+func loadAreas(t *testing.T) *config.AreaLookup {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "area.json")
+	require.NoError(t, os.WriteFile(path, []byte(areaJSON), 0o644))
+
+	areas, err := config.LoadAreaReferences(path)
+	require.NoError(t, err)
+	return areas
+}
+
+func TestAreaLookup_Scale(t *testing.T) {
+	areas := loadAreas(t)
+
+	region, ok := areas.Get("to_mail")
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(108, 1200, 324, 1320), region.Zone)
+
+	areas.SetScale(domain.NewScreenScale(720, 1680)) // 0.667 and 40px bars at the top and bottom
+
+	region, ok = areas.Get("to_mail")
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(72, 840, 216, 920), region.Zone)
+
+	bbox, err := areas.GetRegionByName("to_mail")
+	require.NoError(t, err)
+	assert.Equal(t, region.Zone, bbox.ToRectangle())
+}
+
+func TestAreaLookup_TemporaryRegionInDevicePixels(t *testing.T) {
+	areas := loadAreas(t)
+	areas.SetScale(domain.NewScreenScale(720, 1680))
+
+	// e.g. an OCR box on the device screenshot
+	require.NoError(t, areas.AddTemporaryRegion("found_button", config.Region{Zone: image.Rect(72, 840, 216, 920)}, config.TemporaryRegionOptions{}))
+
+	region, ok := areas.Get("found_button")
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(72, 840, 216, 920), region.Zone)
+}
+
+func TestAreaLookup_Layers(t *testing.T) {
+	areas := loadAreas(t)
+	assert.Equal(t, config.AreaLayerBase, areas.Layer("to_mail"))
+
+	override := filepath.Join(t.TempDir(), "device.json")
+	require.NoError(t, areas.LoadOverrides(override), "a missing override file is not an error")
+
+	areas.SetOverride("to_mail", domain.NewBBoxFromRect(image.Rect(108, 960, 324, 1200), domain.ReferenceWidth, domain.ReferenceHeight))
+	require.NoError(t, areas.SaveOverrides(override))
+
+	reloaded := loadAreas(t)
+	require.NoError(t, reloaded.LoadOverrides(override))
+	assert.Equal(t, config.AreaLayerOverride, reloaded.Layer("to_mail"))
+
+	region, _ := reloaded.Get("to_mail")
+	assert.Equal(t, image.Rect(108, 960, 324, 1200), region.Zone)
+
+	// temporary regions win over overrides
+	require.NoError(t, reloaded.AddTemporaryRegion("to_mail", config.Region{Zone: image.Rect(0, 0, 50, 50)}, config.TemporaryRegionOptions{Override: true}))
+	assert.Equal(t, config.AreaLayerTemporary, reloaded.Layer("to_mail"))
+	assert.Equal(t, []string{"to_mail"}, reloaded.Names())
+}
+
+func TestAreaLookup_TemporaryRegionLifetime(t *testing.T) {
+	areas := loadAreas(t)
+	zone := config.Region{Zone: image.Rect(0, 0, 50, 50)}
+
+	t.Run("base regions are not shadowed without override", func(t *testing.T) {
+		err := areas.AddTemporaryRegion("to_mail", zone, config.TemporaryRegionOptions{})
+		require.ErrorIs(t, err, config.ErrRegionDefined)
+		assert.Equal(t, config.AreaLayerBase, areas.Layer("to_mail"))
+	})
+
+	t.Run("expired regions are an error", func(t *testing.T) {
+		require.NoError(t, areas.AddTemporaryRegion("claim", zone, config.TemporaryRegionOptions{TTL: 20 * time.Millisecond}))
+		_, err := areas.GetRegionByName("claim")
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+		_, err = areas.GetRegionByName("claim")
+		require.ErrorIs(t, err, config.ErrRegionExpired)
+		_, ok := areas.Get("claim")
+		assert.False(t, ok)
+	})
+
+	t.Run("scopes are released", func(t *testing.T) {
+		ctx := config.WithRegionScope(context.Background(), "alliance@1")
+		require.NoError(t, areas.AddTemporaryRegion("chest", zone, config.TemporaryRegionOptions{Scope: config.RegionScope(ctx)}))
+		require.NoError(t, areas.AddTemporaryRegion("gift", zone, config.TemporaryRegionOptions{Scope: "other"}))
+
+		areas.ReleaseScope("alliance@1")
+		_, ok := areas.Get("chest")
+		assert.False(t, ok)
+		_, ok = areas.Get("gift")
+		assert.True(t, ok)
+
+		areas.ClearTemporary()
+		_, ok = areas.Get("gift")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"log"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/buildings"
+)
+
+var (
+	buildingTablesOnce sync.Once
+	buildingTables     buildings.Tables
+)
+
+// DefaultBuildingTables returns the process-wide building tables of PATH_TO_BUILDING_TABLES.
+func DefaultBuildingTables() buildings.Tables {
+	buildingTablesOnce.Do(func() {
+		viper.SetDefault("PATH_TO_BUILDING_TABLES", "references/tables/buildings")
+
+		tables, err := buildings.LoadTables(viper.GetString("PATH_TO_BUILDING_TABLES"))
+		if err != nil {
+			log.Printf("[DefaultBuildingTables] %v", err)
+			tables = buildings.Tables{}
+		}
+		buildingTables = tables
+	})
+
+	return buildingTables
+}
+
+// BuildingStrategy returns the building planner strategy of BUILDING_STRATEGY, rush_furnace by default.
+func BuildingStrategy() buildings.Strategy {
+	strategy, err := buildings.ParseStrategy(viper.GetString("BUILDING_STRATEGY"))
+	if err != nil {
+		log.Printf("[BuildingStrategy] %v, using %s", err, buildings.RushFurnace)
+		return buildings.RushFurnace
+	}
+	return strategy
+}
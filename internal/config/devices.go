@@ -1,6 +1,7 @@
 package config
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
@@ -46,8 +47,13 @@ func LoadDeviceConfig(devicesFile string, repo repository.StateRepository) (*dom
 			// 🔄 Merge state for each player
 			for gIdx, gamer := range cfg.Devices[dIdx].Profiles[pIdx].Gamer {
 				if full, ok := stateMap[gamer.ID]; ok {
+					full.Language = cmp.Or(gamer.Language, full.Language)
 					cfg.Devices[dIdx].Profiles[pIdx].Gamer[gIdx] = full
 				}
+
+				// 🌐 The account language wins over the device one
+				g := &cfg.Devices[dIdx].Profiles[pIdx].Gamer[gIdx]
+				g.Language = cmp.Or(g.Language, cfg.Devices[dIdx].Language)
 			}
 
 			// 🔡 Sort players by Nickname
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
+)
+
+// FSMGraph is the declarative screen graph of the FSM (see references/fsmGraph.yaml).
+type FSMGraph struct {
+	Swipes  map[string]GraphSwipe `yaml:"swipes"`
+	Screens []GraphScreen         `yaml:"screens"`
+
+	index map[string]int
+}
+
+// GraphScreen describes a single game screen and its outgoing edges.
+type GraphScreen struct {
+	Name     string      `yaml:"name"`
+	Title    string      `yaml:"title"`
+	External bool        `yaml:"external"` // opened outside the graph, reachability is not checked
+	Terminal bool        `yaml:"terminal"` // no way back to main_city is expected
+	Identify *Identify   `yaml:"identify"`
+	Edges    []GraphEdge `yaml:"edges"`
+}
+
+// Identify lists signals that tell a screen apart from other screens with the same (or no) title.
+type Identify struct {
+	Tokens        []string         `yaml:"tokens"`        // OCR text expected anywhere on the screen
+	Absent        []string         `yaml:"absent"`        // OCR text that must not be on the screen
+	Icons         []string         `yaml:"icons"`         // anchor icons from references/icons (without .png)
+	IconThreshold float64          `yaml:"iconThreshold"` // match threshold for icons (default 0.8)
+	Highlights    []GraphHighlight `yaml:"highlights"`    // highlighted tabs/buttons
+}
+
+// GraphHighlight expects a region to be painted in a color (as reported by OCR: avg_color/bg_color).
+type GraphHighlight struct {
+	Region string `yaml:"region"`
+	Bg     string `yaml:"bg"`
+	Text   string `yaml:"text"`
+}
+
+// GraphEdge is a transition to another screen.
+type GraphEdge struct {
+	To    string      `yaml:"to"`
+	Cost  int         `yaml:"cost"`
+	Steps []GraphStep `yaml:"steps"`
+}
+
+// GraphStep is a single action of a transition.
+type GraphStep struct {
+	Click   string        `yaml:"click"`
+	Swipe   string        `yaml:"swipe"` // name of a preset from FSMGraph.Swipes
+	Wait    time.Duration `yaml:"wait"`
+	Trigger string        `yaml:"trigger"`
+}
+
+// GraphSwipe is a swipe preset in absolute screen coordinates.
+type GraphSwipe struct {
+	X1 int `yaml:"x1"`
+	Y1 int `yaml:"y1"`
+	X2 int `yaml:"x2"`
+	Y2 int `yaml:"y2"`
+}
+
+// LoadFSMGraph reads and validates the FSM graph.
+// If lookup is not nil, every clicked region must exist in it.
+func LoadFSMGraph(path string, lookup *AreaLookup) (*FSMGraph, error) {
+	graph, err := ParseFSMGraph(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := graph.Validate(lookup); err != nil {
+		return nil, fmt.Errorf("fsm graph %s: %w", path, err)
+	}
+
+	return graph, nil
+}
+
+// ParseFSMGraph reads the FSM graph without validating edges and connectivity
+// (e.g. to visualize a broken graph).
+func ParseFSMGraph(path string) (*FSMGraph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fsm graph: %w", err)
+	}
+
+	var graph FSMGraph
+	if err := yaml.Unmarshal(data, &graph); err != nil {
+		return nil, fmt.Errorf("failed to parse fsm graph: %w", err)
+	}
+
+	if err := graph.init(); err != nil {
+		return nil, fmt.Errorf("fsm graph %s: %w", path, err)
+	}
+
+	return &graph, nil
+}
+
+// init builds the screen index and applies defaults.
+func (g *FSMGraph) init() error {
+	g.index = make(map[string]int, len(g.Screens))
+
+	for i := range g.Screens {
+		screen := &g.Screens[i]
+		if screen.Name == "" {
+			return fmt.Errorf("screen #%d has no name", i)
+		}
+		if _, ok := g.index[screen.Name]; ok {
+			return fmt.Errorf("screen '%s' is declared twice", screen.Name)
+		}
+		g.index[screen.Name] = i
+
+		for j := range screen.Edges {
+			if screen.Edges[j].Cost == 0 {
+				screen.Edges[j].Cost = 1
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks edges, regions and connectivity of the graph.
+// Region checks are skipped if lookup is nil.
+func (g *FSMGraph) Validate(lookup *AreaLookup) error {
+	var problems []string
+
+	if _, ok := g.Screen(state.StateMainCity); !ok {
+		return fmt.Errorf("screen '%s' is not declared", state.StateMainCity)
+	}
+
+	for _, screen := range g.Screens {
+		if screen.Identify != nil {
+			for _, h := range screen.Identify.Highlights {
+				if h.Bg == "" && h.Text == "" {
+					problems = append(problems, fmt.Sprintf("%s: highlight '%s' has no color", screen.Name, h.Region))
+				}
+				if lookup == nil {
+					continue
+				}
+				if _, ok := lookup.Get(h.Region); !ok {
+					problems = append(problems, fmt.Sprintf("%s: highlight region '%s' not found", screen.Name, h.Region))
+				}
+			}
+		}
+
+		for _, edge := range screen.Edges {
+			if _, ok := g.Screen(edge.To); !ok {
+				problems = append(problems, fmt.Sprintf("%s → %s: unknown screen", screen.Name, edge.To))
+			}
+			if edge.Cost < 0 {
+				problems = append(problems, fmt.Sprintf("%s → %s: negative cost", screen.Name, edge.To))
+			}
+
+			for _, step := range edge.Steps {
+				switch {
+				case step.Click == "" && step.Swipe == "":
+					problems = append(problems, fmt.Sprintf("%s → %s: missing click and swipe", screen.Name, edge.To))
+				case step.Swipe != "":
+					if _, ok := g.Swipes[step.Swipe]; !ok {
+						problems = append(problems, fmt.Sprintf("%s → %s: unknown swipe preset '%s'", screen.Name, edge.To, step.Swipe))
+					}
+				case lookup != nil:
+					if _, ok := lookup.Get(step.Click); !ok {
+						problems = append(problems, fmt.Sprintf("%s → %s: region '%s' not found", screen.Name, edge.To, step.Click))
+					}
+				}
+			}
+		}
+	}
+
+	reachable := g.reachable(state.StateMainCity, false)
+	wayBack := g.reachable(state.StateMainCity, true)
+
+	for _, screen := range g.Screens {
+		if !screen.External && !reachable[screen.Name] {
+			problems = append(problems, fmt.Sprintf("%s: unreachable from %s", screen.Name, state.StateMainCity))
+		}
+		if !screen.Terminal && !wayBack[screen.Name] {
+			problems = append(problems, fmt.Sprintf("%s: no way back to %s", screen.Name, state.StateMainCity))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New("invalid graph:\n - " + strings.Join(problems, "\n - "))
+}
+
+// ReachableFrom returns the screens reachable from start (start included).
+func (g *FSMGraph) ReachableFrom(start string) map[string]bool {
+	return g.reachable(start, false)
+}
+
+// CanReach returns the screens from which target is reachable (target included).
+func (g *FSMGraph) CanReach(target string) map[string]bool {
+	return g.reachable(target, true)
+}
+
+// reachable returns screens reachable from start (or, if reverse, screens from which start is reachable).
+func (g *FSMGraph) reachable(start string, reverse bool) map[string]bool {
+	adjacency := make(map[string][]string)
+	for _, screen := range g.Screens {
+		for _, edge := range screen.Edges {
+			if reverse {
+				adjacency[edge.To] = append(adjacency[edge.To], screen.Name)
+			} else {
+				adjacency[screen.Name] = append(adjacency[screen.Name], edge.To)
+			}
+		}
+	}
+
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range adjacency[current] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return seen
+}
+
+// Screen returns a screen by name.
+func (g *FSMGraph) Screen(name string) (GraphScreen, bool) {
+	i, ok := g.index[name]
+	if !ok {
+		return GraphScreen{}, false
+	}
+	return g.Screens[i], true
+}
+
+// Edge returns a direct transition between two screens.
+func (g *FSMGraph) Edge(from, to string) (GraphEdge, bool) {
+	screen, ok := g.Screen(from)
+	if !ok {
+		return GraphEdge{}, false
+	}
+
+	for _, edge := range screen.Edges {
+		if edge.To == to {
+			return edge, true
+		}
+	}
+	return GraphEdge{}, false
+}
+
+// Neighbors returns the screens directly reachable from a screen.
+func (g *FSMGraph) Neighbors(from string) []string {
+	screen, ok := g.Screen(from)
+	if !ok {
+		return nil
+	}
+
+	neighbors := make([]string, 0, len(screen.Edges))
+	for _, edge := range screen.Edges {
+		neighbors = append(neighbors, edge.To)
+	}
+	return neighbors
+}
+
+// ScreenNames returns all screen names in declaration order.
+func (g *FSMGraph) ScreenNames() []string {
+	names := make([]string, 0, len(g.Screens))
+	for _, screen := range g.Screens {
+		names = append(names, screen.Name)
+	}
+	return names
+}
+
+// TitleGroups maps a screen title to the screens sharing it, in declaration order.
+func (g *FSMGraph) TitleGroups() map[string][]string {
+	groups := make(map[string][]string)
+	for _, screen := range g.Screens {
+		if screen.Title == "" {
+			continue
+		}
+		groups[screen.Title] = append(groups[screen.Title], screen.Name)
+	}
+	return groups
+}
+
+// Titles returns all screen titles, longest first, so the most specific title is matched first
+// ("Alliance Territory" before "Alliance").
+func (g *FSMGraph) Titles() []string {
+	groups := g.TitleGroups()
+
+	titles := make([]string, 0, len(groups))
+	for title := range groups {
+		titles = append(titles, title)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if len(titles[i]) != len(titles[j]) {
+			return len(titles[i]) > len(titles[j])
+		}
+		return titles[i] < titles[j]
+	})
+
+	return titles
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// FSMGraphLoader keeps the current FSM graph and reloads it when the file changes.
+// An invalid graph is rejected and the previous one stays active.
+type FSMGraphLoader struct {
+	path    string
+	lookup  *AreaLookup
+	graph   atomic.Pointer[FSMGraph]
+	watcher *fsnotify.Watcher
+}
+
+var (
+	defaultGraphOnce   sync.Once
+	defaultGraphLoader *FSMGraphLoader
+	defaultGraphErr    error
+)
+
+// NewFSMGraphLoader loads the graph from path. Regions are validated against lookup (optional).
+func NewFSMGraphLoader(path string, lookup *AreaLookup) (*FSMGraphLoader, error) {
+	loader := &FSMGraphLoader{
+		path:   path,
+		lookup: lookup,
+	}
+
+	if err := loader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return loader, nil
+}
+
+// DefaultFSMGraph returns the process-wide graph loader.
+// The graph is read from PATH_TO_FSM_GRAPH and its regions are checked against PATH_TO_AREA.
+func DefaultFSMGraph() (*FSMGraphLoader, error) {
+	defaultGraphOnce.Do(func() {
+		viper.AutomaticEnv()
+		viper.SetDefault("PATH_TO_FSM_GRAPH", "references/fsmGraph.yaml")
+		viper.SetDefault("PATH_TO_AREA", "references/area.json")
+
+		lookup, err := LoadAreaReferences(viper.GetString("PATH_TO_AREA"))
+		if err != nil {
+			defaultGraphErr = err
+			return
+		}
+
+		defaultGraphLoader, defaultGraphErr = NewFSMGraphLoader(viper.GetString("PATH_TO_FSM_GRAPH"), lookup)
+	})
+
+	return defaultGraphLoader, defaultGraphErr
+}
+
+// Graph returns the current graph snapshot.
+func (l *FSMGraphLoader) Graph() *FSMGraph {
+	return l.graph.Load()
+}
+
+// Reload reads the graph file again. On error the current graph is kept.
+func (l *FSMGraphLoader) Reload() error {
+	graph, err := LoadFSMGraph(l.path, l.lookup)
+	if err != nil {
+		return err
+	}
+
+	l.graph.Store(graph)
+	return nil
+}
+
+// Watch reloads the graph whenever the file changes.
+func (l *FSMGraphLoader) Watch(ctx context.Context) error {
+	if l.watcher != nil {
+		return fmt.Errorf("already watching")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	l.watcher = watcher
+
+	// watch the directory: editors often replace the file instead of writing into it
+	if err := watcher.Add(filepath.Dir(l.path)); err != nil {
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(l.path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+
+				log.Printf("[FSMGraphLoader] Change detected: %s (%s), reloading...", event.Name, event.Op)
+				if err := l.Reload(); err != nil {
+					log.Printf("[FSMGraphLoader] Graph rejected, keeping the previous one: %v", err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("[FSMGraphLoader] FSNotify error: %v", err)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
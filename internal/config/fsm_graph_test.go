@@ -0,0 +1,213 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+)
+
+const validGraph = `
+swipes:
+  right300: { x1: 540, y1: 1200, x2: 240, y2: 1200 }
+screens:
+  - name: main_city
+    title: MainCity
+    edges:
+      - to: mail
+        steps:
+          - { click: to_mail, wait: 300ms }
+  - name: mail
+    title: Mail
+    edges:
+      - to: main_city
+        cost: 3
+        steps:
+          - { swipe: right300, wait: 1s }
+      - to: mail_wars
+  - name: mail_wars
+    title: Mail
+    edges:
+      - to: mail
+`
+
+func writeGraph(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fsmGraph.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadFSMGraph_References(t *testing.T) {
+	lookup, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	graph, err := config.LoadFSMGraph("../../references/fsmGraph.yaml", lookup)
+	require.NoError(t, err)
+	require.Equal(t, "main_city", graph.TitleGroups()["MainCity"][0])
+	require.Equal(t, "world", graph.TitleGroups()["World"][0])
+
+	// a title group can be told apart only if at most one of its screens has no identify signals
+	for title, names := range graph.TitleGroups() {
+		var plain []string
+		for _, name := range names {
+			if screen, _ := graph.Screen(name); screen.Identify == nil {
+				plain = append(plain, name)
+			}
+		}
+		require.LessOrEqual(t, len(plain), 1, "title %q: screens without identify signals %v", title, plain)
+	}
+}
+
+func TestLoadFSMGraph_Valid(t *testing.T) {
+	graph, err := config.LoadFSMGraph(writeGraph(t, validGraph), nil)
+	require.NoError(t, err)
+
+	edge, ok := graph.Edge("mail", "main_city")
+	require.True(t, ok)
+	require.Equal(t, 3, edge.Cost)
+	require.Equal(t, time.Second, edge.Steps[0].Wait)
+	require.Equal(t, "right300", edge.Steps[0].Swipe)
+
+	edge, ok = graph.Edge("main_city", "mail")
+	require.True(t, ok)
+	require.Equal(t, 1, edge.Cost, "default cost")
+
+	require.Equal(t, []string{"mail", "mail_wars"}, graph.TitleGroups()["Mail"])
+	require.Equal(t, []string{"MainCity", "Mail"}, graph.Titles())
+}
+
+func TestLoadFSMGraph_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph string
+		want  string
+	}{
+		{
+			name: "unknown screen",
+			graph: `
+screens:
+  - name: main_city
+    edges:
+      - to: nowhere
+        steps: [{ click: to_nowhere }]
+`,
+			want: "main_city → nowhere: unknown screen",
+		},
+		{
+			name: "unknown swipe preset",
+			graph: `
+screens:
+  - name: main_city
+    edges:
+      - to: mail
+        steps: [{ swipe: left999 }]
+  - name: mail
+    edges:
+      - to: main_city
+`,
+			want: "unknown swipe preset 'left999'",
+		},
+		{
+			name: "unreachable screen",
+			graph: `
+screens:
+  - name: main_city
+  - name: chat
+    edges:
+      - to: main_city
+`,
+			want: "chat: unreachable from main_city",
+		},
+		{
+			name: "no way back",
+			graph: `
+screens:
+  - name: main_city
+    edges:
+      - to: chat
+  - name: chat
+`,
+			want: "chat: no way back to main_city",
+		},
+		{
+			name: "duplicate screen",
+			graph: `
+screens:
+  - name: main_city
+  - name: main_city
+`,
+			want: "declared twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadFSMGraph(writeGraph(t, tt.graph), nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLoadFSMGraph_MissingRegion(t *testing.T) {
+	lookup, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	_, err = config.LoadFSMGraph(writeGraph(t, `
+screens:
+  - name: main_city
+    edges:
+      - to: mail
+        steps: [{ click: no_such_region }]
+  - name: mail
+    edges:
+      - to: main_city
+`), lookup)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "region 'no_such_region' not found")
+}
+
+func TestFSMGraphLoader_RejectsInvalidReload(t *testing.T) {
+	path := writeGraph(t, validGraph)
+
+	loader, err := config.NewFSMGraphLoader(path, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("screens: [{ name: chat }]"), 0644))
+	require.Error(t, loader.Reload())
+
+	// the previous graph stays active
+	_, ok := loader.Graph().Screen("mail_wars")
+	require.True(t, ok)
+}
+
+func TestFSMGraphLoader_Watch(t *testing.T) {
+	path := writeGraph(t, validGraph)
+
+	loader, err := config.NewFSMGraphLoader(path, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, loader.Watch(ctx))
+
+	updated := validGraph + `
+  - name: mail_system
+    title: Mail
+    external: true
+    edges:
+      - to: mail
+`
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0644))
+
+	require.Eventually(t, func() bool {
+		_, ok := loader.Graph().Screen("mail_system")
+		return ok
+	}, 2*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/buildings"
+)
+
+// CanAffordLibIn registers canAfford for a gamer, backed by the building tables:
+//
+//	canAfford("cookhouse", 5) — the resources pay for the upgrade to level 5
+//	canAfford("furnace")      — the resources pay for the next level
+func CanAffordLibIn(tables buildings.Tables, gamer *domain.Gamer) cel.EnvOption {
+	canAfford := func(building string, level int) ref.Val {
+		next, ok := tables[buildings.Name(building)][level]
+		return types.Bool(ok && gamer.Resources.Covers(next.Cost))
+	}
+
+	return cel.Function("canAfford",
+		cel.Overload("canAfford_string_int_bool",
+			[]*cel.Type{cel.StringType, cel.IntType},
+			cel.BoolType,
+			cel.BinaryBinding(func(name, level ref.Val) ref.Val {
+				b, ok1 := name.Value().(string)
+				l, ok2 := level.Value().(int64)
+				if !ok1 || !ok2 {
+					return types.Bool(false)
+				}
+				return canAfford(b, int(l))
+			}),
+		),
+		cel.Overload("canAfford_string_bool",
+			[]*cel.Type{cel.StringType},
+			cel.BoolType,
+			cel.UnaryBinding(func(name ref.Val) ref.Val {
+				b, ok := name.Value().(string)
+				if !ok {
+					return types.Bool(false)
+				}
+				return canAfford(b, gamer.Buildings.Levels()[buildings.Name(b)]+1)
+			}),
+		),
+	)
+}
+
+// HasResourcesLibIn registers hasResources for a gamer:
+//
+//	hasResources({"wood": 50000, "iron": 5000}) — the resources reach the amounts
+func HasResourcesLibIn(gamer *domain.Gamer) cel.EnvOption {
+	return cel.Function("hasResources",
+		cel.Overload("hasResources_map_bool",
+			[]*cel.Type{cel.MapType(cel.StringType, cel.IntType)},
+			cel.BoolType,
+			cel.UnaryBinding(func(amounts ref.Val) ref.Val {
+				native, err := amounts.ConvertToNative(reflect.TypeOf(map[string]int64{}))
+				if err != nil {
+					return types.Bool(false)
+				}
+				cost, ok := resourcesOf(native.(map[string]int64))
+				return types.Bool(ok && gamer.Resources.Covers(cost))
+			}),
+		),
+	)
+}
+
+// resourcesOf converts resource amounts by name; false for an unknown resource.
+func resourcesOf(amounts map[string]int64) (domain.Resources, bool) {
+	var r domain.Resources
+	for name, n := range amounts {
+		switch strings.ToLower(name) {
+		case "wood":
+			r.Wood = int(n)
+		case "food":
+			r.Food = int(n)
+		case "iron":
+			r.Iron = int(n)
+		case "meat":
+			r.Meat = int(n)
+		case "coal":
+			r.Coal = int(n)
+		default:
+			return r, false
+		}
+	}
+	return r, true
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/buildings"
+)
+
+func TestResourceFunctions(t *testing.T) {
+	tables, err := buildings.LoadTables("../../references/tables/buildings")
+	require.NoError(t, err)
+
+	gamer := &domain.Gamer{Resources: domain.Resources{Wood: 2000, Iron: 400, Meat: 50}}
+	gamer.Buildings.Cookhouse.Level = 3
+
+	env, err := cel.NewEnv(CanAffordLibIn(tables, gamer), HasResourcesLibIn(gamer))
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`canAfford("cookhouse", 4)`, true},
+		{`canAfford("Cookhouse", 5)`, false}, // 70 meat
+		{`canAfford("cookhouse")`, true},     // level 4
+		{`canAfford("cookhouse", 99)`, false},
+		{`canAfford("sawmill", 1)`, false},
+		{`hasResources({"wood": 2000, "iron": 100})`, true},
+		{`hasResources({"meat": 51})`, false},
+		{`hasResources({"gold": 1})`, false},
+	}
+
+	for _, tc := range tests {
+		ast, issues := env.Compile(tc.expr)
+		require.NoError(t, issues.Err(), tc.expr)
+		prg, err := env.Program(ast)
+		require.NoError(t, err)
+		out, _, err := prg.Eval(map[string]any{})
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.want, out.Value(), tc.expr)
+	}
+}
@@ -42,40 +42,55 @@ func FuzzySubstringMatch(haystack, needle string, maxDist int) bool {
 // compareText(a, b) → bool  (registration in CEL)
 // -----------------------------------------------------------------------------
 
-// compareTextBinding — actual implementation of the function for CEL.
-func compareTextBinding(lhs, rhs ref.Val) ref.Val {
-	a, ok1 := lhs.Value().(string)
-	b, ok2 := rhs.Value().(string)
-	if !ok1 || !ok2 {
+// compareTextIn — actual implementation of the function for CEL.
+// Texts are compared in the game language: an English text also matches its translations.
+func compareTextIn(catalog *Catalog, lang string) func(lhs, rhs ref.Val) ref.Val {
+	return func(lhs, rhs ref.Val) ref.Val {
+		a, ok1 := lhs.Value().(string)
+		b, ok2 := rhs.Value().(string)
+		if !ok1 || !ok2 {
+			return types.Bool(false)
+		}
+
+		for _, av := range catalog.Variants(lang, a) {
+			for _, bv := range catalog.Variants(lang, b) {
+				if compareText(av, bv) {
+					return types.Bool(true)
+				}
+			}
+		}
 		return types.Bool(false)
 	}
+}
 
+func compareText(a, b string) bool {
 	al := strings.ToLower(a)
 	bl := strings.ToLower(b)
 
 	if bl == "" || al == "" {
-		return types.Bool(false)
+		return false
 	}
 
 	if strings.Contains(bl, al) {
-		return types.Bool(true)
+		return true
 	}
 
 	// Only for substrings of length >= 4 characters apply fuzzy matching
-	if len(al) >= 4 && FuzzySubstringMatch(bl, al, 1) {
-		return types.Bool(true)
-	}
-
-	return types.Bool(false)
+	return len(al) >= 4 && FuzzySubstringMatch(bl, al, 1)
 }
 
-// CompareTextLib — EnvOption that registers the compareText function.
-var CompareTextLib = cel.Function(
-	"compareText",
-	cel.Overload(
-		"compareText_string_string_bool",
-		[]*cel.Type{cel.StringType, cel.StringType},
-		cel.BoolType,
-		cel.BinaryBinding(compareTextBinding),
-	),
-)
+// CompareTextLib — EnvOption that registers the compareText function for English texts.
+var CompareTextLib = CompareTextLibIn(nil, LanguageEnglish)
+
+// CompareTextLibIn registers compareText for a game language.
+func CompareTextLibIn(catalog *Catalog, lang string) cel.EnvOption {
+	return cel.Function(
+		"compareText",
+		cel.Overload(
+			"compareText_string_string_bool",
+			[]*cel.Type{cel.StringType, cel.StringType},
+			cel.BoolType,
+			cel.BinaryBinding(compareTextIn(catalog, lang)),
+		),
+	)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/heroes"
+)
+
+var (
+	heroCatalogOnce sync.Once
+	heroCatalog     map[string]heroes.Hero
+)
+
+// DefaultHeroCatalog returns the process-wide hero catalog of PATH_TO_HEROES; empty without the file.
+func DefaultHeroCatalog() map[string]heroes.Hero {
+	heroCatalogOnce.Do(func() {
+		viper.SetDefault("PATH_TO_HEROES", "references/heroes.yaml")
+
+		catalog, err := heroes.LoadCatalog(viper.GetString("PATH_TO_HEROES"))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("[DefaultHeroCatalog] %v", err)
+			}
+			catalog = map[string]heroes.Hero{}
+		}
+		heroCatalog = catalog
+	})
+
+	return heroCatalog
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// LanguageEnglish is the language usecases, the FSM graph and analyze rules are written in.
+const LanguageEnglish = "en"
+
+// Catalog translates the English texts of the game UI (screen titles, buttons, pop-up keywords)
+// to the game languages of the accounts.
+type Catalog struct {
+	texts map[string]map[string][]string // language → lowercased English text → translations
+}
+
+var (
+	defaultCatalogOnce sync.Once
+	defaultCatalog     *Catalog
+)
+
+// LoadCatalog reads the catalog: for every language a map of the English text to its translation
+// (or a list of them when the game uses several).
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translations: %w", err)
+	}
+
+	var raw map[string]map[string]translations
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse translations: %w", err)
+	}
+
+	c := &Catalog{texts: make(map[string]map[string][]string, len(raw))}
+	for lang, texts := range raw {
+		lang = strings.ToLower(lang)
+		c.texts[lang] = make(map[string][]string, len(texts))
+		for en, tr := range texts {
+			c.texts[lang][strings.ToLower(en)] = tr
+		}
+	}
+	return c, nil
+}
+
+// DefaultCatalog returns the process-wide catalog of PATH_TO_I18N; without the file texts stay English.
+func DefaultCatalog() *Catalog {
+	defaultCatalogOnce.Do(func() {
+		viper.SetDefault("PATH_TO_I18N", "references/i18n.yaml")
+
+		c, err := LoadCatalog(viper.GetString("PATH_TO_I18N"))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("[DefaultCatalog] %v", err)
+			}
+			c = &Catalog{}
+		}
+		defaultCatalog = c
+	})
+
+	return defaultCatalog
+}
+
+// Variants returns the English text followed by its translations to lang:
+// the game keeps some texts in English, so both are accepted.
+func (c *Catalog) Variants(lang, text string) []string {
+	variants := []string{text}
+	if c == nil || lang == "" || strings.EqualFold(lang, LanguageEnglish) {
+		return variants
+	}
+	return append(variants, c.texts[strings.ToLower(lang)][strings.ToLower(text)]...)
+}
+
+// translations is a single string or a list in the catalog file.
+type translations []string
+
+func (t *translations) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = translations{node.Value}
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
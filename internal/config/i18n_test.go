@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	catalog, err := LoadCatalog("../../references/i18n.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Mail", "Почта"}, catalog.Variants("ru", "Mail"))
+	assert.Equal(t, []string{"backpack", "Рюкзак", "Сумка"}, catalog.Variants("RU", "backpack"), "a list of translations")
+	assert.Equal(t, []string{"Mail"}, catalog.Variants("en", "Mail"))
+	assert.Equal(t, []string{"Mail"}, catalog.Variants("", "Mail"))
+	assert.Equal(t, []string{"Unknown text"}, catalog.Variants("ru", "Unknown text"))
+
+	var missing *Catalog
+	assert.Equal(t, []string{"Mail"}, missing.Variants("ru", "Mail"))
+}
+
+func TestCompareTextLibIn(t *testing.T) {
+	catalog, err := LoadCatalog("../../references/i18n.yaml")
+	require.NoError(t, err)
+
+	eval := func(lang, expr string) bool {
+		env, err := cel.NewEnv(cel.Variable("text", cel.StringType), CompareTextLibIn(catalog, lang))
+		require.NoError(t, err)
+		ast, issues := env.Compile(expr)
+		require.NoError(t, issues.Err())
+		prg, err := env.Program(ast)
+		require.NoError(t, err)
+		out, _, err := prg.Eval(map[string]any{"text": "Улучшить"})
+		require.NoError(t, err)
+		return out.Value().(bool)
+	}
+
+	assert.True(t, eval("ru", `compareText(text, "Upgrade")`))
+	assert.False(t, eval("en", `compareText(text, "Upgrade")`))
+	assert.False(t, eval("ru", `compareText(text, "Idle")`))
+	assert.True(t, eval("ru", `compareText("Idle", "Idle")`), "English texts still match")
+}
@@ -1,63 +1,23 @@
 package config
 
 import (
-	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
+	"log"
 )
 
-var (
-	// TitleToState is used to determine the state based on the screen title.
-	TitleToState = map[string][]string{
-		"Alliance":      {state.StateAllianceManage},
-		"Chief Profile": {state.StateChiefProfile},
-		"Tech":          {state.StateAllianceTech},
-		"Chests":        {state.StateAllianceChests, state.StateAllianceChestGift, state.StateAllianceChestLoot},
-		"Exploration":   {state.StateExploration},
-		"Mail":          {state.StateMail, state.StateMailWars, state.StateMailAlliance, state.StateMailSystem, state.StateMailReports, state.StateMailStarred},
-		"Backpack":      {state.StateBackpack, state.StateBackpackResources, state.StateBackpackSpeedups, state.StateBackpackBonus, state.StateBackpackGear, state.StateBackpackOther},
-		"Chat":          {state.StateChat, state.StateChatAlliance, state.StateChatWorld, state.StateChatPersonal},
-		"Heroes":        {state.StateHeroes},
-		"Events":        {state.StateEvents},
-		"Deals":         {state.StateDeals},
-		"War":           {state.StateAllianceWar, state.StateAllianceWarRally, state.StateAllianceWarRallyAutoJoin, state.StateAllianceWarSolo, state.StateAllianceWarEvents},
-		"VIP":           {state.StateVIP},
-		"Settings":      {state.StateChiefProfileSetting},
-		"Account":       {state.StateChiefProfileAccount},
-		"MainCity": {
-			state.StateMainCity,
-			state.StateMainMenuCity,
-			state.StateMainMenuWilderness,
-			state.StateMainMenuBuilding1,
-			state.StateMainMenuBuilding2,
-			state.StateMainMenuTechResearch,
-
-			state.StateInfantryCityView,
-			state.StateLancerCityView,
-			state.StateMarksmanCityView,
-
-			state.StateArenaCityView,
-		},
-		"World": {
-			state.StateWorld,
-			state.StateWorldSearch,
-			state.StateWorldGlobalMap,
-			state.StateHealInjured,
-		},
-		"Squad Settings":         {state.StateExplorationBattle},
-		"Top-up Center":          {state.StateTopUpCenter},
-		"Intel":                  {state.StateIntel},
-		"Arena of Glory":         {state.StateArenaMain},
-		"Defensive Squad Lineup": {state.StateArenaDefensiveSquadLineup},
-		"Alliance Territory":     {state.StateAllianceTerritory},
-		"Activity Triumph":       {state.StateActivityTriumph},
-		"The Labyrinth":          {state.StateLabyrinth},
-		"Cave of Monsters":       {state.StateCaveOfMonsters},
-		"Natalia":                {state.StateNatalia},
-		"Enlistment Office":      {state.StateEnlistmentOffice},
+// TitleToState is used to determine the state based on the screen title.
+// Groups come from the current FSM graph (see FSMGraph.TitleGroups).
+func TitleToState() map[string][]string {
+	loader, err := DefaultFSMGraph()
+	if err != nil {
+		log.Printf("[TitleToState] FSM graph is not available: %v", err)
+		return nil
 	}
-)
+
+	return loader.Graph().TitleGroups()
+}
 
 func SameScreenGroup(current, other string) bool {
-	for _, states := range TitleToState {
+	for _, states := range TitleToState() {
 		foundCurrent := false
 		foundOther := false
 		for _, st := range states {
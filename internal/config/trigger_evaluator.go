@@ -53,9 +53,11 @@ func (t *triggerEvaluator) EvaluateTrigger(expr string, char *domain.Gamer) (boo
 	env, err := cel.NewEnv(
 		cel.Declarations(declsList...),
 		ext.Strings(), // adds string.lowerAscii()
-		CompareTextLib,
+		CompareTextLibIn(DefaultCatalog(), char.Language),
 		IsMaxLib,
 		IsMinLib,
+		CanAffordLibIn(DefaultBuildingTables(), char),
+		HasResourcesLibIn(char),
 	)
 	if err != nil {
 		return false, fmt.Errorf("creating CEL env: %w", err)
@@ -29,3 +29,60 @@ func TestCompareTextCEL(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildingQueueTrigger(t *testing.T) {
+	eval := NewTriggerEvaluator()
+	trigger := `compareText(buildings.state.text, "Upgrade") && (!buildings.next.isPlanned || buildings.next.queue1 != "")`
+
+	tests := []struct {
+		name string
+		next domain.BuildingPlan
+		want bool
+	}{
+		{"no plan", domain.BuildingPlan{}, true},
+		{"planned", domain.BuildingPlan{IsPlanned: true, Queue1: "furnace"}, true},
+		{"nothing affordable", domain.BuildingPlan{IsPlanned: true}, false},
+	}
+
+	for _, tc := range tests {
+		gamer := &domain.Gamer{}
+		gamer.Buildings.State.Text = "Upgrade"
+		gamer.Buildings.Next = tc.next
+
+		got, err := eval.EvaluateTrigger(trigger, gamer)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTroopTrainResourceTrigger(t *testing.T) {
+	eval := NewTriggerEvaluator()
+	trigger := `compareText(troops.infantry.state.TextStatus, "Idle") && (resources.wood + resources.meat == 0 || hasResources({"meat": 100000, "wood": 100000}))`
+
+	tests := []struct {
+		name      string
+		resources domain.Resources
+		want      bool
+	}{
+		{"not read yet", domain.Resources{}, true},
+		{"enough", domain.Resources{Meat: 20_950_000, Wood: 19_770_000}, true},
+		{"short of wood", domain.Resources{Meat: 20_950_000, Wood: 99_000}, false},
+	}
+
+	for _, tc := range tests {
+		gamer := &domain.Gamer{Resources: tc.resources}
+		gamer.Troops.Infantry.State.TextStatus = "Idle"
+
+		got, err := eval.EvaluateTrigger(trigger, gamer)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
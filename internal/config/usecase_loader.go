@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -17,6 +18,7 @@ import (
 // UseCaseLoader knows how to scan a directory and load all YAML usecases with hot-reload support.
 type UseCaseLoader interface {
 	LoadAll(ctx context.Context) ([]*domain.UseCase, error)
+	All() []*domain.UseCase
 	GetByName(name string) *domain.UseCase
 	Reload(ctx context.Context) error
 	Watch(ctx context.Context) error
@@ -154,9 +156,37 @@ func LoadUseCase(ctx context.Context, configFile string) (*domain.UseCase, error
 	// save the file name in the structure
 	uc.SourcePath = configFile
 
+	if err := validateSteps(uc.Steps); err != nil {
+		return nil, fmt.Errorf("invalid usecase %s: %w", configFile, err)
+	}
+
 	return &uc, nil
 }
 
+// validateSteps checks the analyze rules of the steps, nested loops and if branches included.
+func validateSteps(steps []domain.Step) error {
+	for _, step := range steps {
+		for _, rule := range step.Analyze {
+			if err := ValidateAnalyzeRule(rule); err != nil {
+				return err
+			}
+		}
+
+		if err := validateSteps(step.Steps); err != nil {
+			return err
+		}
+		if step.If != nil {
+			if err := validateSteps(step.If.Then); err != nil {
+				return err
+			}
+			if err := validateSteps(step.If.Else); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (l *usecaseLoader) LoadAll(ctx context.Context) ([]*domain.UseCase, error) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -171,6 +201,20 @@ func (l *usecaseLoader) LoadAll(ctx context.Context) ([]*domain.UseCase, error)
 	return active, nil
 }
 
+// All returns every indexed usecase (including ones without cron), sorted by name.
+func (l *usecaseLoader) All() []*domain.UseCase {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	all := make([]*domain.UseCase, 0, len(l.indexed))
+	for _, uc := range l.indexed {
+		all = append(all, uc)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	return all
+}
+
 func (l *usecaseLoader) GetByName(name string) *domain.UseCase {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
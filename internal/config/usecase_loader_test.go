@@ -72,3 +72,35 @@ steps: []
 	// SourcePath should be saved
 	require.Contains(t, fromDebug.SourcePath, "debug/only_debug.yaml")
 }
+
+func TestLoadUseCase_ValidatesRules(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeUseCase(t, tmpDir, "broken.yaml", `
+name: Broken
+node: main_city
+steps:
+  - if:
+      trigger: "true"
+      then:
+        - analyze:
+            - name: arena.state.opponents
+              action: text
+              type: table
+`)
+
+	_, err := config.LoadUseCase(context.Background(), filepath.Join(tmpDir, "broken.yaml"))
+	require.ErrorContains(t, err, "table.columns")
+	require.Nil(t, config.NewUseCaseLoader(tmpDir).GetByName("Broken"))
+}
+
+func TestLoadUseCase_References(t *testing.T) {
+	err := filepath.Walk("../../usecases", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		_, err = config.LoadUseCase(context.Background(), path)
+		return err
+	})
+	require.NoError(t, err)
+}
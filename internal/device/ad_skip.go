@@ -3,9 +3,11 @@ package device
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/vision"
 )
 
@@ -19,10 +21,25 @@ func (d *Device) handleEntryScreens(ctx context.Context) error {
 		"Brothers in Arms", "Event Coming Soon", "Dawn Pack",
 		"Unyielding Dawn", "Overview", "Confirm",
 	}
-	// Convert all keys to lowercase
-	lowerKW := make([]string, len(keywords))
-	for i, kw := range keywords {
-		lowerKW[i] = strings.ToLower(kw)
+	language := ""
+	if gamer := d.ActiveGamer(); gamer != nil {
+		language = gamer.Language
+	}
+	catalog := config.DefaultCatalog()
+
+	// Convert all keys to lowercase, adding their translations to the game language
+	var lowerKW []string
+	for _, kw := range keywords {
+		for _, text := range catalog.Variants(language, kw) {
+			lowerKW = append(lowerKW, strings.ToLower(text))
+		}
+	}
+	var confirm []string
+	for _, text := range catalog.Variants(language, "Confirm") {
+		confirm = append(confirm, strings.ToLower(text))
+	}
+	isConfirm := func(txt string) bool {
+		return slices.ContainsFunc(confirm, func(c string) bool { return vision.FuzzySubstringMatch(txt, c, 1) })
 	}
 
 	start := time.Now()
@@ -39,7 +56,7 @@ func (d *Device) handleEntryScreens(ctx context.Context) error {
 		default:
 		}
 
-		zones, err := d.OCRClient.WaitForText(lowerKW, 10*time.Second, 1*time.Second, "entry_check")
+		zones, err := d.OCRClient.WaitForText(ctx, lowerKW, 10*time.Second, 1*time.Second, "entry_check")
 		if err != nil {
 			d.Logger.Error("❌ OCRClient error", slog.Any("err", err))
 			return err
@@ -51,10 +68,9 @@ func (d *Device) handleEntryScreens(ctx context.Context) error {
 		// 1) Look for Confirm
 		for _, z := range zones {
 			txt := strings.ToLower(strings.TrimSpace(z.Text))
-			if vision.FuzzySubstringMatch(txt, "confirm", 1) &&
-				z.AvgColor == "white" && z.BgColor == "green" {
+			if isConfirm(txt) && z.AvgColor == "white" && z.BgColor == "green" {
 				d.Logger.Info("🟢 Clicking Confirm", slog.String("text", txt))
-				if err := d.ADB.ClickRegion("welcome_back_continue_button", d.AreaLookup); err != nil {
+				if err := d.ADB.ClickRegion(ctx, "welcome_back_continue_button", d.AreaLookup); err != nil {
 					d.Logger.Error("❌ Error clicking Confirm", slog.Any("err", err))
 					return err
 				}
@@ -68,12 +84,12 @@ func (d *Device) handleEntryScreens(ctx context.Context) error {
 		for _, z := range zones {
 			txt := strings.ToLower(strings.TrimSpace(z.Text))
 			for _, target := range lowerKW {
-				if target == "confirm" {
+				if slices.Contains(confirm, target) {
 					continue
 				}
 				if vision.FuzzySubstringMatch(txt, target, 1) {
 					d.Logger.Info("🌀 Closing pop-up", slog.String("popup", txt))
-					if err := d.ADB.ClickRegion("ad_banner_close", d.AreaLookup); err != nil {
+					if err := d.ADB.ClickRegion(ctx, "ad_banner_close", d.AreaLookup); err != nil {
 						d.Logger.Error("❌ Error clicking pop-up close", slog.Any("err", err))
 						return err
 					}
@@ -19,11 +19,11 @@ func (d *Device) DetectedGamer(ctx context.Context) (int, int, error) {
 	d.Logger.Info("🚀 Detecting current player")
 
 	// 0. Navigate to profile screen
-	d.FSM.ForceTo(state.StateChiefProfile, nil)
+	d.FSM.ForceTo(ctx, state.StateChiefProfile, nil)
 
 	defer func() {
 		// 4. Return to main screen
-		d.FSM.ForceTo(state.StateMainCity, nil)
+		d.FSM.ForceTo(ctx, state.StateMainCity, nil)
 	}()
 
 	zone, ok := d.AreaLookup.Get("chief_profile_nickname")
@@ -42,7 +42,7 @@ func (d *Device) DetectedGamer(ctx context.Context) (int, int, error) {
 	}
 
 	// 3. Recognize player nickname
-	fullOCR, fullErr := d.OCRClient.FetchOCR("", []ocrclient.Region{region}) // debugName can be omitted
+	fullOCR, fullErr := d.OCRClient.FetchOCR(ctx, "", []ocrclient.Region{region}) // debugName can be omitted
 	if fullErr != nil {
 		d.Logger.Error("Full OCR failed", slog.Any("error", fullErr))
 		return -1, -1, fmt.Errorf("full OCR failed: %w", fullErr)
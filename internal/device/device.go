@@ -1,6 +1,7 @@
 package device
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/redis/go-redis/v9"
@@ -10,6 +11,7 @@ import (
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
 	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
 )
 
 type Device struct {
@@ -22,6 +24,8 @@ type Device struct {
 	rdb              *redis.Client
 	triggerEvaluator config.TriggerEvaluator
 	OCRClient        *ocrclient.Client
+	screenRepo       repository.ScreenRepository
+	edgeStatsRepo    repository.EdgeStatsRepository
 
 	activeProfileIdx int
 	activeGamerIdx   int
@@ -42,6 +46,17 @@ func New(deviceId string, profiles domain.Profiles, log *slog.Logger, areaPath s
 		log.Error("❌ Error loading area.json:", "error", err)
 		return nil, err
 	}
+	if err := areaLookup.LoadOverrides(config.AreaOverridePath(deviceId)); err != nil {
+		log.Error("❌ Error loading area overrides", slog.Any("error", err))
+		return nil, err
+	}
+
+	scale, err := screenScale(context.Background(), controller, log)
+	if err != nil {
+		log.Error("❌ Unsupported screen", slog.Any("error", err))
+		return nil, err
+	}
+	areaLookup.SetScale(scale)
 
 	device := &Device{
 		Name:             deviceId,
@@ -52,10 +67,56 @@ func New(deviceId string, profiles domain.Profiles, log *slog.Logger, areaPath s
 		rdb:              rdb,
 		triggerEvaluator: triggerEvaluator,
 		OCRClient:        ocrclient.NewClient(deviceId, log),
+		screenRepo:       repository.NewRedisScreenRepository(rdb),
+		edgeStatsRepo:    repository.NewRedisEdgeStatsRepository(rdb),
 	}
 
-	// Initialize FSM
-	device.FSM = fsm.NewGame(log, controller, areaLookup, triggerEvaluator, device.ActiveGamer(), device.OCRClient)
+	// Initialize FSM and resume from the screen the game is actually on
+	device.FSM = device.newFSM()
+	device.FSM.Resume(context.Background())
 
 	return device, nil
 }
+
+// newFSM creates a new FSM for the active gamer which persists confirmed screens of the device
+// and learns transition costs.
+func (d *Device) newFSM() *fsm.GameFSM {
+	// regions found on the screens of the previous account are stale
+	d.AreaLookup.ClearTemporary()
+
+	game := fsm.NewGame(d.Logger, d.ADB, d.AreaLookup, d.triggerEvaluator, d.ActiveGamer(), d.OCRClient)
+	game.SetScreenRepository(d.Name, d.screenRepo)
+	game.SetEdgeStatsRepository(context.Background(), d.edgeStatsRepo)
+
+	return game
+}
+
+// screenScale measures the device screen and checks that regions authored for the reference resolution
+// can be scaled to it.
+func screenScale(ctx context.Context, controller *adb.Controller, log *slog.Logger) (domain.ScreenScale, error) {
+	width, height, err := controller.GetScreenResolution(ctx)
+	if err != nil {
+		return domain.ScreenScale{}, err
+	}
+
+	scale := domain.NewScreenScale(width, height)
+	if err := scale.Check(); err != nil {
+		return scale, err
+	}
+
+	if !scale.IsIdentity() {
+		log.Info("📐 Scaling regions to the device screen",
+			slog.Int("width", width),
+			slog.Int("height", height),
+			slog.Float64("factor", scale.Factor),
+		)
+	}
+	if scale.Letterboxed() {
+		log.Warn("⚠️ Aspect ratio differs from the reference, regions are letterboxed",
+			slog.Float64("offset_x", scale.OffsetX),
+			slog.Float64("offset_y", scale.OffsetY),
+		)
+	}
+
+	return scale, nil
+}
@@ -12,7 +12,6 @@ import (
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
-	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
 )
 
 func (d *Device) NextGamer(profileIdx, gamerIdx int) {
@@ -45,13 +44,13 @@ func (d *Device) NextGamer(profileIdx, gamerIdx int) {
 	d.Logger.Info("➡️ Navigating to player selection screen",
 		slog.String("trace_id", traceID),
 	)
-	d.FSM.ForceTo(state.StateChiefCharacters, nil)
+	d.FSM.ForceTo(ctx, state.StateChiefCharacters, nil)
 
 	// 🕒 Wait to avoid conflicts with other processes
 	time.Sleep(2 * time.Second)
 
 	// ========== 1️⃣ Perform unified full-screen OCR ==========
-	fullOCR, fullErr := d.OCRClient.FetchOCR("", nil) // debugName can be omitted
+	fullOCR, fullErr := d.OCRClient.FetchOCR(ctx, "", nil) // debugName can be omitted
 	if fullErr != nil {
 		d.Logger.Error("❌ Full OCR failed", slog.Any("error", fullErr))
 		panic(fmt.Sprintf("ocrClient.FetchOCR() failed: %v", fullErr))
@@ -70,7 +69,7 @@ func (d *Device) NextGamer(profileIdx, gamerIdx int) {
 		slog.String("text", gamerZone.Text),
 		slog.String("trace_id", traceID),
 	)
-	if err := d.ADB.ClickOCRResult(gamerZone); err != nil {
+	if err := d.ADB.ClickOCRResult(ctx, gamerZone); err != nil {
 		d.Logger.Error("❌ Failed to click nickname account",
 			slog.Any("err", err),
 			slog.String("trace_id", traceID),
@@ -84,7 +83,7 @@ func (d *Device) NextGamer(profileIdx, gamerIdx int) {
 		slog.String("region", "character_change_confirm"),
 		slog.String("trace_id", traceID),
 	)
-	if err := d.ADB.ClickRegion("character_change_confirm", d.AreaLookup); err != nil {
+	if err := d.ADB.ClickRegion(ctx, "character_change_confirm", d.AreaLookup); err != nil {
 		d.Logger.Error("❌ Failed to click character_change_confirm",
 			slog.Any("err", err),
 			slog.String("trace_id", traceID),
@@ -108,5 +107,5 @@ func (d *Device) NextGamer(profileIdx, gamerIdx int) {
 	d.Logger.Info("🔧 Initializing FSM",
 		slog.String("trace_id", traceID),
 	)
-	d.FSM = fsm.NewGame(d.Logger, d.ADB, d.AreaLookup, d.triggerEvaluator, d.ActiveGamer(), d.OCRClient)
+	d.FSM = d.newFSM()
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
-	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
 	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
 )
 
@@ -28,7 +27,7 @@ func (d *Device) NextProfile(profileIdx, expectedGamerIdx int) {
 
 	// 🔁 Navigation: go to Google account selection screen
 	d.Logger.Info("➡️ Navigating to account selection screen")
-	d.FSM.ForceTo(state.StateChiefProfileAccountChangeGoogle, nil)
+	d.FSM.ForceTo(ctx, state.StateChiefProfileAccountChangeGoogle, nil)
 
 	// 🕒 Wait to avoid conflicts with other processes
 	time.Sleep(2 * time.Second)
@@ -40,7 +39,7 @@ func (d *Device) NextProfile(profileIdx, expectedGamerIdx int) {
 		panic("AreaLookup(google_profile) failed")
 	}
 
-	fullOCR, fullErr := d.OCRClient.FetchOCR("google_profile", []ocrclient.Region{
+	fullOCR, fullErr := d.OCRClient.FetchOCR(ctx, "google_profile", []ocrclient.Region{
 		{
 			X0: region.Zone.Min.X,
 			Y0: region.Zone.Min.Y,
@@ -63,7 +62,7 @@ func (d *Device) NextProfile(profileIdx, expectedGamerIdx int) {
 	}
 
 	d.Logger.Info("🟢 Clicking email account", slog.String("text", emailZone.Text), slog.String("region", emailZone.String()))
-	if err := d.ADB.ClickOCRResult(emailZone); err != nil {
+	if err := d.ADB.ClickOCRResult(ctx, emailZone); err != nil {
 		d.Logger.Error("❌ Failed to click email account", slog.Any("err", err))
 		panic(fmt.Sprintf("ClickRegion(email:gamer1) failed: %v", err))
 	}
@@ -77,14 +76,14 @@ func (d *Device) NextProfile(profileIdx, expectedGamerIdx int) {
 	}
 
 	// Wait for "Continue" text via OCR client
-	if _, err := d.OCRClient.WaitForText([]string{"Continue"}, time.Second, 500*time.Millisecond, "continue"); err != nil {
+	if _, err := d.OCRClient.WaitForText(ctx, []string{"Continue"}, time.Second, 500*time.Millisecond, "continue"); err != nil {
 		d.Logger.Error("❌ OCRClient WaitForText failed for Continue", slog.Any("err", err))
 		panic(fmt.Sprintf("OCRClient.WaitForText(Continue) failed: %v", err))
 	}
 
 	d.Logger.Info("🟢 Clicking Google continue button", slog.String("region", "to_google_continue"))
 
-	if err := d.ADB.Click(googleContinueArea.Zone); err != nil {
+	if err := d.ADB.Click(ctx, googleContinueArea.Zone); err != nil {
 		d.Logger.Error("❌ Failed to click to_google_continue", slog.Any("err", err))
 		panic(fmt.Sprintf("ClickRegion(to_google_continue) failed: %v", err))
 	}
@@ -92,7 +91,7 @@ func (d *Device) NextProfile(profileIdx, expectedGamerIdx int) {
 	// ♻️ Reset FSM after login
 	d.activeProfileIdx = profileIdx
 	d.activeGamerIdx = expectedGamerIdx
-	d.FSM = fsm.NewGame(d.Logger, d.ADB, d.AreaLookup, d.triggerEvaluator, d.ActiveGamer(), d.OCRClient)
+	d.FSM = d.newFSM()
 
 	// Check entry banners
 	err := d.handleEntryScreens(ctx)
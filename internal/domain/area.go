@@ -11,6 +11,16 @@ type AreaReference struct {
 	Transcription []string `json:"transcription"`
 }
 
+// Has reports whether the reference labels a region with the name.
+func (r AreaReference) Has(name string) bool {
+	for _, label := range r.Transcription {
+		if label == name {
+			return true
+		}
+	}
+	return false
+}
+
 type BBox struct {
 	X              float64 `json:"x"`
 	Y              float64 `json:"y"`
@@ -12,9 +12,11 @@ type ArenaState struct {
 	IsAvailableFight    bool `yaml:"isAvailableFight"`    // Flag for fight availability.
 	CountAvailableFight int  `yaml:"countAvailableFight"` // Number of available fights.
 
-	EnemyPower1 int `yaml:"enemyPower1"` // First opponent's power.
-	EnemyPower2 int `yaml:"enemyPower2"` // Second opponent's power.
-	EnemyPower3 int `yaml:"enemyPower3"` // Third opponent's power.
-	EnemyPower4 int `yaml:"enemyPower4"` // Fourth opponent's power.
-	EnemyPower5 int `yaml:"enemyPower5"` // Fifth opponent's power.
+	Opponents []ArenaOpponent `yaml:"opponents"` // Challenge list, top to bottom.
+}
+
+// ArenaOpponent is a row of the arena challenge list.
+type ArenaOpponent struct {
+	Name  string `yaml:"name"`  // Opponent's nickname.
+	Power int    `yaml:"power"` // Opponent's power.
 }
@@ -7,9 +7,52 @@ type Buildings struct {
 
 	State BuildingState `yaml:"state"`
 
-	Furnace Building `yaml:"furnace"` // Furnace.
+	Furnace   Building `yaml:"furnace"`   // Furnace.
+	Cookhouse Building `yaml:"cookhouse"` // Cookhouse.
+
+	Next BuildingPlan `yaml:"next"` // Upgrades chosen by the building planner.
 }
 
 type BuildingState struct {
-	Text string `yaml:"text"`
+	Text  string    `yaml:"text"`
+	Name  string    `yaml:"name"`  // the building a queue opened, as the game shows it ("Furnace")
+	Level int       `yaml:"level"` // its level
+	Cost  Resources `yaml:"cost"`  // its upgrade, from the Requires rows of the upgrade screen
+}
+
+// BuildingPlan is the next upgrade of each construction queue, the building table name
+// ("furnace", "cookhouse"); empty when nothing is worth building or affordable.
+// IsPlanned is false when the planner had nothing to plan from (tables, furnace level, resources).
+// TakeSuggested tells the queue usecases whether to upgrade the building the game opened.
+type BuildingPlan struct {
+	Queue1        string `yaml:"queue1"`
+	Queue2        string `yaml:"queue2"`
+	IsPlanned     bool   `yaml:"isPlanned"`
+	TakeSuggested bool   `yaml:"takeSuggested"`
+}
+
+// SetLevel records the level of a building by its table name; levels never go down.
+func (b *Buildings) SetLevel(name string, level int) bool {
+	var building *Building
+	switch name {
+	case "furnace":
+		building = &b.Furnace
+	case "cookhouse":
+		building = &b.Cookhouse
+	default:
+		return false
+	}
+	if level <= building.Level {
+		return false
+	}
+	building.Level = level
+	return true
+}
+
+// Levels returns the building levels by building table name.
+func (b Buildings) Levels() map[string]int {
+	return map[string]int{
+		"furnace":   b.Furnace.Level,
+		"cookhouse": b.Cookhouse.Level,
+	}
 }
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildings_SetLevel(t *testing.T) {
+	var b Buildings
+	assert.True(t, b.SetLevel("furnace", 9))
+	assert.False(t, b.SetLevel("furnace", 8), "levels never go down")
+	assert.False(t, b.SetLevel("infirmary", 21), "no level field")
+	assert.Equal(t, map[string]int{"furnace": 9, "cookhouse": 0}, b.Levels())
+}
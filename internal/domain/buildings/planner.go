@@ -0,0 +1,167 @@
+package buildings
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Strategy decides which upgrade a construction queue takes first.
+type Strategy string
+
+const (
+	RushFurnace    Strategy = "rush_furnace"     // the furnace, then what the next furnace level needs, then power per minute
+	PowerPerMinute Strategy = "power_per_minute" // the most power gained per minute of construction
+	Cheapest       Strategy = "cheapest"         // the fewest resources in total
+)
+
+// ParseStrategy validates a strategy name; empty is RushFurnace.
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(s) {
+	case "":
+		return RushFurnace, nil
+	case RushFurnace, PowerPerMinute, Cheapest:
+		return Strategy(s), nil
+	}
+	return "", fmt.Errorf("unknown building strategy '%s'", s)
+}
+
+// Upgrade is the upgrade of a building to its next level.
+type Upgrade struct {
+	Building string
+	Level    int // level after the upgrade
+	Cost     domain.Resources
+	Time     time.Duration
+	Power    int // power gained
+}
+
+// powerPerMinute is the power gained per minute of construction.
+func (u Upgrade) powerPerMinute() float64 {
+	return float64(u.Power) / max(u.Time.Minutes(), 1.0/60)
+}
+
+// Candidates returns the next level of every building whose prerequisites are met by levels.
+// Prerequisites on buildings without a table are left to the game.
+func (t Tables) Candidates(levels map[string]int) []Upgrade {
+	var out []Upgrade
+	for name, table := range t {
+		current := levels[name]
+		next, ok := table[current+1]
+		if !ok || !t.met(next.Prerequisites, levels) {
+			continue
+		}
+		out = append(out, Upgrade{
+			Building: name,
+			Level:    current + 1,
+			Cost:     next.Cost,
+			Time:     next.ConstructionTime,
+			Power:    next.Power - table[current].Power,
+		})
+	}
+	return out
+}
+
+// Plan chooses the upgrades of up to queues construction queues: one per building, each affordable
+// with the resources the previous queues leave. It reports false without inputs to plan from:
+// no furnace table, an unknown furnace level or no building resources (wood, meat, iron) read yet;
+// coal alone comes from the main city top bar.
+func (t Tables) Plan(levels map[string]int, res domain.Resources, strategy Strategy, queues int) ([]Upgrade, bool) {
+	if _, ok := t["furnace"]; !ok || levels["furnace"] == 0 || res.Wood+res.Meat+res.Iron == 0 {
+		return nil, false
+	}
+
+	candidates := t.Candidates(levels)
+	rank := t.ranking(levels, strategy)
+	sort.Slice(candidates, func(i, j int) bool {
+		return rank(candidates[i], candidates[j])
+	})
+
+	var plan []Upgrade
+	for _, u := range candidates {
+		if len(plan) == queues {
+			break
+		}
+		if !res.Covers(u.Cost) {
+			continue
+		}
+		res = res.Sub(u.Cost)
+		plan = append(plan, u)
+	}
+	return plan, true
+}
+
+// TakesSuggested tells whether a queue may upgrade the building the game suggests: it is planned,
+// or paying for it still leaves the resources of the first planned upgrade. The queues can only open
+// the suggested building, so a plan holds resources back instead of tapping another one.
+func TakesSuggested(plan []Upgrade, suggested string, cost, res domain.Resources) bool {
+	for _, u := range plan {
+		if u.Building == suggested {
+			return true
+		}
+	}
+	if !res.Covers(cost) {
+		return false
+	}
+	return len(plan) == 0 || res.Sub(cost).Covers(plan[0].Cost)
+}
+
+// ranking returns the order of the candidates under a strategy; ties go by building name.
+func (t Tables) ranking(levels map[string]int, strategy Strategy) func(a, b Upgrade) bool {
+	byPower := func(a, b Upgrade) bool {
+		if pa, pb := a.powerPerMinute(), b.powerPerMinute(); pa != pb {
+			return pa > pb
+		}
+		return a.Building < b.Building
+	}
+
+	switch strategy {
+	case Cheapest:
+		return func(a, b Upgrade) bool {
+			if ca, cb := a.Cost.Total(), b.Cost.Total(); ca != cb {
+				return ca < cb
+			}
+			return a.Building < b.Building
+		}
+	case PowerPerMinute:
+		return byPower
+	}
+
+	// RushFurnace
+	blocking := map[string]bool{}
+	if next, ok := t["furnace"][levels["furnace"]+1]; ok {
+		for _, p := range next.Prerequisites {
+			if levels[p.Building] < p.Level {
+				blocking[p.Building] = true
+			}
+		}
+	}
+	priority := func(u Upgrade) int {
+		switch {
+		case u.Building == "furnace":
+			return 2
+		case blocking[u.Building]:
+			return 1
+		}
+		return 0
+	}
+	return func(a, b Upgrade) bool {
+		if pa, pb := priority(a), priority(b); pa != pb {
+			return pa > pb
+		}
+		return byPower(a, b)
+	}
+}
+
+func (t Tables) met(prerequisites []Prerequisite, levels map[string]int) bool {
+	for _, p := range prerequisites {
+		if _, ok := t[p.Building]; !ok {
+			continue
+		}
+		if levels[p.Building] < p.Level {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package buildings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+func TestLoadTables(t *testing.T) {
+	tables, err := LoadTables("../../../references/tables/buildings")
+	require.NoError(t, err)
+
+	lvl5 := tables["cookhouse"][5]
+	assert.Equal(t, []Prerequisite{{Building: "furnace", Level: 5}}, lvl5.Prerequisites)
+	assert.Equal(t, domain.Resources{Wood: 1500, Iron: 360, Meat: 70}, lvl5.Cost)
+	assert.Equal(t, 3*time.Minute, lvl5.ConstructionTime)
+	assert.Equal(t, 465, lvl5.Power)
+
+	furnace := tables["furnace"]
+	require.Contains(t, furnace, 10)
+	assert.Equal(t, []Prerequisite{{Building: "embassy", Level: 8}}, furnace[9].Prerequisites)
+}
+
+func TestParsePrerequisites(t *testing.T) {
+	got, err := parsePrerequisites("Furnace Lv.5, Hero Hall Lv. 4")
+	require.NoError(t, err)
+	assert.Equal(t, []Prerequisite{{"furnace", 5}, {"hero_hall", 4}}, got)
+
+	_, err = parsePrerequisites("Furnace")
+	assert.Error(t, err)
+}
+
+// testTables: the furnace needs the embassy, the cookhouse is fast and cheap, the clinic is slow but strong.
+var testTables = Tables{
+	"furnace": {
+		1: {Power: 100},
+		2: {Prerequisites: []Prerequisite{{"embassy", 1}}, Cost: domain.Resources{Wood: 500}, ConstructionTime: time.Hour, Power: 1000},
+	},
+	"embassy": {
+		1: {Prerequisites: []Prerequisite{{"furnace", 1}}, Cost: domain.Resources{Wood: 200}, ConstructionTime: 20 * time.Minute, Power: 50},
+	},
+	"cookhouse": {
+		1: {Prerequisites: []Prerequisite{{"furnace", 1}}, Cost: domain.Resources{Wood: 50}, ConstructionTime: time.Minute, Power: 30},
+	},
+	"clinic": {
+		1: {Prerequisites: []Prerequisite{{"furnace", 2}}, Cost: domain.Resources{Wood: 10}, ConstructionTime: time.Minute, Power: 900},
+	},
+}
+
+func names(plan []Upgrade) []string {
+	var out []string
+	for _, u := range plan {
+		out = append(out, u.Building)
+	}
+	return out
+}
+
+func TestPlan(t *testing.T) {
+	levels := map[string]int{"furnace": 1}
+	rich := domain.Resources{Wood: 10_000}
+
+	tests := []struct {
+		name     string
+		levels   map[string]int
+		res      domain.Resources
+		strategy Strategy
+		want     []string
+	}{
+		{"rush: what the furnace needs first", levels, rich, RushFurnace, []string{"embassy", "cookhouse"}},
+		{"rush: the furnace once possible", map[string]int{"furnace": 1, "embassy": 1}, rich, RushFurnace, []string{"furnace", "cookhouse"}},
+		{"power per minute", levels, rich, PowerPerMinute, []string{"cookhouse", "embassy"}},
+		{"cheapest", levels, rich, Cheapest, []string{"cookhouse", "embassy"}},
+		{"the second queue gets what is left", levels, domain.Resources{Wood: 220}, RushFurnace, []string{"embassy"}},
+		{"nothing affordable", levels, domain.Resources{Wood: 5}, RushFurnace, nil},
+		{"prerequisites unmet", levels, domain.Resources{Wood: 10}, Cheapest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, ok := testTables.Plan(tt.levels, tt.res, tt.strategy, 2)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, names(plan))
+		})
+	}
+}
+
+func TestPlan_NoInputs(t *testing.T) {
+	rich := domain.Resources{Wood: 10_000}
+
+	_, ok := testTables.Plan(map[string]int{}, rich, RushFurnace, 2)
+	assert.False(t, ok, "furnace level unknown")
+
+	_, ok = testTables.Plan(map[string]int{"furnace": 1}, domain.Resources{Coal: 900}, RushFurnace, 2)
+	assert.False(t, ok, "building resources not read")
+
+	_, ok = Tables{"cookhouse": testTables["cookhouse"]}.Plan(map[string]int{"furnace": 1}, rich, RushFurnace, 2)
+	assert.False(t, ok, "no furnace table")
+}
+
+func TestPlan_UntrackedPrerequisites(t *testing.T) {
+	tables := Tables{"furnace": testTables["furnace"]}
+	plan, ok := tables.Plan(map[string]int{"furnace": 1}, domain.Resources{Wood: 500}, RushFurnace, 2)
+	require.True(t, ok)
+	assert.Equal(t, []string{"furnace"}, names(plan), "the embassy has no table, the game checks it")
+}
+
+func TestPlan_Upgrade(t *testing.T) {
+	plan, ok := testTables.Plan(map[string]int{"furnace": 1, "embassy": 1}, domain.Resources{Wood: 500}, RushFurnace, 1)
+	require.True(t, ok)
+	require.Len(t, plan, 1)
+	assert.Equal(t, Upgrade{Building: "furnace", Level: 2, Cost: domain.Resources{Wood: 500}, Time: time.Hour, Power: 900}, plan[0])
+}
+
+func TestTakesSuggested(t *testing.T) {
+	plan := []Upgrade{{Building: "furnace", Cost: domain.Resources{Wood: 500, Iron: 100}}}
+	res := domain.Resources{Wood: 1_000, Iron: 150}
+
+	assert.True(t, TakesSuggested(plan, "furnace", domain.Resources{}, res), "the plan itself")
+	assert.True(t, TakesSuggested(plan, "infirmary", domain.Resources{Wood: 400, Iron: 50}, res), "leaves enough for the plan")
+	assert.False(t, TakesSuggested(plan, "infirmary", domain.Resources{Wood: 400, Iron: 60}, res), "delays the plan")
+	assert.False(t, TakesSuggested(nil, "infirmary", domain.Resources{Wood: 2_000}, res), "not affordable")
+	assert.True(t, TakesSuggested(nil, "infirmary", domain.Resources{Wood: 400}, res), "nothing planned")
+}
+
+func TestParseStrategy(t *testing.T) {
+	s, err := ParseStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, RushFurnace, s)
+
+	s, err = ParseStrategy("cheapest")
+	require.NoError(t, err)
+	assert.Equal(t, Cheapest, s)
+
+	_, err = ParseStrategy("fastest")
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package buildings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Tables are the upgrade tables of the buildings by building name ("furnace", "cookhouse").
+type Tables map[string]Table
+
+// Table is the upgrade table of one building by level.
+type Table map[int]Level
+
+// Level is the upgrade to a building level.
+type Level struct {
+	Prerequisites    []Prerequisite
+	Cost             domain.Resources
+	ConstructionTime time.Duration
+	Power            int
+}
+
+// Prerequisite is the building level an upgrade needs.
+type Prerequisite struct {
+	Building string
+	Level    int
+}
+
+// levelFile is a level of references/tables/buildings/<name>.yaml.
+type levelFile struct {
+	Prerequisites    string           `yaml:"prerequisites"`
+	BuildCost        domain.Resources `yaml:"build_cost"`
+	ConstructionTime time.Duration    `yaml:"construction_time"`
+	BuildingPower    int              `yaml:"building_power"`
+}
+
+var prerequisiteRe = regexp.MustCompile(`(?i)^\s*(.+?)\s+Lv\.?\s*(\d+)\s*$`)
+
+// LoadTables reads every building table (*.yaml) of a directory.
+func LoadTables(dir string) (Tables, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	tables := Tables{}
+	for _, path := range files {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read building table: %w", err)
+		}
+
+		var file map[string]struct {
+			Levels map[string]levelFile `yaml:"levels"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse building table %s: %w", path, err)
+		}
+
+		for name, building := range file {
+			table := Table{}
+			for key, lvl := range building.Levels {
+				n, err := strconv.Atoi(strings.TrimPrefix(key, "lvl"))
+				if err != nil {
+					return nil, fmt.Errorf("building table %s: invalid level '%s'", path, key)
+				}
+				prerequisites, err := parsePrerequisites(lvl.Prerequisites)
+				if err != nil {
+					return nil, fmt.Errorf("building table %s, %s: %w", path, key, err)
+				}
+				table[n] = Level{
+					Prerequisites:    prerequisites,
+					Cost:             lvl.BuildCost,
+					ConstructionTime: lvl.ConstructionTime,
+					Power:            lvl.BuildingPower,
+				}
+			}
+			tables[Name(name)] = table
+		}
+	}
+	return tables, nil
+}
+
+// Name converts a building name of the game ("Furnace", "Hero Hall") to a table name ("furnace", "hero_hall").
+func Name(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+}
+
+// parsePrerequisites parses "Furnace Lv.5, Embassy Lv.4".
+func parsePrerequisites(s string) ([]Prerequisite, error) {
+	var out []Prerequisite
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		m := prerequisiteRe.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("invalid prerequisite '%s'", strings.TrimSpace(part))
+		}
+		level, _ := strconv.Atoi(m[2])
+		out = append(out, Prerequisite{Building: Name(m[1]), Level: level})
+	}
+	return out, nil
+}
@@ -0,0 +1,13 @@
+package domain
+
+// HSVRange is a color range for local color sampling.
+// Hue is in degrees [0, 360), a range with HMin > HMax wraps around red (e.g. 345–15).
+// Saturation and value are in [0, 1]; zero maximums mean "up to 1".
+type HSVRange struct {
+	HMin float64 `yaml:"hMin"`
+	HMax float64 `yaml:"hMax"`
+	SMin float64 `yaml:"sMin"`
+	SMax float64 `yaml:"sMax"`
+	VMin float64 `yaml:"vMin"`
+	VMax float64 `yaml:"vMax"`
+}
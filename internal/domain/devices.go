@@ -6,6 +6,7 @@ type Config struct {
 
 type Device struct {
 	Name     string    `yaml:"name"`
+	Language string    `yaml:"language,omitempty"` // game language of the accounts on the device, English by default
 	Profiles []Profile `yaml:"profiles"`
 }
 
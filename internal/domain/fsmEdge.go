@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+)
+
+// EdgeStats is the execution history of a single FSM transition (from → to).
+type EdgeStats struct {
+	From          string
+	To            string
+	Attempts      int64         // number of executions
+	Failures      int64         // executions that ended on an unexpected screen or with an error
+	TotalDuration time.Duration // summed duration of all executions
+}
+
+// AvgDuration returns the average duration of an execution.
+func (s EdgeStats) AvgDuration() time.Duration {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Attempts)
+}
+
+// FailureRate returns the share of failed executions in [0, 1].
+func (s EdgeStats) FailureRate() float64 {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Attempts)
+}
@@ -14,11 +14,13 @@ type Gamer struct {
 	Avatar   string `yaml:"avatar"`   // Character avatar URL.
 	Gems     int    `yaml:"gems"`     // Number of gems (premium currency).
 	Power    int    `yaml:"power"`    // Character power.
+	Language string `yaml:"language"` // Game language of the account ("ru", "zh"); empty — the device language or English.
 
 	ScreenState ScreenState `yaml:"screenState"` // Screen state (e.g., "main", "battle", "exploration").
 
 	VIP            VIP            `yaml:"vip"`            // Character VIP status.
 	Resources      Resources      `yaml:"resources"`      // Character resources.
+	ResourceIncome ResourceIncome `yaml:"resourceIncome"` // Resource income estimated from the readings.
 	Exploration    Exploration    `yaml:"exploration"`    // World exploration.
 	Heroes         heroes.Heroes  `yaml:"heroes"`         // Heroes state.
 	Messages       MessagesState  `yaml:"messages"`       // Messages state.
@@ -0,0 +1,69 @@
+package heroes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadCatalog reads the hero catalog (references/heroes.yaml): classes, roles, skills and buffs by hero name.
+func LoadCatalog(path string) (map[string]Hero, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hero catalog: %w", err)
+	}
+
+	var file struct {
+		Heroes map[string]Hero `yaml:"heroes"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse hero catalog %s: %w", path, err)
+	}
+
+	for name, hero := range file.Heroes {
+		hero.Name = name
+		file.Heroes[name] = hero
+	}
+	return file.Heroes, nil
+}
+
+// WithCatalog returns the heroes with the static data of the catalog. The state of known heroes is kept,
+// catalog heroes the gamer hasn't recruited yet are added as unavailable.
+func (h Heroes) WithCatalog(catalog map[string]Hero) Heroes {
+	out := h
+	out.List = make(map[string]Hero, max(len(h.List), len(catalog)))
+	for name, hero := range h.List {
+		out.List[name] = hero
+	}
+
+	for name, hero := range catalog {
+		hero.Name = name
+		hero.State = out.List[name].State
+		out.List[name] = hero
+	}
+	return out
+}
+
+// maxNameDistance is the number of letters OCR may get wrong in a hero name.
+const maxNameDistance = 2
+
+// Lookup returns the hero name closest to an OCR reading, ignoring case.
+func (h Heroes) Lookup(text string) (string, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return "", false
+	}
+
+	best, bestDistance := "", maxNameDistance+1
+	for name := range h.List {
+		d := levenshtein.ComputeDistance(text, strings.ToLower(name))
+		if d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best, bestDistance <= maxNameDistance
+}
@@ -5,10 +5,27 @@ type Heroes struct {
 	IsNotify bool `json:"isNotify"` // Flag indicating the need to notify about hero state.
 
 	List map[string]Hero
+
+	Scan Scan `json:"scan" yaml:"scan"` // Progress of the roster scan on the hero screens.
+	Pick Pick `json:"pick" yaml:"pick"` // Result of the last hero selection (pickHeroes step).
+}
+
+// Scan tracks a pass through the hero screens: it is complete when the first hero comes round again.
+type Scan struct {
+	First      string `json:"first" yaml:"first"`           // First hero read in this scan.
+	Count      int    `json:"count" yaml:"count"`           // Hero screens read in this scan.
+	IsComplete bool   `json:"isComplete" yaml:"isComplete"` // Every hero has been read.
+}
+
+// Pick is the result of a hero selection.
+type Pick struct {
+	IsFound bool `json:"isFound" yaml:"isFound"` // The last searched hero is on the screen.
+	Count   int  `json:"count" yaml:"count"`     // Heroes tapped.
 }
 
 // Hero represents a single hero with their characteristics and state.
 type Hero struct {
+	Name       string            `json:"name"`            // Hero name, the key of Heroes.List
 	Class      string            `json:"class"`           // Infantry, Lancer, Marksman
 	Generation int               `json:"generation"`      // Hero generation
 	Roles      []string          `json:"roles"`           // Roles (rally_leader, resource_gathering, etc.)
@@ -33,6 +50,7 @@ type HeroSkill struct {
 // State describes the current hero status for the user.
 type State struct {
 	Level         int  `json:"level"`           // Upgrade level
+	Stars         int  `json:"stars"`           // Filled stars (0–5)
 	IsAvailable   bool `json:"is_available"`    // Whether the hero is available to the user
 	IsCampTrainer bool `json:"is_camp_trainer"` // Whether the hero is a camp trainer
 }
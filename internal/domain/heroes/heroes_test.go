@@ -0,0 +1,98 @@
+package heroes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadRoster(t *testing.T) Heroes {
+	t.Helper()
+	catalog, err := LoadCatalog("../../../references/heroes.yaml")
+	require.NoError(t, err)
+	return Heroes{}.WithCatalog(catalog)
+}
+
+func TestLoadCatalog(t *testing.T) {
+	h := loadRoster(t)
+
+	smith, ok := h.List["Smith"]
+	require.True(t, ok)
+	assert.Equal(t, "Smith", smith.Name)
+	assert.Equal(t, "15%", smith.Buffs["iron_gathering_speed"])
+	assert.False(t, smith.State.IsAvailable)
+}
+
+func TestRecord_Scan(t *testing.T) {
+	h := loadRoster(t)
+
+	h, ok := h.Record(Card{Name: "Natalla", Level: 60, Stars: 4})
+	require.True(t, ok, "OCR typos are matched")
+	assert.Equal(t, "Natalia", h.Scan.First)
+	assert.Equal(t, State{Level: 60, Stars: 4, IsAvailable: true}, h.List["Natalia"].State)
+
+	h, ok = h.Record(Card{Name: "???", Level: 1})
+	assert.False(t, ok)
+	assert.Equal(t, 2, h.Scan.Count)
+
+	h, _ = h.Record(Card{Name: "MOLLY", Level: 45, Stars: 2})
+	assert.False(t, h.Scan.IsComplete)
+
+	h, _ = h.Record(Card{Name: "Natalia", Level: 61, Stars: 4})
+	assert.True(t, h.Scan.IsComplete)
+	assert.Equal(t, 61, h.List["Natalia"].State.Level)
+}
+
+func TestWithCatalog_KeepsState(t *testing.T) {
+	h, _ := loadRoster(t).Record(Card{Name: "Eugene", Level: 30, Stars: 1})
+	catalog, err := LoadCatalog("../../../references/heroes.yaml")
+	require.NoError(t, err)
+
+	h = h.WithCatalog(catalog)
+	assert.Equal(t, 30, h.List["Eugene"].State.Level)
+	assert.Equal(t, "Eugene", h.Scan.First)
+}
+
+func TestSelect(t *testing.T) {
+	h := loadRoster(t)
+	for _, c := range []Card{
+		{Name: "Smith", Level: 20, Stars: 1},
+		{Name: "Eugene", Level: 20, Stars: 1},
+		{Name: "Cloris", Level: 20, Stars: 1},
+		{Name: "Jeronimo", Level: 50, Stars: 3},
+		{Name: "Natalia", Level: 55, Stars: 3},
+	} {
+		h, _ = h.Record(c)
+	}
+
+	wood, err := h.Select("resource:wood")
+	require.NoError(t, err)
+	require.Len(t, wood, 1)
+	assert.Equal(t, "Eugene", wood[0].Name)
+
+	attack, err := h.Select("attack")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(attack), 2)
+	assert.Equal(t, "Natalia", attack[0].Name, "the higher level first")
+	assert.Equal(t, "Jeronimo", attack[1].Name)
+
+	_, err = h.Select("magic")
+	assert.Error(t, err)
+}
+
+func TestPlanUpgrades(t *testing.T) {
+	h := loadRoster(t)
+	assert.Empty(t, h.PlanUpgrades(0), "no hero scanned yet")
+
+	h, _ = h.Record(Card{Name: "Jeronimo", Level: 60, Stars: 5})
+	h, _ = h.Record(Card{Name: "Natalia", Level: 30, Stars: 2})
+	h, _ = h.Record(Card{Name: "Smith", Level: 30, Stars: 2})
+
+	plan := h.PlanUpgrades(0)
+	require.Len(t, plan, 2, "a maxed hero is skipped")
+	assert.Equal(t, "Natalia", plan[0].Name, "a rally leader outweighs a gatherer")
+	assert.Contains(t, plan[0].Reason, "30 levels behind")
+
+	assert.Len(t, h.PlanUpgrades(1), 1)
+}
@@ -0,0 +1,106 @@
+package heroes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxStars is the star count of a fully ascended hero.
+const MaxStars = 5
+
+// roleWeights rate what a role brings to the account; unlisted roles weigh 1.
+var roleWeights = map[string]float64{
+	"rally_leader":       3,
+	"garrison_defense":   2.5,
+	"defense":            2.5,
+	"combat":             2,
+	"tank":               2,
+	"polar_terror_rally": 2,
+	"resource_gathering": 1.5,
+}
+
+// Upgrade is a hero worth investing in next.
+type Upgrade struct {
+	Name   string
+	Score  float64
+	Reason string
+}
+
+// PlanUpgrades ranks the available heroes by what an upgrade is worth: the best role of the hero,
+// a newer generation and higher skill priorities count more, and so do heroes lagging behind the
+// best level and stars of the roster. Heroes at the top level with full stars are skipped.
+// limit 0 returns every candidate.
+func (h Heroes) PlanUpgrades(limit int) []Upgrade {
+	topLevel := 0
+	for _, hero := range h.List {
+		if hero.State.IsAvailable {
+			topLevel = max(topLevel, hero.State.Level)
+		}
+	}
+
+	var plan []Upgrade
+	for name, hero := range h.List {
+		if !hero.State.IsAvailable || (hero.State.Level >= topLevel && hero.State.Stars >= MaxStars) {
+			continue
+		}
+
+		role, weight := hero.bestRole()
+		skills := hero.skillPriority()
+		levelGap := topLevel - hero.State.Level
+		starGap := max(MaxStars-hero.State.Stars, 0)
+
+		score := weight *
+			(1 + 0.25*float64(max(hero.Generation-1, 0))) *
+			(1 + 0.1*float64(skills)) *
+			(1 + float64(levelGap)/float64(max(topLevel, 1)) + 0.1*float64(starGap))
+
+		reasons := []string{role, fmt.Sprintf("gen %d", hero.Generation)}
+		if skills > 0 {
+			reasons = append(reasons, fmt.Sprintf("skill priority %d", skills))
+		}
+		if levelGap > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d levels behind", levelGap))
+		}
+		if starGap > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d/%d stars", hero.State.Stars, MaxStars))
+		}
+
+		plan = append(plan, Upgrade{Name: name, Score: score, Reason: strings.Join(reasons, ", ")})
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].Score != plan[j].Score {
+			return plan[i].Score > plan[j].Score
+		}
+		return plan[i].Name < plan[j].Name
+	})
+	if limit > 0 && len(plan) > limit {
+		plan = plan[:limit]
+	}
+	return plan
+}
+
+// bestRole returns the role of the hero that weighs most.
+func (h Hero) bestRole() (string, float64) {
+	best, weight := "no role", 1.0
+	for _, role := range h.Roles {
+		w, ok := roleWeights[role]
+		if !ok {
+			w = 1
+		}
+		if w > weight || best == "no role" {
+			best, weight = role, w
+		}
+	}
+	return best, weight
+}
+
+// skillPriority sums the priorities of the expedition skills.
+func (h Hero) skillPriority() int {
+	sum := 0
+	for _, skill := range h.Skills.Expedition {
+		sum += skill.Priority
+	}
+	return sum
+}
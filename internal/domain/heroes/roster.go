@@ -0,0 +1,40 @@
+package heroes
+
+// Card is what a hero screen shows about the hero.
+type Card struct {
+	Name  string // OCR reading of the name
+	Level int
+	Stars int
+}
+
+// Record returns the heroes with a card read on a hero screen: the hero is available with the level
+// and stars of the card. Every card advances the scan; a name that matches no hero is not recorded.
+func (h Heroes) Record(card Card) (Heroes, bool) {
+	out := h
+	out.Scan.Count++
+
+	name, ok := h.Lookup(card.Name)
+	if !ok {
+		return out, false
+	}
+
+	switch {
+	case h.Scan.First == "" || h.Scan.Count == 0:
+		out.Scan.First = name
+		out.Scan.IsComplete = false
+	case name == h.Scan.First:
+		out.Scan.IsComplete = true
+	}
+
+	out.List = make(map[string]Hero, len(h.List))
+	for n, hero := range h.List {
+		out.List[n] = hero
+	}
+
+	hero := out.List[name]
+	hero.State.IsAvailable = true
+	hero.State.Level = card.Level
+	hero.State.Stars = card.Stars
+	out.List[name] = hero
+	return out, true
+}
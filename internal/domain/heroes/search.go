@@ -1,6 +1,14 @@
 package heroes
 
-// BestForResource returns a list of available heroes with a buff for the specified resource.
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BestForResource returns a list of available heroes with a buff for the specified resource,
+// the biggest buff first.
 func (h Heroes) BestForResource(resource string) []Hero {
 	var result []Hero
 	key := resource + "_gathering_speed"
@@ -13,10 +21,14 @@ func (h Heroes) BestForResource(resource string) []Hero {
 			result = append(result, hero)
 		}
 	}
+
+	sort.SliceStable(rank(result), func(i, j int) bool {
+		return buffPercent(result[i].Buffs[key]) > buffPercent(result[j].Buffs[key])
+	})
 	return result
 }
 
-// BestForDefense returns available heroes with a defense role.
+// BestForDefense returns available heroes with a defense role, the strongest first.
 func (h Heroes) BestForDefense() []Hero {
 	var result []Hero
 	for _, hero := range h.List {
@@ -30,10 +42,10 @@ func (h Heroes) BestForDefense() []Hero {
 			}
 		}
 	}
-	return result
+	return rank(result)
 }
 
-// BestForAttack returns available heroes with a combat role.
+// BestForAttack returns available heroes with a combat role, the strongest first.
 func (h Heroes) BestForAttack() []Hero {
 	var result []Hero
 
@@ -49,7 +61,20 @@ func (h Heroes) BestForAttack() []Hero {
 		}
 	}
 
-	return result
+	return rank(result)
+}
+
+// Select returns the heroes of a selector: "defense", "attack" or "resource:<name>" (iron, wood, coal, meat).
+func (h Heroes) Select(selector string) ([]Hero, error) {
+	switch {
+	case selector == "defense":
+		return h.BestForDefense(), nil
+	case selector == "attack":
+		return h.BestForAttack(), nil
+	case strings.HasPrefix(selector, "resource:"):
+		return h.BestForResource(strings.TrimPrefix(selector, "resource:")), nil
+	}
+	return nil, fmt.Errorf("unknown hero selector '%s'", selector)
 }
 
 // Available returns a Heroes structure with only available heroes.
@@ -67,3 +92,26 @@ func (h Heroes) Available() Heroes {
 
 	return out
 }
+
+// rank sorts heroes by stars, level and generation (descending), then by name.
+func rank(list []Hero) []Hero {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch {
+		case a.State.Stars != b.State.Stars:
+			return a.State.Stars > b.State.Stars
+		case a.State.Level != b.State.Level:
+			return a.State.Level > b.State.Level
+		case a.Generation != b.Generation:
+			return a.Generation > b.Generation
+		}
+		return a.Name < b.Name
+	})
+	return list
+}
+
+// buffPercent parses a buff value like "15%".
+func buffPercent(buff string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(buff), "%"), 64)
+	return v
+}
@@ -0,0 +1,111 @@
+package domain
+
+import "time"
+
+// Covers reports whether the resources pay for cost.
+func (r Resources) Covers(cost Resources) bool {
+	return r.Wood >= cost.Wood && r.Food >= cost.Food && r.Iron >= cost.Iron && r.Meat >= cost.Meat && r.Coal >= cost.Coal
+}
+
+// Sub returns the resources left after paying cost.
+func (r Resources) Sub(cost Resources) Resources {
+	return Resources{
+		Wood: r.Wood - cost.Wood,
+		Food: r.Food - cost.Food,
+		Iron: r.Iron - cost.Iron,
+		Meat: r.Meat - cost.Meat,
+		Coal: r.Coal - cost.Coal,
+	}
+}
+
+// Shortage returns what is missing to pay for cost.
+func (r Resources) Shortage(cost Resources) Resources {
+	return Resources{
+		Wood: max(cost.Wood-r.Wood, 0),
+		Food: max(cost.Food-r.Food, 0),
+		Iron: max(cost.Iron-r.Iron, 0),
+		Meat: max(cost.Meat-r.Meat, 0),
+		Coal: max(cost.Coal-r.Coal, 0),
+	}
+}
+
+// Total is the sum of all resources.
+func (r Resources) Total() int {
+	return r.Wood + r.Food + r.Iron + r.Meat + r.Coal
+}
+
+// ResourceIncomeWindow is how far back the income estimate looks.
+const ResourceIncomeWindow = 24 * time.Hour
+
+// ResourceIncome estimates the resources gained per hour from the resource readings.
+type ResourceIncome struct {
+	PerHour Resources        `yaml:"perHour"`           // Gain per hour; spending is not counted.
+	Samples []ResourceSample `yaml:"samples,omitempty"` // The latest reading of every hour of the window.
+}
+
+// ResourceSample is a resource reading.
+type ResourceSample struct {
+	Time      time.Time `yaml:"time"`
+	Resources Resources `yaml:"resources"`
+}
+
+// Record adds a reading and estimates the income of the window from the gains between readings.
+// A reading replaces the previous one of the same hour, so the window keeps at most one sample per hour.
+func (i ResourceIncome) Record(now time.Time, res Resources) ResourceIncome {
+	samples := make([]ResourceSample, 0, len(i.Samples)+1)
+	for _, s := range i.Samples {
+		if now.Sub(s.Time) <= ResourceIncomeWindow && !s.Time.After(now) {
+			samples = append(samples, s)
+		}
+	}
+	if n := len(samples); n > 0 && samples[n-1].Time.Truncate(time.Hour).Equal(now.Truncate(time.Hour)) {
+		samples = samples[:n-1]
+	}
+	samples = append(samples, ResourceSample{Time: now, Resources: res})
+
+	out := ResourceIncome{Samples: samples}
+	hours := now.Sub(samples[0].Time).Hours()
+	if hours <= 0 {
+		return out
+	}
+
+	var gain Resources
+	for k := 1; k < len(samples); k++ {
+		prev, cur := samples[k-1].Resources, samples[k].Resources
+		gain.Wood += max(cur.Wood-prev.Wood, 0)
+		gain.Food += max(cur.Food-prev.Food, 0)
+		gain.Iron += max(cur.Iron-prev.Iron, 0)
+		gain.Meat += max(cur.Meat-prev.Meat, 0)
+		gain.Coal += max(cur.Coal-prev.Coal, 0)
+	}
+	out.PerHour = Resources{
+		Wood: int(float64(gain.Wood) / hours),
+		Food: int(float64(gain.Food) / hours),
+		Iron: int(float64(gain.Iron) / hours),
+		Meat: int(float64(gain.Meat) / hours),
+		Coal: int(float64(gain.Coal) / hours),
+	}
+	return out
+}
+
+// Until returns how long the income takes to cover the shortage of cost; false when it never does.
+func (i ResourceIncome) Until(have, cost Resources) (time.Duration, bool) {
+	short := have.Shortage(cost)
+	hours := 0.0
+	for _, p := range [][2]int{
+		{short.Wood, i.PerHour.Wood},
+		{short.Food, i.PerHour.Food},
+		{short.Iron, i.PerHour.Iron},
+		{short.Meat, i.PerHour.Meat},
+		{short.Coal, i.PerHour.Coal},
+	} {
+		if p[0] == 0 {
+			continue
+		}
+		if p[1] <= 0 {
+			return 0, false
+		}
+		hours = max(hours, float64(p[0])/float64(p[1]))
+	}
+	return time.Duration(hours * float64(time.Hour)), true
+}
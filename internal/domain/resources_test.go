@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceIncome_Record(t *testing.T) {
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	var income ResourceIncome
+	income = income.Record(start, Resources{Wood: 1000, Iron: 500})
+	assert.Equal(t, Resources{}, income.PerHour, "one reading says nothing")
+
+	income = income.Record(start.Add(time.Hour), Resources{Wood: 3000, Iron: 100}) // iron spent
+	income = income.Record(start.Add(2*time.Hour), Resources{Wood: 5000, Iron: 300})
+	assert.Equal(t, Resources{Wood: 2000, Iron: 100}, income.PerHour)
+
+	for m := 10; m < 60; m += 10 {
+		income = income.Record(start.Add(2*time.Hour+time.Duration(m)*time.Minute), Resources{Wood: 5000 + m, Iron: 300})
+	}
+	assert.Len(t, income.Samples, 3, "one sample per hour")
+	assert.Equal(t, Resources{Wood: 5050, Iron: 300}, income.Samples[2].Resources, "the latest reading of the hour")
+
+	income = income.Record(start.Add(30*time.Hour), Resources{Wood: 5000, Iron: 300})
+	assert.Len(t, income.Samples, 1, "readings out of the window are dropped")
+}
+
+func TestResourceIncome_Until(t *testing.T) {
+	income := ResourceIncome{PerHour: Resources{Wood: 1000, Meat: 100}}
+	have := Resources{Wood: 500}
+
+	d, ok := income.Until(have, Resources{Wood: 2500, Meat: 50})
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Hour, d)
+
+	_, ok = income.Until(have, Resources{Iron: 1})
+	assert.False(t, ok, "no iron income")
+
+	d, ok = income.Until(have, Resources{Wood: 100})
+	assert.True(t, ok)
+	assert.Zero(t, d)
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Reference resolution area.json, swipe presets and icons are authored for.
+const (
+	ReferenceWidth  = 1080
+	ReferenceHeight = 2400
+)
+
+// Limits of safe scaling.
+const (
+	MinScaleFactor  = 0.5  // smaller UI text is unreadable for OCR
+	MaxLetterboxPct = 0.10 // wider bars mean the game lays the UI out differently
+)
+
+// ScreenScale maps reference coordinates to a device screen. The game UI is scaled uniformly
+// and centered, so a different aspect ratio leaves letterbox bars on one side.
+type ScreenScale struct {
+	Width   int // device resolution
+	Height  int
+	Factor  float64 // uniform scale of the UI
+	OffsetX float64 // width of the left/right bar
+	OffsetY float64 // height of the top/bottom bar
+}
+
+// IdentityScale is the scale of a reference-resolution device.
+func IdentityScale() ScreenScale {
+	return ScreenScale{Width: ReferenceWidth, Height: ReferenceHeight, Factor: 1}
+}
+
+// NewScreenScale fits the reference screen into width x height.
+func NewScreenScale(width, height int) ScreenScale {
+	s := ScreenScale{Width: width, Height: height}
+	if width <= 0 || height <= 0 {
+		return s
+	}
+
+	s.Factor = math.Min(float64(width)/ReferenceWidth, float64(height)/ReferenceHeight)
+	s.OffsetX = (float64(width) - ReferenceWidth*s.Factor) / 2
+	s.OffsetY = (float64(height) - ReferenceHeight*s.Factor) / 2
+	return s
+}
+
+// IsIdentity reports whether coordinates need no mapping.
+func (s ScreenScale) IsIdentity() bool {
+	return s.Width == ReferenceWidth && s.Height == ReferenceHeight
+}
+
+// Letterboxed reports whether the aspect ratio differs from the reference one.
+func (s ScreenScale) Letterboxed() bool {
+	return s.OffsetX >= 1 || s.OffsetY >= 1
+}
+
+// Check returns why clicks and OCR can't be trusted on this screen, nil when scaling is safe.
+func (s ScreenScale) Check() error {
+	switch {
+	case s.Width <= 0 || s.Height <= 0:
+		return fmt.Errorf("invalid screen resolution %dx%d", s.Width, s.Height)
+	case s.Width > s.Height:
+		return fmt.Errorf("screen %dx%d is in landscape, the game runs in portrait", s.Width, s.Height)
+	case s.Factor < MinScaleFactor:
+		return fmt.Errorf("screen %dx%d scales the UI by %.2f, below the minimum %.2f", s.Width, s.Height, s.Factor, MinScaleFactor)
+	case 2*s.OffsetX > MaxLetterboxPct*float64(s.Width) || 2*s.OffsetY > MaxLetterboxPct*float64(s.Height):
+		return fmt.Errorf("aspect ratio of %dx%d differs too much from %dx%d", s.Width, s.Height, ReferenceWidth, ReferenceHeight)
+	}
+	return nil
+}
+
+// Point maps a reference point to the device.
+func (s ScreenScale) Point(x, y int) (int, int) {
+	if s.IsIdentity() {
+		return x, y
+	}
+	return int(math.Round(s.OffsetX + float64(x)*s.Factor)), int(math.Round(s.OffsetY + float64(y)*s.Factor))
+}
+
+// Rect maps a reference rectangle to the device.
+func (s ScreenScale) Rect(r image.Rectangle) image.Rectangle {
+	x0, y0 := s.Point(r.Min.X, r.Min.Y)
+	x1, y1 := s.Point(r.Max.X, r.Max.Y)
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// Unscale maps a device rectangle (e.g. an OCR box) back to reference coordinates.
+func (s ScreenScale) Unscale(r image.Rectangle) image.Rectangle {
+	if s.IsIdentity() || s.Factor == 0 {
+		return r
+	}
+	back := func(v int, offset float64) int {
+		return int(math.Round((float64(v) - offset) / s.Factor))
+	}
+	return image.Rect(back(r.Min.X, s.OffsetX), back(r.Min.Y, s.OffsetY), back(r.Max.X, s.OffsetX), back(r.Max.Y, s.OffsetY))
+}
+
+// BBox maps a labelled box to the device: the result is in percent of the device screen.
+func (s ScreenScale) BBox(b BBox) BBox {
+	if s.IsIdentity() || s.Factor == 0 {
+		return b
+	}
+
+	// percents are independent of the resolution the box was labelled on
+	toX := func(pct float64) float64 {
+		return (s.OffsetX + pct/100*ReferenceWidth*s.Factor) / float64(s.Width) * 100
+	}
+	toY := func(pct float64) float64 {
+		return (s.OffsetY + pct/100*ReferenceHeight*s.Factor) / float64(s.Height) * 100
+	}
+
+	out := b
+	out.X = toX(b.X)
+	out.Y = toY(b.Y)
+	out.Width = b.Width * ReferenceWidth * s.Factor / float64(s.Width)
+	out.Height = b.Height * ReferenceHeight * s.Factor / float64(s.Height)
+	out.OriginalWidth = s.Width
+	out.OriginalHeight = s.Height
+	return out
+}
@@ -0,0 +1,58 @@
+package domain_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+func TestScreenScale(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		point         image.Point // of reference (540, 1200) – the center
+		letterboxed   bool
+		safe          bool
+	}{
+		{"reference", 1080, 2400, image.Pt(540, 1200), false, true},
+		{"same aspect", 720, 1600, image.Pt(360, 800), false, true},
+		{"taller", 1080, 2520, image.Pt(540, 1260), true, true},
+		{"wider", 1200, 2400, image.Pt(600, 1200), true, true},
+		{"tablet", 1600, 2560, image.Pt(800, 1280), true, false},
+		{"too small", 480, 1066, image.Pt(240, 533), false, false},
+		{"landscape", 2400, 1080, image.Pt(1200, 540), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := domain.NewScreenScale(tt.width, tt.height)
+
+			x, y := s.Point(540, 1200)
+			assert.Equal(t, tt.point, image.Pt(x, y))
+			assert.Equal(t, tt.letterboxed, s.Letterboxed())
+			assert.Equal(t, tt.safe, s.Check() == nil, s.Check())
+		})
+	}
+}
+
+func TestScreenScale_Letterbox(t *testing.T) {
+	s := domain.NewScreenScale(1080, 2520) // 60px bars at the top and bottom
+
+	assert.Equal(t, image.Rect(0, 60, 1080, 2460), s.Rect(image.Rect(0, 0, 1080, 2400)))
+	assert.Equal(t, image.Rect(100, 200, 300, 400), s.Unscale(s.Rect(image.Rect(100, 200, 300, 400))))
+}
+
+func TestScreenScale_BBox(t *testing.T) {
+	ref := domain.NewBBoxFromRect(image.Rect(108, 240, 324, 480), domain.ReferenceWidth, domain.ReferenceHeight)
+
+	assert.Equal(t, ref, domain.IdentityScale().BBox(ref))
+
+	s := domain.NewScreenScale(1200, 2400) // 60px bars on the sides
+	scaled := s.BBox(ref)
+	require.Equal(t, 1200, scaled.OriginalWidth)
+	assert.Equal(t, s.Rect(ref.ToRectangle()), scaled.ToRectangle())
+}
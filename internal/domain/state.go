@@ -11,6 +11,7 @@ type Resources struct {
 	Food int `yaml:"food"` // Food.
 	Iron int `yaml:"iron"` // Iron.
 	Meat int `yaml:"meat"` // Meat.
+	Coal int `yaml:"coal"` // Coal.
 }
 
 // MessagesState contains information about character messages.
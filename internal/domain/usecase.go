@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 )
 
@@ -49,6 +50,10 @@ type Step struct {
 	To  interface{} `yaml:"to,omitempty"`  // New value (in your case — "")
 
 	PushUsecase []PushUsecase `yaml:"pushUsecase,omitempty"` // List of usecases to run when executing this step
+
+	// Hero selection: taps the heroes chosen by the selector ("defense", "attack", "resource:iron") by their names on screen
+	PickHeroes string `yaml:"pickHeroes,omitempty"`
+	Limit      int    `yaml:"limit,omitempty"` // pickHeroes: number of heroes to tap (default 1); loop: max iterations
 }
 
 // IfStep describes a conditional construct of the form if { then {} else {} }
@@ -58,18 +63,81 @@ type IfStep struct {
 	Else    []Step `yaml:"else,omitempty"` // Steps if trigger = false (optional)
 }
 
+// Icon matchers of an analyze rule.
+const (
+	MatcherLocal  = "local"  // template matching in Go on a local screencap
+	MatcherRemote = "remote" // /find_image of the OCR service
+)
+
+// OCR engines an analyze rule can pin.
+const (
+	EngineHTTP      = "http"      // the OCR service
+	EngineTesseract = "tesseract" // the local tesseract CLI
+	EngineRecorded  = "recorded"  // results recorded to a file, for tests and replays
+)
+
 // AnalyzeRule describes rules for analyzing a screen region (screenshot).
 type AnalyzeRule struct {
-	Name              string        `yaml:"name"`                        // Region name (and key for saving)
-	Action            string        `yaml:"action"`                      // Action: "text", "exist", "color_check", "findIcon", "findText"
-	Text              string        `yaml:"text,omitempty"`              // Text to search for (e.g., "Battle")
-	Type              string        `yaml:"type,omitempty"`              // Result type (e.g., "integer" if action = text)
-	Threshold         float64       `yaml:"threshold,omitempty"`         // Confidence level, default 0.9
-	ExpectedColorBg   string        `yaml:"expectedColorBg,omitempty"`   // Expected background color (e.g., "red")
-	ExpectedColorText string        `yaml:"expectedColorText,omitempty"` // Expected text color (e.g., "green")
-	Log               string        `yaml:"log,omitempty"`               // Message for logging (optional)
-	SaveAsRegion      bool          `yaml:"saveAsRegion,omitempty"`      // If true — save the zone as a new temporary region with name .Name
-	PushUseCase       []PushUsecase `yaml:"pushUsecase,omitempty"`       // List of usecases to run when executing this rule
+	Name              string            `yaml:"name"`                        // Region name (and key for saving)
+	Action            string            `yaml:"action"`                      // Action: "text", "exist", "color_check", "color_sample", "findIcon", "findText", "heroCard"
+	Text              string            `yaml:"text,omitempty"`              // Text to search for (e.g., "Battle")
+	Type              string            `yaml:"type,omitempty"`              // Result type of "text": integer, string, time_duration, amount, percent, fraction, countdown, date, regex, table
+	Pattern           string            `yaml:"pattern,omitempty"`           // type regex: expression with named captures
+	Fields            map[string]string `yaml:"fields,omitempty"`            // regex captures / fraction parts ("current", "max") → state paths
+	Table             *TableSpec        `yaml:"table,omitempty"`             // type table: columns of the list field
+	Threshold         float64           `yaml:"threshold,omitempty"`         // Confidence level, default 0.9
+	ExpectedColorBg   string            `yaml:"expectedColorBg,omitempty"`   // Expected background color (e.g., "red")
+	ExpectedColorText string            `yaml:"expectedColorText,omitempty"` // Expected text color (e.g., "green")
+	Log               string            `yaml:"log,omitempty"`               // Message for logging (optional)
+	SaveAsRegion      bool              `yaml:"saveAsRegion,omitempty"`      // If true — save the zone as a new temporary region with name .Name
+	RegionTTL         time.Duration     `yaml:"regionTTL,omitempty"`         // saveAsRegion: how long the saved region stays valid (default 2m)
+	OverrideRegion    bool              `yaml:"overrideRegion,omitempty"`    // saveAsRegion: allow shadowing a region of area.json with the same name
+	Matcher           string            `yaml:"matcher,omitempty"`           // Icon matcher for "exist"/"findIcon": "local" (Go) or "remote" (OCR service); default ICON_MATCHER
+	Engine            string            `yaml:"engine,omitempty"`            // Preferred OCR engine: "http", "tesseract" or "recorded"; default OCR_ENGINE
+	Color             string            `yaml:"color,omitempty"`             // color_sample: palette color ("red", "blue", "gray"…)
+	HSV               []HSVRange        `yaml:"hsv,omitempty"`               // color_sample: explicit HSV ranges instead of a palette color
+	Segments          int               `yaml:"segments,omitempty"`          // color_sample: count the matching columns of the region (stars of a star bar)
+	Policy            *UpdatePolicy     `yaml:"policy,omitempty"`            // When a reading may replace the stored value
+	PushUseCase       []PushUsecase     `yaml:"pushUsecase,omitempty"`       // List of usecases to run when executing this rule
+}
+
+// UpdatePolicy guards a state field against bad readings; rejected readings keep the previous value.
+type UpdatePolicy struct {
+	KeepOnMiss   bool     `yaml:"keepOnMiss,omitempty"` // nothing was read (no OCR text, no digits) — keep the value instead of resetting it
+	Min          *float64 `yaml:"min,omitempty"`        // numeric sanity bounds
+	Max          *float64 `yaml:"max,omitempty"`
+	Monotonic    bool     `yaml:"monotonic,omitempty"`    // the value never decreases (power, building levels)
+	Votes        int      `yaml:"votes,omitempty"`        // apply a value only when it wins the majority of the last N readings
+	MaxDeviation float64  `yaml:"maxDeviation,omitempty"` // reject readings off the median of recent readings by more than this share (0.5 = ±50%)
+}
+
+// Validate checks that the policy is consistent.
+func (p *UpdatePolicy) Validate() error {
+	switch {
+	case p == nil:
+		return nil
+	case p.Min != nil && p.Max != nil && *p.Min > *p.Max:
+		return fmt.Errorf("min %v is greater than max %v", *p.Min, *p.Max)
+	case p.Votes < 0:
+		return fmt.Errorf("votes must not be negative")
+	case p.MaxDeviation < 0:
+		return fmt.Errorf("maxDeviation must not be negative")
+	}
+	return nil
+}
+
+// TableSpec groups the OCR boxes of a region into rows by Y and maps them to the elements of a list field.
+// The first column is the key of a row: rows without it are skipped.
+type TableSpec struct {
+	Columns []TableColumn `yaml:"columns"`
+	RowGap  int           `yaml:"rowGap,omitempty"` // boxes closer vertically than this (reference px) share a row
+}
+
+// TableColumn fills one field of a row element; the first unused box of the row matching Pattern is taken.
+type TableColumn struct {
+	Field   string `yaml:"field"`             // field of the list element, e.g. "power"
+	Type    string `yaml:"type,omitempty"`    // integer, amount, percent or string (default)
+	Pattern string `yaml:"pattern,omitempty"` // optional regexp the box text must match
 }
 
 type PushUsecase struct {
@@ -80,9 +148,67 @@ type PushUsecase struct {
 // Validate checks the validity of the action value in the analysis rule.
 func (r AnalyzeRule) Validate() error {
 	switch r.Action {
-	case "text", "exist", "color_check", "findIcon", "findText":
-		return nil
+	case "text":
+		if err := r.validateText(); err != nil {
+			return fmt.Errorf("text rule '%s': %w", r.Name, err)
+		}
+	case "exist", "color_check", "findIcon", "findText", "heroCard":
+	case "color_sample":
+		if r.Color == "" && len(r.HSV) == 0 && r.Type != "string" {
+			return fmt.Errorf("color_sample rule '%s' requires 'color', 'hsv' or type 'string'", r.Name)
+		}
+		if r.Segments < 0 {
+			return fmt.Errorf("color_sample rule '%s': segments must not be negative", r.Name)
+		}
 	default:
 		return fmt.Errorf("invalid action '%s' in rule '%s'", r.Action, r.Name)
 	}
+
+	if err := r.Policy.Validate(); err != nil {
+		return fmt.Errorf("invalid policy in rule '%s': %w", r.Name, err)
+	}
+
+	switch r.Engine {
+	case "", EngineHTTP, EngineTesseract, EngineRecorded:
+	default:
+		return fmt.Errorf("invalid engine '%s' in rule '%s'", r.Engine, r.Name)
+	}
+
+	switch r.Matcher {
+	case "", MatcherLocal, MatcherRemote:
+		return nil
+	default:
+		return fmt.Errorf("invalid matcher '%s' in rule '%s'", r.Matcher, r.Name)
+	}
+}
+
+func (r AnalyzeRule) validateText() error {
+	switch r.Type {
+	case "regex":
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		for capture := range r.Fields {
+			if re.SubexpIndex(capture) < 0 {
+				return fmt.Errorf("pattern has no capture '%s'", capture)
+			}
+		}
+	case "table":
+		if r.Table == nil || len(r.Table.Columns) == 0 {
+			return fmt.Errorf("type table requires 'table.columns'")
+		}
+		for _, col := range r.Table.Columns {
+			if _, err := regexp.Compile(col.Pattern); err != nil {
+				return fmt.Errorf("column '%s': invalid pattern: %w", col.Field, err)
+			}
+		}
+	case "fraction":
+		for part := range r.Fields {
+			if part != "current" && part != "max" {
+				return fmt.Errorf("fraction has no part '%s'", part)
+			}
+		}
+	}
+	return nil
 }
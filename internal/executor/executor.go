@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"strings"
 	"time"
@@ -25,7 +26,7 @@ type UseCaseExecutor interface {
 
 // Analyzer describes the interface for analyzing screenshots and updating player state
 type Analyzer interface {
-	AnalyzeAndUpdateState(state *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error)
+	AnalyzeAndUpdateState(ctx context.Context, state *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error)
 }
 
 // NewUseCaseExecutor returns an implementation of UseCaseExecutor
@@ -97,6 +98,11 @@ func (e *executorImpl) ExecuteUseCase(ctx context.Context, uc *domain.UseCase, g
 		}
 	}
 
+	// regions saved by this execution don't leak into the next usecases
+	scope := fmt.Sprintf("%s@%d", uc.Name, start.UnixNano())
+	ctx = config.WithRegionScope(ctx, scope)
+	defer e.area.ReleaseScope(scope)
+
 	// Логируем старт UseCase с TraceID
 	e.logger.Info("=== Start usecase ===",
 		slog.String("name", uc.Name),
@@ -156,7 +162,7 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 	if step.Click != "" {
 		e.logger.Info(prefix+"Click", slog.String("target", step.Click))
 
-		err := e.adb.ClickRegion(step.Click, e.area)
+		err := e.adb.ClickRegion(ctx, step.Click, e.area)
 		if err != nil {
 			e.logger.Error(prefix+"Failed to click region",
 				slog.String("target", step.Click),
@@ -208,9 +214,9 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 			loopCtx, loopSpan := otel.Tracer("bot").Start(ctx, prefix+"loop: "+step.Trigger)
 			defer loopSpan.End()
 
-			e.logger.Info(prefix+"Entering loop", slog.String("trigger", step.Trigger))
+			e.logger.Info(prefix+"Entering loop", slog.String("trigger", step.Trigger), slog.Int("limit", step.Limit))
 
-			for {
+			for i := 0; ; i++ {
 				select {
 				case <-loopCtx.Done():
 					e.logger.Warn(prefix + "Loop interrupted by context")
@@ -218,6 +224,11 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 				default:
 				}
 
+				if step.Limit > 0 && i >= step.Limit {
+					e.logger.Warn(prefix+"Loop limit reached, exiting loop", slog.Int("limit", step.Limit))
+					break
+				}
+
 				shouldContinue, err := e.triggerEvaluator.EvaluateTrigger(step.Trigger, gamer)
 				if err != nil {
 					e.logger.Error(prefix+"Trigger evaluation failed", slog.Any("error", err))
@@ -236,6 +247,10 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 				}
 			}
 
+		// Next upgrade of each construction queue: "plan_buildings"
+		case "plan_buildings":
+			e.planBuildings(prefix, gamer)
+
 		// Forced loop exit
 		case "loop_stop":
 			e.logger.Info(prefix + "Received loop_stop")
@@ -245,10 +260,10 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 		case "screenshot":
 			// If there are analysis rules
 			if len(step.Analyze) > 0 {
-				_, analyzeSpan := otel.Tracer("bot").Start(ctx, prefix+"AnalyzeAndUpdateState")
+				analyzeCtx, analyzeSpan := otel.Tracer("bot").Start(ctx, prefix+"AnalyzeAndUpdateState")
 				defer analyzeSpan.End()
 
-				newState, err := e.analyzer.AnalyzeAndUpdateState(gamer, step.Analyze, e.queue)
+				newState, err := e.analyzer.AnalyzeAndUpdateState(analyzeCtx, gamer, step.Analyze, e.queue)
 				if err != nil {
 					e.logger.Error(prefix+"Analyze failed", slog.Any("error", err))
 				} else {
@@ -259,6 +274,11 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 		}
 	}
 
+	// Hero selection: "pickHeroes"
+	if step.PickHeroes != "" {
+		e.pickHeroes(ctx, step, prefix, gamer)
+	}
+
 	// If step.Wait exists — wait
 	if step.Wait > 0 {
 		e.logger.Info(prefix+"Wait", slog.Duration("duration", step.Wait))
@@ -328,7 +348,7 @@ func (e *executorImpl) runStep(ctx context.Context, step domain.Step, indent int
 		}
 
 		x, y, _, _ := bbox.ToPixels()
-		err = e.adb.Swipe(x, y, x, y, step.Wait) // swipe to the same place with specified time
+		err = e.adb.Swipe(ctx, x, y, x, y, step.Wait) // swipe to the same place with specified time
 		if err != nil {
 			e.logger.Error(prefix+"Failed to perform longtap",
 				slog.String("target", step.Longtap),
@@ -8,8 +8,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/batazor/whiteout-survival-autopilot/internal/adb"
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/executor"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
 )
 
 // mockEvaluator counts calls and returns true only twice
@@ -17,7 +20,7 @@ type mockEvaluator struct {
 	counter int
 }
 
-func (m *mockEvaluator) EvaluateTrigger(expr string, state *domain.State) (bool, error) {
+func (m *mockEvaluator) EvaluateTrigger(expr string, gamer *domain.Gamer) (bool, error) {
 	if m.counter < 2 {
 		m.counter++
 		return true, nil
@@ -27,27 +30,33 @@ func (m *mockEvaluator) EvaluateTrigger(expr string, state *domain.State) (bool,
 
 type noopAnalyzer struct{}
 
-func (a *noopAnalyzer) AnalyzeAndUpdateState(imagePath string, state *domain.State, rules []domain.AnalyzeRule) (*domain.Gamer, error) {
-	return state, nil
+func (a *noopAnalyzer) AnalyzeAndUpdateState(ctx context.Context, gamer *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error) {
+	return gamer, nil
 }
 
-type noopADB struct{}
+// noopADB taps nothing; the other device calls are not used by these tests
+type noopADB struct {
+	adb.DeviceController
+}
 
-func (a *noopADB) Screenshot(path string) error {
+func (a *noopADB) ClickRegion(ctx context.Context, name string, area *config.AreaLookup) error {
 	return nil
 }
 
+func newTestExecutor(t *testing.T, evaluator config.TriggerEvaluator) executor.UseCaseExecutor {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	area, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	return executor.NewUseCaseExecutor(logger, evaluator, &noopAnalyzer{}, &noopADB{}, area, "test", nil)
+}
+
 func TestLoopExecution(t *testing.T) {
 	require := require.New(t)
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
-
-	state := &domain.State{}
 	evaluator := &mockEvaluator{}
-	analyzer := &noopAnalyzer{}
-	adb := &noopADB{}
-
-	exec := executor.NewUseCaseExecutor(logger, evaluator, analyzer, adb)
+	exec := newTestExecutor(t, evaluator)
 
 	usecase := &domain.UseCase{
 		Name:    "Test Loop",
@@ -66,7 +75,7 @@ func TestLoopExecution(t *testing.T) {
 		},
 	}
 
-	exec.ExecuteUseCase(context.TODO(), usecase, state)
+	exec.ExecuteUseCase(context.TODO(), usecase, &domain.Gamer{}, nil)
 
 	require.Equal(2, evaluator.counter, "loop should evaluate trigger exactly 2 times")
 }
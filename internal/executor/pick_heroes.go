@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// pickRegion is the state field and temporary region of the hero name found on screen.
+// The region is released after every hero: while it exists the OCR of the next search reads only its box.
+const pickRegion = "heroes.pick.isFound"
+
+// pickHeroes taps the best heroes of the selector by their names on screen, up to the step limit.
+// heroes.pick.count tells the usecase how many were tapped, e.g. to fall back to quick deploy.
+func (e *executorImpl) pickHeroes(ctx context.Context, step domain.Step, prefix string, gamer *domain.Gamer) {
+	gamer.Heroes.Pick.Count = 0
+
+	list, err := gamer.Heroes.Select(step.PickHeroes)
+	if err != nil {
+		e.logger.Error(prefix+"Hero selection failed", slog.Any("error", err))
+		return
+	}
+
+	limit := step.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+
+	pickCtx := config.WithRegionScope(ctx, config.RegionScope(ctx)+"/"+pickRegion)
+	defer e.area.ReleaseScope(config.RegionScope(pickCtx))
+
+	for _, hero := range list {
+		if gamer.Heroes.Pick.Count >= limit {
+			break
+		}
+		e.area.ReleaseScope(config.RegionScope(pickCtx))
+
+		rule := domain.AnalyzeRule{
+			Name:           pickRegion,
+			Action:         "findText",
+			Text:           hero.Name,
+			SaveAsRegion:   true,
+			OverrideRegion: true,
+		}
+		newState, err := e.analyzer.AnalyzeAndUpdateState(pickCtx, gamer, []domain.AnalyzeRule{rule}, nil)
+		if err != nil {
+			e.logger.Error(prefix+"Hero search failed", slog.String("hero", hero.Name), slog.Any("error", err))
+			return
+		}
+		*gamer = *newState
+
+		if !gamer.Heroes.Pick.IsFound {
+			e.logger.Info(prefix+"🦸 Hero not on screen", slog.String("hero", hero.Name))
+			continue
+		}
+
+		if err := e.adb.ClickRegion(ctx, pickRegion, e.area); err != nil {
+			e.logger.Error(prefix+"Failed to tap hero", slog.String("hero", hero.Name), slog.Any("error", err))
+			return
+		}
+		gamer.Heroes.Pick.Count++
+	}
+
+	e.logger.Info(prefix+"🦸 Heroes picked",
+		slog.String("selector", step.PickHeroes),
+		slog.Int("count", gamer.Heroes.Pick.Count),
+		slog.Int("limit", limit),
+	)
+}
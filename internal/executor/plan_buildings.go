@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/buildings"
+)
+
+// planBuildings stores the next upgrade of each construction queue in buildings.next, and whether
+// the building a queue opened (buildings.state) is worth upgrading instead. Its level is recorded first.
+func (e *executorImpl) planBuildings(prefix string, gamer *domain.Gamer) {
+	state := &gamer.Buildings.State
+	suggested, cost := buildings.Name(state.Name), state.Cost
+	if suggested != "" && state.Level > 0 && gamer.Buildings.SetLevel(suggested, state.Level) {
+		e.logger.Info(prefix+"🏗️ Building level updated", slog.String("building", suggested), slog.Int("level", state.Level))
+	}
+	state.Name, state.Level, state.Cost = "", 0, domain.Resources{}
+
+	strategy := config.BuildingStrategy()
+	plan, ok := config.DefaultBuildingTables().Plan(gamer.Buildings.Levels(), gamer.Resources, strategy, 2)
+
+	gamer.Buildings.Next = domain.BuildingPlan{IsPlanned: ok, TakeSuggested: true}
+	if !ok {
+		e.logger.Info(prefix+"🏗️ Nothing to plan from, the queues build what the game suggests",
+			slog.Int("furnace", gamer.Buildings.Furnace.Level),
+			slog.Int("wood", gamer.Resources.Wood),
+		)
+		return
+	}
+	if len(plan) > 0 {
+		gamer.Buildings.Next.Queue1 = plan[0].Building
+	}
+	if len(plan) > 1 {
+		gamer.Buildings.Next.Queue2 = plan[1].Building
+	}
+	if suggested != "" && !buildings.TakesSuggested(plan, suggested, cost, gamer.Resources) {
+		gamer.Buildings.Next.TakeSuggested = false
+		e.logger.Info(prefix+"🏗️ Suggested building skipped, the plan needs the resources",
+			slog.String("building", suggested),
+			slog.String("planned", gamer.Buildings.Next.Queue1),
+		)
+	}
+
+	for i, u := range plan {
+		e.logger.Info(prefix+"🏗️ Building planned",
+			slog.Int("queue", i+1),
+			slog.String("building", u.Building),
+			slog.Int("level", u.Level),
+			slog.Duration("time", u.Time),
+			slog.String("strategy", string(strategy)),
+		)
+	}
+	if len(plan) == 0 {
+		e.logger.Info(prefix+"🏗️ Nothing to build", slog.String("strategy", string(strategy)))
+	}
+}
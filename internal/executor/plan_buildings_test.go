@@ -0,0 +1,93 @@
+package executor_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/executor"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
+)
+
+// upgradeScreenAnalyzer fills the state the way a pass over queue1.png and building_main.png does.
+type upgradeScreenAnalyzer struct {
+	resources domain.Resources
+}
+
+func (a *upgradeScreenAnalyzer) AnalyzeAndUpdateState(ctx context.Context, gamer *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error) {
+	for _, rule := range rules {
+		switch rule.Name {
+		case "buildings.state.text":
+			gamer.Buildings.State.Text = "Upgrade"
+		case "building.name":
+			gamer.Buildings.State.Name = "Infirmary"
+		case "building.requires":
+			gamer.Resources = a.resources
+			gamer.Buildings.State.Cost = domain.Resources{Meat: 8_460_000, Wood: 8_460_000, Coal: 1_690_000, Iron: 423_000}
+		}
+	}
+	return gamer, nil
+}
+
+// clickRecorder remembers the regions the usecase taps.
+type clickRecorder struct {
+	noopADB
+	clicks []string
+}
+
+func (a *clickRecorder) ClickRegion(ctx context.Context, name string, area *config.AreaLookup) error {
+	a.clicks = append(a.clicks, name)
+	return nil
+}
+
+func TestQueue1_PlanGatesSuggestedUpgrade(t *testing.T) {
+	viper.Set("PATH_TO_BUILDING_TABLES", "../../references/tables/buildings")
+
+	uc, err := config.LoadUseCase(context.Background(), "../../usecases/building/queue1.yaml")
+	require.NoError(t, err)
+	area, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+
+	// the Requires rows of references/screenshots/building/building_main.png
+	requires := domain.Resources{Meat: 20_950_000, Wood: 19_770_000, Coal: 4_710_000, Iron: 678_370}
+	tightIron := requires
+	tightIron.Iron = 429_000 // 6K left after the Infirmary, the furnace lvl8 needs 6.3K
+
+	tests := []struct {
+		name      string
+		resources domain.Resources
+		take      bool
+		tap, skip string
+	}{
+		{name: "leaves enough for the plan", resources: requires, take: true, tap: "building.start", skip: "building.close"},
+		{name: "delays the furnace", resources: tightIron, take: false, tap: "building.close", skip: "building.start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := &clickRecorder{}
+			exec := executor.NewUseCaseExecutor(slog.New(slog.DiscardHandler), config.NewTriggerEvaluator(),
+				&upgradeScreenAnalyzer{resources: tt.resources}, device, area, "test", nil)
+
+			gamer := &domain.Gamer{}
+			gamer.Buildings.Furnace.Level = 7
+			gamer.Buildings.Cookhouse.Level = 6
+			exec.ExecuteUseCase(context.Background(), uc, gamer, nil)
+
+			assert.Equal(t, domain.BuildingPlan{
+				Queue1:        "furnace",
+				Queue2:        "cookhouse",
+				IsPlanned:     true,
+				TakeSuggested: tt.take,
+			}, gamer.Buildings.Next)
+			assert.Contains(t, device.clicks, tt.tap)
+			assert.NotContains(t, device.clicks, tt.skip)
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package framearchive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Record is what one analysis pass saw on the screen and the clicks that followed it.
+type Record struct {
+	ID      string                       `json:"id"` // file name of the frame without extension
+	TraceID string                       `json:"traceId,omitempty"`
+	Device  string                       `json:"device"`
+	Gamer   string                       `json:"gamer,omitempty"`
+	Screen  string                       `json:"screen"`
+	Time    time.Time                    `json:"time"`
+	OCR     map[string]domain.OCRResults `json:"ocr,omitempty"` // engine → boxes
+	Icons   []Icon                       `json:"icons,omitempty"`
+	Rules   []Rule                       `json:"rules,omitempty"`
+	Clicks  []Click                      `json:"clicks,omitempty"`
+	Error   string                       `json:"error,omitempty"`
+}
+
+// Icon is the result of an icon search.
+type Icon struct {
+	Name  string            `json:"name"`
+	Found bool              `json:"found"`
+	Boxes []image.Rectangle `json:"boxes,omitempty"`
+}
+
+// Rule is a value a rule read, with the region it was read from.
+type Rule struct {
+	Name   string           `json:"name"` // state field
+	Action string           `json:"action"`
+	Value  any              `json:"value"`
+	Missed bool             `json:"missed,omitempty"`
+	Region *image.Rectangle `json:"region,omitempty"`
+}
+
+// Click is a tap on the device.
+type Click struct {
+	X       int       `json:"x"`
+	Y       int       `json:"y"`
+	Target  string    `json:"target,omitempty"` // region name or OCR text
+	TraceID string    `json:"traceId,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// AddIcon adds an icon search; a nil record ignores it.
+func (r *Record) AddIcon(icon Icon) {
+	if r != nil {
+		r.Icons = append(r.Icons, icon)
+	}
+}
+
+// AddRule adds a rule value; a nil record ignores it.
+func (r *Record) AddRule(rule Rule) {
+	if r != nil {
+		r.Rules = append(r.Rules, rule)
+	}
+}
+
+// Archive keeps the frames of the analysis passes of every device in Dir:
+// <id>.png (screenshot), <id>_overlay.png (annotated) and <id>.json (the record).
+// Taps are collected in memory; the latest frame of a device gets its overlay and final record
+// when the next frame of the device is saved (or on Flush).
+// The newest MaxFrames frames not older than MaxAge are kept, 0 – no limit.
+type Archive struct {
+	Dir       string
+	MaxFrames int
+	MaxAge    time.Duration
+
+	mu   sync.Mutex
+	last map[string]*entry // device → its latest frame, which clicks are added to
+}
+
+type entry struct {
+	record *Record
+	img    image.Image
+	done   bool // overlay and final record written
+}
+
+// New creates an archive.
+func New(dir string, maxFrames int, maxAge time.Duration) *Archive {
+	return &Archive{Dir: dir, MaxFrames: maxFrames, MaxAge: maxAge, last: make(map[string]*entry)}
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultArchive *Archive
+)
+
+// Default returns the archive configured by FRAME_ARCHIVE_DIR, FRAME_ARCHIVE_MAX_FRAMES and
+// FRAME_ARCHIVE_MAX_AGE; nil when FRAME_ARCHIVE_DIR is empty.
+func Default() *Archive {
+	defaultOnce.Do(func() {
+		viper.SetDefault("FRAME_ARCHIVE_DIR", "")
+		viper.SetDefault("FRAME_ARCHIVE_MAX_FRAMES", 500)
+		viper.SetDefault("FRAME_ARCHIVE_MAX_AGE", 24*time.Hour)
+
+		if dir := viper.GetString("FRAME_ARCHIVE_DIR"); dir != "" {
+			defaultArchive = New(dir, viper.GetInt("FRAME_ARCHIVE_MAX_FRAMES"), viper.GetDuration("FRAME_ARCHIVE_MAX_AGE"))
+		}
+	})
+	return defaultArchive
+}
+
+// Enabled reports whether frames are stored.
+func (a *Archive) Enabled() bool {
+	return a != nil && a.Dir != ""
+}
+
+// NewRecord starts the record of an analysis pass; nil when the archive is off.
+func (a *Archive) NewRecord(ctx context.Context, device, gamer, screen string) *Record {
+	if !a.Enabled() {
+		return nil
+	}
+	return &Record{
+		TraceID: traceID(ctx),
+		Device:  device,
+		Gamer:   gamer,
+		Screen:  screen,
+		Time:    time.Now(),
+		OCR:     make(map[string]domain.OCRResults),
+	}
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Save stores the frame with its record, completes the previous frame of the device
+// and drops the frames beyond the retention limits. Later clicks on the device are added to this frame.
+func (a *Archive) Save(rec *Record, img image.Image) error {
+	if !a.Enabled() || rec == nil {
+		return nil
+	}
+	if err := os.MkdirAll(a.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create frame archive: %w", err)
+	}
+
+	// the time first, so names sort by age
+	rec.ID = strings.Join([]string{
+		rec.Time.UTC().Format("20060102T150405.000000000"),
+		unsafeChars.ReplaceAllString(rec.Device, "_"),
+		unsafeChars.ReplaceAllString(rec.Screen, "_"),
+	}, "_")
+
+	if img != nil {
+		if err := writePNG(a.path(rec.ID, ".png"), img); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.last == nil {
+		a.last = make(map[string]*entry)
+	}
+	if prev, ok := a.last[rec.Device]; ok {
+		if err := a.complete(prev); err != nil {
+			return err
+		}
+	}
+	a.last[rec.Device] = &entry{record: rec, img: img}
+
+	if err := a.writeRecord(rec); err != nil {
+		return err
+	}
+	return a.prune(time.Now())
+}
+
+// Flush completes the latest frame of every device: its overlay and the record with the clicks.
+func (a *Archive) Flush() error {
+	if !a.Enabled() {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	for _, e := range a.last {
+		errs = append(errs, a.complete(e))
+	}
+	return errors.Join(errs...)
+}
+
+// RecordClick adds a tap to the latest frame of the device; nothing is written until the frame is completed.
+func (a *Archive) RecordClick(ctx context.Context, device string, x, y int, target string) error {
+	if !a.Enabled() {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	e, ok := a.last[device]
+	if !ok {
+		return nil
+	}
+	e.record.Clicks = append(e.record.Clicks, Click{X: x, Y: y, Target: target, TraceID: traceID(ctx), Time: time.Now()})
+	return nil
+}
+
+// FindByTrace returns the frames of a trace, oldest first. Frames are matched by the trace
+// of the pass or of a click on them.
+func (a *Archive) FindByTrace(traceID string) ([]*Record, error) {
+	records, err := a.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Record
+	for _, rec := range records {
+		if rec.TraceID == traceID || slices.ContainsFunc(rec.Clicks, func(c Click) bool { return c.TraceID == traceID }) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+// List returns every record of the archive, oldest first.
+// The latest frames of this process include the clicks not written yet.
+func (a *Archive) List() ([]*Record, error) {
+	ids, err := a.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	pending := make(map[string][]Click, len(a.last))
+	for _, e := range a.last {
+		pending[e.record.ID] = slices.Clone(e.record.Clicks)
+	}
+	a.mu.Unlock()
+
+	records := make([]*Record, 0, len(ids))
+	for _, id := range ids {
+		data, err := os.ReadFile(a.path(id, ".json"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // pruned meanwhile
+			}
+			return nil, err
+		}
+
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse frame %s: %w", id, err)
+		}
+		if clicks, ok := pending[id]; ok {
+			rec.Clicks = clicks
+		}
+		records = append(records, &rec)
+	}
+	return records, nil
+}
+
+// Path returns the file of a frame: ".png", "_overlay.png" or ".json".
+func (a *Archive) Path(rec *Record, suffix string) string {
+	return a.path(rec.ID, suffix)
+}
+
+func (a *Archive) path(id, suffix string) string {
+	return filepath.Join(a.Dir, id+suffix)
+}
+
+// RenderOverlay draws the overlay of an archived frame from its screenshot and record,
+// e.g. for the latest frame of a device that was not completed yet.
+func (a *Archive) RenderOverlay(rec *Record) error {
+	f, err := os.Open(a.path(rec.ID, ".png"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return fmt.Errorf("failed to decode frame %s: %w", rec.ID, err)
+	}
+	return writePNG(a.path(rec.ID, "_overlay.png"), Overlay(img, rec))
+}
+
+// complete stores the overlay and the record (with the clicks) of a frame, once.
+func (a *Archive) complete(e *entry) error {
+	if e.done {
+		return nil
+	}
+	e.done = true
+
+	if e.img != nil {
+		if err := writePNG(a.path(e.record.ID, "_overlay.png"), Overlay(e.img, e.record)); err != nil {
+			return err
+		}
+	}
+	if len(e.record.Clicks) == 0 {
+		return nil // the record was written by Save
+	}
+	return a.writeRecord(e.record)
+}
+
+func (a *Archive) writeRecord(rec *Record) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.path(rec.ID, ".json"), data, 0o644)
+}
+
+// ids returns the frame ids of the archive, oldest first.
+func (a *Archive) ids() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(a.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(files))
+	for i, f := range files {
+		ids[i] = strings.TrimSuffix(filepath.Base(f), ".json")
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// prune removes the frames beyond MaxFrames and those older than MaxAge.
+func (a *Archive) prune(now time.Time) error {
+	ids, err := a.ids()
+	if err != nil {
+		return err
+	}
+
+	drop := 0
+	if a.MaxFrames > 0 && len(ids) > a.MaxFrames {
+		drop = len(ids) - a.MaxFrames
+	}
+	if a.MaxAge > 0 {
+		for drop < len(ids) && frameTime(ids[drop]).Before(now.Add(-a.MaxAge)) {
+			drop++
+		}
+	}
+
+	for _, id := range ids[:drop] {
+		for device, e := range a.last {
+			if e.record.ID == id {
+				delete(a.last, device) // nothing to complete
+			}
+		}
+		for _, suffix := range []string{".png", "_overlay.png", ".json"} {
+			if err := os.Remove(a.path(id, suffix)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// frameTime parses the time prefix of a frame id; ids of other files are the oldest.
+func frameTime(id string) time.Time {
+	prefix, _, _ := strings.Cut(id, "_")
+	t, err := time.Parse("20060102T150405.000000000", prefix)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func traceID(ctx context.Context) string {
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return f.Close()
+}
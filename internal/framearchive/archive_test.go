@@ -0,0 +1,151 @@
+package framearchive
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+func traceContext(t *testing.T, id string) context.Context {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex(id)
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+const (
+	traceA = "0af7651916cd43dd8448eb211c80319c"
+	traceB = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func TestArchive_SaveAndClick(t *testing.T) {
+	archive := New(t.TempDir(), 0, 0)
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+
+	rec := archive.NewRecord(traceContext(t, traceA), "127.0.0.1:5555", "batazor", "main_city")
+	rec.OCR[domain.EngineHTTP] = domain.OCRResults{{Text: "Mail", X: 10, Y: 10, Width: 40, Height: 20}}
+	region := image.Rect(100, 100, 150, 120)
+	rec.AddRule(Rule{Name: "gems", Action: "text", Value: 1250, Region: &region})
+	rec.AddIcon(Icon{Name: "to_mail", Found: true, Boxes: []image.Rectangle{image.Rect(60, 60, 90, 90)}})
+	require.NoError(t, archive.Save(rec, img))
+
+	assert.Regexp(t, `^\d{8}T\d{6}\.\d{9}_127_0_0_1_5555_main_city$`, rec.ID)
+	for _, suffix := range []string{".png", ".json"} {
+		assert.FileExists(t, archive.Path(rec, suffix))
+	}
+	assert.NoFileExists(t, archive.Path(rec, "_overlay.png"), "rendered once the frame is completed")
+
+	// the click that followed the pass, in the trace of the next usecase
+	require.NoError(t, archive.RecordClick(traceContext(t, traceB), "127.0.0.1:5555", 30, 40, "to_mail"))
+	require.NoError(t, archive.RecordClick(context.Background(), "other-device", 1, 1, ""), "a device without frames")
+
+	found, err := archive.FindByTrace(traceB)
+	require.NoError(t, err)
+	require.Len(t, found, 1, "found by the trace of the click")
+	assert.Equal(t, traceA, found[0].TraceID)
+	assert.Equal(t, "to_mail", found[0].Clicks[0].Target)
+	assert.Equal(t, []Rule{{Name: "gems", Action: "text", Value: 1250.0, Region: &region}}, found[0].Rules)
+
+	// the next frame of the device completes this one
+	next := archive.NewRecord(traceContext(t, traceB), "127.0.0.1:5555", "batazor", "mail")
+	require.NoError(t, archive.Save(next, img))
+	assert.NoFileExists(t, archive.Path(next, "_overlay.png"))
+
+	stored, err := New(archive.Dir, 0, 0).FindByTrace(traceB)
+	require.NoError(t, err)
+	require.Len(t, stored, 2, "the clicks are written with the frame")
+	assert.Equal(t, rec.ID, stored[0].ID)
+	assert.Len(t, stored[0].Clicks, 1)
+
+	overlay := readPNG(t, archive.Path(rec, "_overlay.png"))
+	assert.Equal(t, clickColor, color.RGBAModel.Convert(overlay.At(30, 40)), "the click")
+	assert.Equal(t, ocrColor, color.RGBAModel.Convert(overlay.At(10, 20)), "the OCR box")
+	assert.Equal(t, regionColor, color.RGBAModel.Convert(overlay.At(120, 100)), "the rule region")
+	assert.Equal(t, color.RGBA{}, color.RGBAModel.Convert(readPNG(t, archive.Path(rec, ".png")).At(30, 40)), "the screenshot is kept clean")
+}
+
+func TestArchive_FlushAndRenderOverlay(t *testing.T) {
+	archive := New(t.TempDir(), 0, 0)
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+
+	rec := archive.NewRecord(context.Background(), "d", "g", "main_city")
+	require.NoError(t, archive.Save(rec, img))
+	require.NoError(t, archive.RecordClick(context.Background(), "d", 30, 40, "to_mail"))
+
+	// another process (cmd/frames) renders the overlay of a frame that was not completed
+	reader := New(archive.Dir, 0, 0)
+	records, err := reader.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Empty(t, records[0].Clicks)
+	require.NoError(t, reader.RenderOverlay(records[0]))
+	assert.FileExists(t, archive.Path(rec, "_overlay.png"))
+
+	require.NoError(t, archive.Flush())
+	records, err = reader.List()
+	require.NoError(t, err)
+	assert.Len(t, records[0].Clicks, 1)
+	overlay := readPNG(t, archive.Path(rec, "_overlay.png"))
+	assert.Equal(t, clickColor, color.RGBAModel.Convert(overlay.At(30, 40)), "the click")
+}
+
+func TestArchive_Retention(t *testing.T) {
+	archive := New(t.TempDir(), 3, time.Hour)
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	now := time.Now()
+
+	var ids []string
+	for i, age := range []time.Duration{3 * time.Hour, 50 * time.Minute, 40 * time.Minute, 30 * time.Minute, 20 * time.Minute} {
+		rec := &Record{Device: "d", Screen: "main_city", Time: now.Add(-age), TraceID: []string{traceA, traceB}[i%2]}
+		require.NoError(t, archive.Save(rec, img))
+		ids = append(ids, rec.ID)
+	}
+
+	records, err := archive.List()
+	require.NoError(t, err)
+	require.Len(t, records, 3, "MaxFrames")
+	assert.Equal(t, ids[2:], []string{records[0].ID, records[1].ID, records[2].ID}, "the newest, oldest first")
+	assert.NoFileExists(t, filepath.Join(archive.Dir, ids[0]+"_overlay.png"))
+
+	archive.MaxFrames = 0
+	require.NoError(t, archive.prune(now.Add(35*time.Minute)))
+	records, err = archive.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1, "MaxAge")
+	assert.Equal(t, ids[4], records[0].ID)
+}
+
+func TestArchive_Disabled(t *testing.T) {
+	var archive *Archive
+	assert.False(t, archive.Enabled())
+
+	rec := archive.NewRecord(context.Background(), "d", "g", "main_city")
+	assert.Nil(t, rec)
+	rec.AddRule(Rule{Name: "gems"}) // no-op on nil
+	assert.NoError(t, archive.Save(rec, nil))
+	assert.NoError(t, archive.RecordClick(context.Background(), "d", 1, 1, ""))
+}
+
+func readPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	require.NoError(t, err)
+	return img
+}
@@ -0,0 +1,72 @@
+package framearchive
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Overlay colors; the texts and values are in the record.
+var (
+	ocrColor    = color.RGBA{G: 200, A: 255}         // OCR boxes
+	regionColor = color.RGBA{R: 255, G: 200, A: 255} // regions the rules read
+	iconColor   = color.RGBA{B: 255, R: 160, A: 255} // icons found
+	clickColor  = color.RGBA{R: 255, A: 255}         // taps
+)
+
+const (
+	lineWidth   = 3
+	clickRadius = 24
+)
+
+// Overlay draws the record on a copy of the screenshot.
+func Overlay(img image.Image, rec *Record) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	for _, boxes := range rec.OCR {
+		for _, b := range boxes {
+			strokeRect(out, image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height), ocrColor)
+		}
+	}
+	for _, r := range rec.Rules {
+		if r.Region != nil {
+			strokeRect(out, *r.Region, regionColor)
+		}
+	}
+	for _, icon := range rec.Icons {
+		for _, b := range icon.Boxes {
+			strokeRect(out, b, iconColor)
+		}
+	}
+	for _, c := range rec.Clicks {
+		cross(out, image.Pt(c.X, c.Y), clickColor)
+	}
+	return out
+}
+
+// strokeRect draws the outline of r.
+func strokeRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	src := image.NewUniform(c)
+	for _, side := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+lineWidth),
+		image.Rect(r.Min.X, r.Max.Y-lineWidth, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+lineWidth, r.Max.Y),
+		image.Rect(r.Max.X-lineWidth, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(img, side.Intersect(img.Bounds()), src, image.Point{}, draw.Src)
+	}
+}
+
+// cross marks a tap with a crosshair in a square.
+func cross(img *image.RGBA, p image.Point, c color.Color) {
+	src := image.NewUniform(c)
+	half := lineWidth / 2
+	for _, bar := range []image.Rectangle{
+		image.Rect(p.X-clickRadius, p.Y-half, p.X+clickRadius, p.Y-half+lineWidth),
+		image.Rect(p.X-half, p.Y-clickRadius, p.X-half+lineWidth, p.Y+clickRadius),
+	} {
+		draw.Draw(img, bar.Intersect(img.Bounds()), src, image.Point{}, draw.Src)
+	}
+	strokeRect(img, image.Rect(p.X-clickRadius, p.Y-clickRadius, p.X+clickRadius, p.Y+clickRadius), c)
+}
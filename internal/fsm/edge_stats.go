@@ -0,0 +1,148 @@
+package fsm
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
+)
+
+const (
+	// minEdgeSamples – executions required before learned stats replace the defaults.
+	minEdgeSamples = 3
+
+	// defaultEdgeDuration – assumed duration of a transition without history (wait + screen check).
+	defaultEdgeDuration = 1500 * time.Millisecond
+
+	// minEdgeSuccessRate caps the penalty of an edge that (almost) always fails.
+	minEdgeSuccessRate = 0.05
+)
+
+// edgeStatsCache holds learned stats of transitions, keyed by "from|to".
+type edgeStatsCache struct {
+	mu    sync.RWMutex
+	stats map[string]domain.EdgeStats
+}
+
+func (c *edgeStatsCache) get(from, to string) (domain.EdgeStats, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, ok := c.stats[from+"|"+to]
+	return s, ok
+}
+
+func (c *edgeStatsCache) add(from, to string, duration time.Duration, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil {
+		c.stats = make(map[string]domain.EdgeStats)
+	}
+
+	s := c.stats[from+"|"+to]
+	s.From, s.To = from, to
+	s.Attempts++
+	s.TotalDuration += duration
+	if !success {
+		s.Failures++
+	}
+	c.stats[from+"|"+to] = s
+}
+
+func (c *edgeStatsCache) replace(stats []domain.EdgeStats) {
+	indexed := make(map[string]domain.EdgeStats, len(stats))
+	for _, s := range stats {
+		indexed[s.From+"|"+s.To] = s
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = indexed
+}
+
+// SetEdgeStatsRepository enables learning of edge costs and loads the stats collected so far.
+func (g *GameFSM) SetEdgeStatsRepository(ctx context.Context, repo repository.EdgeStatsRepository) {
+	g.edgeStatsRepo = repo
+
+	stats, err := repo.LoadEdgeStats(ctx)
+	if err != nil {
+		g.logger.Warn("FSM: failed to load edge stats, using static costs", slog.Any("error", err))
+		return
+	}
+
+	g.edgeStats.replace(stats)
+}
+
+// recordEdge stores the outcome of a single transition.
+func (g *GameFSM) recordEdge(from, to string, duration time.Duration, success bool) {
+	g.edgeStats.add(from, to, duration, success)
+
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	metrics.FSMTransitionTotal.WithLabelValues(from, to, result).Inc()
+	metrics.FSMTransitionDuration.WithLabelValues(from, to).Observe(duration.Seconds())
+
+	if g.edgeStatsRepo == nil {
+		return
+	}
+
+	if err := g.edgeStatsRepo.RecordEdge(context.Background(), from, to, duration, success); err != nil {
+		g.logger.Warn("FSM: failed to record edge stats",
+			slog.String("from", from),
+			slog.String("to", to),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// edgeWeight returns the expected cost of a transition in seconds:
+// average duration divided by the success rate, multiplied by the static cost from the graph.
+// Edges without enough history use defaultEdgeDuration and are assumed reliable.
+func (g *GameFSM) edgeWeight(graph *config.FSMGraph, from, to string) float64 {
+	base := float64(cost(graph, from, to))
+
+	stats, ok := g.edgeStats.get(from, to)
+	if !ok || stats.Attempts < minEdgeSamples {
+		return base * defaultEdgeDuration.Seconds()
+	}
+
+	success := math.Max(1-stats.FailureRate(), minEdgeSuccessRate)
+	return base * stats.AvgDuration().Seconds() / success
+}
+
+// FlakiestEdges returns transitions with at least minAttempts executions,
+// ordered by failure rate (then by failures count), at most limit items (0 – no limit).
+func FlakiestEdges(stats []domain.EdgeStats, minAttempts int64, limit int) []domain.EdgeStats {
+	var result []domain.EdgeStats
+	for _, s := range stats {
+		if s.Attempts >= minAttempts && s.Failures > 0 {
+			result = append(result, s)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FailureRate() != result[j].FailureRate() {
+			return result[i].FailureRate() > result[j].FailureRate()
+		}
+		if result[i].Failures != result[j].Failures {
+			return result[i].Failures > result[j].Failures
+		}
+		return result[i].From+result[i].To < result[j].From+result[j].To
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result
+}
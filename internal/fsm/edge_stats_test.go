@@ -0,0 +1,107 @@
+package fsm
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// main_city → alliance_manage directly, or via main_menu_city.
+const weightedGraph = `
+screens:
+  - name: main_city
+    edges:
+      - to: alliance_manage
+        steps: [{ click: to_alliance_manage }]
+      - to: main_menu_city
+        steps: [{ click: to_main_menu_city }]
+  - name: main_menu_city
+    edges:
+      - to: alliance_manage
+        steps: [{ click: to_alliance_manage }]
+      - to: main_city
+        steps: [{ click: from_main_menu_city_to_main_city }]
+  - name: alliance_manage
+    edges:
+      - to: main_city
+        steps: [{ click: to_alliance_back }]
+`
+
+func newTestGame(t *testing.T, graph string) *GameFSM {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fsmGraph.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(graph), 0644))
+
+	loader, err := config.NewFSMGraphLoader(path, nil)
+	require.NoError(t, err)
+
+	return &GameFSM{
+		graph:  loader,
+		logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+}
+
+func TestFindPath_PrefersDirectEdgeWithoutHistory(t *testing.T) {
+	g := newTestGame(t, weightedGraph)
+
+	require.Equal(t, []string{"main_city", "alliance_manage"}, g.FindPath("main_city", "alliance_manage"))
+}
+
+func TestFindPath_AvoidsFlakyEdge(t *testing.T) {
+	g := newTestGame(t, weightedGraph)
+
+	// the direct edge fails 4 of 5 times
+	for i := 0; i < 5; i++ {
+		g.recordEdge("main_city", "alliance_manage", time.Second, i == 0)
+	}
+
+	require.Equal(t,
+		[]string{"main_city", "main_menu_city", "alliance_manage"},
+		g.FindPath("main_city", "alliance_manage"),
+	)
+}
+
+func TestFindPath_AvoidsSlowEdge(t *testing.T) {
+	g := newTestGame(t, weightedGraph)
+
+	g.edgeStats.replace([]domain.EdgeStats{
+		{From: "main_city", To: "alliance_manage", Attempts: 10, TotalDuration: 60 * time.Second},
+		{From: "main_city", To: "main_menu_city", Attempts: 10, TotalDuration: 10 * time.Second},
+		{From: "main_menu_city", To: "alliance_manage", Attempts: 10, TotalDuration: 10 * time.Second},
+	})
+
+	require.Equal(t,
+		[]string{"main_city", "main_menu_city", "alliance_manage"},
+		g.FindPath("main_city", "alliance_manage"),
+	)
+}
+
+func TestFindPath_NoPath(t *testing.T) {
+	g := newTestGame(t, weightedGraph)
+
+	require.Nil(t, g.FindPath("main_city", "mail"))
+}
+
+func TestFlakiestEdges(t *testing.T) {
+	stats := []domain.EdgeStats{
+		{From: "a", To: "b", Attempts: 10, Failures: 1},
+		{From: "b", To: "c", Attempts: 10, Failures: 5},
+		{From: "c", To: "d", Attempts: 2, Failures: 2}, // not enough samples
+		{From: "d", To: "e", Attempts: 10},             // never failed
+		{From: "e", To: "f", Attempts: 20, Failures: 10},
+	}
+
+	got := FlakiestEdges(stats, 3, 2)
+
+	require.Len(t, got, 2)
+	require.Equal(t, "e", got[0].From, "same rate, more failures first")
+	require.Equal(t, "b", got[1].From)
+}
@@ -1,84 +1,107 @@
-package fsm_test
+package fsm
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/batazor/whiteout-survival-autopilot/internal/classifier"
 	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
-	fsm2 "github.com/batazor/whiteout-survival-autopilot/internal/fsm"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
 )
 
-func TestExpectState_TableDriven(t *testing.T) {
-	// Set ENV
-	os.Setenv("PATH_TO_FSM_STATE_RULES", "../../references/fsmState.yaml")
+// titleAnalyzer returns the title signals of a screen instead of reading them.
+type titleAnalyzer struct {
+	title, family string
+	err           error
+}
 
-	lookup, err := config.LoadAreaReferences("../../references/area.json")
-	if err != nil {
-		t.Fatalf("failed to load area.json: %v", err)
+func (a titleAnalyzer) AnalyzeAndUpdateState(_ context.Context, state *domain.Gamer, _ []domain.AnalyzeRule, _ *redis_queue.Queue) (*domain.Gamer, error) {
+	if a.err != nil {
+		return nil, a.err
 	}
+	out := *state
+	out.ScreenState.TitleFact = a.title
+	out.ScreenState.IsMainCity = a.family
+	return &out, nil
+}
+
+// newClassifierFSM builds a FSM on the real graph whose screen signals come from analyzer.
+func newClassifierFSM(t *testing.T, analyzer stateAnalyzer) *GameFSM {
+	t.Helper()
+
+	lookup, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+	graph, err := config.NewFSMGraphLoader("../../references/fsmGraph.yaml", lookup)
+	require.NoError(t, err)
+
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	fakeADB := &FakeADB{}
+	return &GameFSM{
+		logger:     logger,
+		lookup:     lookup,
+		graph:      graph,
+		analyzer:   analyzer,
+		classifier: classifier.New(lookup, logger),
+	}
+}
+
+func TestExpectState_TableDriven(t *testing.T) {
+	errOCR := errors.New("ocr down")
 
 	tests := []struct {
-		name           string
-		want           string
-		ocrTitle       string
-		ocrFamily      string
-		expectedResult string
-		expectedErr    bool
-		analyzeErr     error
+		name     string
+		want     string
+		analyzer titleAnalyzer
+		expected string
+		err      error
 	}{
 		{
-			name:           "want in filtered group (city)",
-			want:           "main_city",
-			ocrTitle:       "MainCity", // <-- key matches the map!
-			ocrFamily:      "world",    // filter on main_city
-			expectedResult: "main_city",
+			name:     "the title identifies the screen",
+			want:     "heroes",
+			analyzer: titleAnalyzer{title: "Heroes"},
+			expected: "heroes",
+		},
+		{
+			name:     "another screen than want is reported, not want",
+			want:     "mail",
+			analyzer: titleAnalyzer{title: "Heroes"},
+			expected: "heroes",
 		},
 		{
-			name:           "want in filtered group (world)",
-			want:           "world",
-			ocrTitle:       "MainCity",
-			ocrFamily:      "city", // filter on world
-			expectedResult: "world",
+			name:     "a sub-screen without its signals is the main screen of the group",
+			want:     "mail_wars",
+			analyzer: titleAnalyzer{title: "Mail"},
+			expected: "mail",
 		},
 		{
-			name:           "want not in group, but group not empty — returns first",
-			want:           "some_state",
-			ocrTitle:       "MainCity",
-			ocrFamily:      "",
-			expectedResult: "some_state",
+			name:     "no title matches — unknown screen instead of want",
+			want:     "mail",
+			analyzer: titleAnalyzer{title: "Unknown"},
+			err:      ErrUnknownScreen,
 		},
 		{
-			name:           "no title matches — returns want",
-			want:           "mail",
-			ocrTitle:       "Unknown", // No such key
-			ocrFamily:      "",
-			expectedResult: "mail",
+			name:     "analysis errors are returned",
+			want:     "mail",
+			analyzer: titleAnalyzer{err: errOCR},
+			err:      errOCR,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			oldTitleToState := config.TitleToState
-			defer func() { config.TitleToState = oldTitleToState }()
-
-			gamer := &domain.Gamer{
-				ScreenState: domain.ScreenState{},
-			}
-			gameFSM := fsm2.NewGame(logger, fakeADB, lookup, nil, gamer)
-
-			got, err := gameFSM.ExpectState(tt.want)
-			if tt.expectedErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedResult, got)
+			got, err := newClassifierFSM(t, tt.analyzer).ExpectState(context.Background(), tt.want)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
 			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
 		})
 	}
 }
@@ -0,0 +1,209 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
+)
+
+// GraphReport annotates the FSM graph for export: usecases per screen and connectivity problems.
+type GraphReport struct {
+	Graph *config.FSMGraph
+
+	Usecases    map[string][]string // screen → usecases starting on it
+	Unreachable map[string]bool     // can't be reached from main_city
+	DeadEnds    map[string]bool     // no outgoing edges
+	NoWayBack   map[string]bool     // main_city can't be reached from the screen
+}
+
+// NewGraphReport analyzes the graph. Usecases are grouped by their node.
+func NewGraphReport(graph *config.FSMGraph, usecases []*domain.UseCase) *GraphReport {
+	report := &GraphReport{
+		Graph:       graph,
+		Usecases:    make(map[string][]string),
+		Unreachable: make(map[string]bool),
+		DeadEnds:    make(map[string]bool),
+		NoWayBack:   make(map[string]bool),
+	}
+
+	for _, uc := range usecases {
+		if uc.Node != "" {
+			report.Usecases[uc.Node] = append(report.Usecases[uc.Node], uc.Name)
+		}
+	}
+	for node := range report.Usecases {
+		sort.Strings(report.Usecases[node])
+	}
+
+	reachable := graph.ReachableFrom(state.StateMainCity)
+	wayBack := graph.CanReach(state.StateMainCity)
+	for _, screen := range graph.Screens {
+		report.Unreachable[screen.Name] = !reachable[screen.Name]
+		report.DeadEnds[screen.Name] = len(screen.Edges) == 0
+		report.NoWayBack[screen.Name] = !wayBack[screen.Name]
+	}
+
+	return report
+}
+
+// DOT renders the graph in Graphviz format.
+func (r *GraphReport) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph fsm {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=white, fontname=Helvetica];\n")
+	b.WriteString("  edge [fontname=Helvetica, fontsize=10];\n\n")
+
+	for _, screen := range r.Graph.Screens {
+		attrs := []string{fmt.Sprintf("label=\"%s\"", dotEscape(strings.Join(r.nodeLines(screen), "\n")))}
+
+		switch {
+		case r.NoWayBack[screen.Name]:
+			attrs = append(attrs, "fillcolor=\"#ffc9c9\"")
+		case r.Unreachable[screen.Name]:
+			attrs = append(attrs, "fillcolor=\"#ffe8cc\"")
+		}
+		if r.Unreachable[screen.Name] {
+			attrs = append(attrs, "style=\"rounded,filled,dashed\"")
+		}
+		if r.DeadEnds[screen.Name] {
+			attrs = append(attrs, "color=\"#e03131\"", "penwidth=2")
+		}
+
+		fmt.Fprintf(&b, "  %q [%s];\n", screen.Name, strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("\n")
+	for _, screen := range r.Graph.Screens {
+		for _, edge := range screen.Edges {
+			attrs := []string{fmt.Sprintf("label=\"%s\"", dotEscape(strings.Join(edgeLines(edge), "\n")))}
+			if len(edge.Steps) == 0 {
+				attrs = append(attrs, "style=dotted")
+			}
+			fmt.Fprintf(&b, "  %q -> %q [%s];\n", screen.Name, edge.To, strings.Join(attrs, ", "))
+		}
+	}
+
+	b.WriteString("\n  subgraph cluster_legend {\n")
+	b.WriteString("    label=\"Legend\";\n")
+	b.WriteString("    legend_unreachable [label=\"unreachable from main_city\", fillcolor=\"#ffe8cc\", style=\"rounded,filled,dashed\"];\n")
+	b.WriteString("    legend_no_way_back [label=\"no path back to main_city\", fillcolor=\"#ffc9c9\"];\n")
+	b.WriteString("    legend_dead_end [label=\"dead end\", color=\"#e03131\", penwidth=2];\n")
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+func (r *GraphReport) Mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+	b.WriteString("  classDef unreachable fill:#ffe8cc,stroke-dasharray:5 5\n")
+	b.WriteString("  classDef noWayBack fill:#ffc9c9\n")
+	b.WriteString("  classDef deadEnd stroke:#e03131,stroke-width:3px\n\n")
+
+	for _, screen := range r.Graph.Screens {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", screen.Name, mermaidEscape(strings.Join(r.nodeLines(screen), "<br/>")))
+	}
+
+	b.WriteString("\n")
+	for _, screen := range r.Graph.Screens {
+		for _, edge := range screen.Edges {
+			arrow := "-->"
+			if len(edge.Steps) == 0 {
+				arrow = "-.->"
+			}
+
+			label := strings.Join(edgeLines(edge), "<br/>")
+			if label == "" {
+				fmt.Fprintf(&b, "  %s %s %s\n", screen.Name, arrow, edge.To)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", screen.Name, arrow, mermaidEscape(label), edge.To)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, class := range []struct {
+		name  string
+		nodes map[string]bool
+	}{
+		{"unreachable", r.Unreachable},
+		{"noWayBack", r.NoWayBack},
+		{"deadEnd", r.DeadEnds},
+	} {
+		if names := r.marked(class.nodes); len(names) > 0 {
+			fmt.Fprintf(&b, "  class %s %s\n", strings.Join(names, ","), class.name)
+		}
+	}
+
+	return b.String()
+}
+
+// nodeLines describes a screen: name, title, flags and usecases.
+func (r *GraphReport) nodeLines(screen config.GraphScreen) []string {
+	lines := []string{screen.Name}
+	if screen.Title != "" {
+		lines = append(lines, "title: "+screen.Title)
+	}
+	if screen.External {
+		lines = append(lines, "(external)")
+	}
+	if screen.Terminal {
+		lines = append(lines, "(terminal)")
+	}
+	if ucs := r.Usecases[screen.Name]; len(ucs) > 0 {
+		lines = append(lines, "usecases: "+strings.Join(ucs, ", "))
+	}
+	return lines
+}
+
+// marked returns screens flagged in nodes, in declaration order.
+func (r *GraphReport) marked(nodes map[string]bool) []string {
+	var names []string
+	for _, screen := range r.Graph.Screens {
+		if nodes[screen.Name] {
+			names = append(names, screen.Name)
+		}
+	}
+	return names
+}
+
+// edgeLines describes the steps of a transition.
+func edgeLines(edge config.GraphEdge) []string {
+	var lines []string
+	for _, step := range edge.Steps {
+		var line string
+		switch {
+		case step.Click != "":
+			line = "click: " + step.Click
+		case step.Swipe != "":
+			line = "swipe: " + step.Swipe
+		}
+		if step.Trigger != "" {
+			line += " if " + step.Trigger
+		}
+		lines = append(lines, line)
+	}
+	if edge.Cost > 1 {
+		lines = append(lines, fmt.Sprintf("cost: %d", edge.Cost))
+	}
+	return lines
+}
+
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
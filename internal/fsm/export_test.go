@@ -0,0 +1,81 @@
+package fsm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+const exportGraph = `
+screens:
+  - name: main_city
+    title: MainCity
+    edges:
+      - to: mail
+        steps: [{ click: to_mail }]
+      - to: vip
+        steps: [{ click: to_vip, trigger: vip.isActive }]
+  - name: mail
+    title: Mail
+    edges:
+      - to: main_city
+        cost: 2
+        steps: [{ click: mail_close }]
+  - name: vip
+    terminal: true
+  - name: chat
+    external: true
+    edges:
+      - to: main_city
+`
+
+func newExportReport(t *testing.T) *GraphReport {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fsmGraph.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(exportGraph), 0644))
+
+	graph, err := config.LoadFSMGraph(path, nil)
+	require.NoError(t, err)
+
+	return NewGraphReport(graph, []*domain.UseCase{
+		{Name: "Read Mail", Node: "mail"},
+		{Name: "Check Mail", Node: "mail"},
+	})
+}
+
+func TestGraphReport_Analysis(t *testing.T) {
+	r := newExportReport(t)
+
+	require.Equal(t, []string{"Check Mail", "Read Mail"}, r.Usecases["mail"])
+	require.True(t, r.Unreachable["chat"])
+	require.False(t, r.Unreachable["vip"])
+	require.True(t, r.DeadEnds["vip"])
+	require.True(t, r.NoWayBack["vip"])
+	require.False(t, r.NoWayBack["chat"])
+}
+
+func TestGraphReport_DOT(t *testing.T) {
+	dot := newExportReport(t).DOT()
+
+	require.Contains(t, dot, `"main_city" -> "vip" [label="click: to_vip if vip.isActive"];`)
+	require.Contains(t, dot, `"mail" -> "main_city" [label="click: mail_close\ncost: 2"];`)
+	require.Contains(t, dot, `"chat" -> "main_city" [label="", style=dotted];`)
+	require.Contains(t, dot, `"mail" [label="mail\ntitle: Mail\nusecases: Check Mail, Read Mail"];`)
+	require.Contains(t, dot, `"vip" [label="vip\n(terminal)", fillcolor="#ffc9c9", color="#e03131", penwidth=2];`)
+}
+
+func TestGraphReport_Mermaid(t *testing.T) {
+	mermaid := newExportReport(t).Mermaid()
+
+	require.Contains(t, mermaid, `main_city -->|"click: to_mail"| mail`)
+	require.Contains(t, mermaid, `chat -.-> main_city`)
+	require.Contains(t, mermaid, "class chat unreachable")
+	require.Contains(t, mermaid, "class vip noWayBack")
+	require.Contains(t, mermaid, "class vip deadEnd")
+}
@@ -6,13 +6,15 @@ import (
 	"log/slog"
 	"math/rand"
 	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 )
 
 var (
 	EventNotActive = fmt.Errorf("event not active")
 )
 
-func (g *GameFSM) ForceTo(target string, updateStateFromScreen func(ctx context.Context, screen string, filename string)) error {
+func (g *GameFSM) ForceTo(ctx context.Context, target string, updateStateFromScreen func(ctx context.Context, screen string)) error {
 	prev := g.Current()
 
 	// Save the previous state (before changing it)
@@ -23,88 +25,45 @@ func (g *GameFSM) ForceTo(target string, updateStateFromScreen func(ctx context.
 		return nil
 	}
 
-	var steps []TransitionStep
-	var path = []string{prev, target}
-	found := false
-
 	if g.adb != nil {
-		steps, found = transitionPaths[prev][target]
-		if !found {
-			path = g.FindPath(prev, target)
-			if len(path) > 1 {
-				g.logger.Warn("FSM path generated dynamically", slog.Any("path", path))
-				steps = g.pathToSteps(path)
-				g.logAutoPath(path)
-			} else {
-				panic(fmt.Sprintf("❌ FSM: no path found from '%s' to '%s'", prev, target))
-			}
+		graph := g.graph.Graph()
+
+		// the cheapest route wins, even over a direct but slow or flaky edge
+		path := g.FindPath(prev, target)
+		if len(path) < 2 {
+			panic(fmt.Sprintf("❌ FSM: no path found from '%s' to '%s'", prev, target))
+		}
+		if len(path) > 2 {
+			g.logger.Warn("FSM path generated dynamically", slog.Any("path", path))
+			g.logAutoPath(path)
 		}
 
-		for i, step := range steps {
-			// Check Trigger (CEL)
-			if step.Trigger != "" {
-				ok, err := g.triggerEvaluator.EvaluateTrigger(step.Trigger, g.gamerState)
-				if err != nil {
-					g.logger.Error("Trigger evaluation failed",
-						slog.String("trigger", step.Trigger),
-						slog.Any("error", err),
-					)
-					panic("Trigger evaluation failed")
-				}
-				if !ok {
-					g.logger.Info("Trigger condition not met, skipping step",
-						slog.String("trigger", step.Trigger),
-					)
+		for i := 0; i < len(path)-1; i++ {
+			from, expected := path[i], path[i+1]
 
-					return EventNotActive
-				}
+			steps, found := g.transitionSteps(graph, from, expected)
+			if !found {
+				panic(fmt.Sprintf("❌ FSM: direct transition from '%s' to '%s' not defined", from, expected))
 			}
 
-			// Check conditions for click
-			if step.Click != "" {
-				if _, ok := g.lookup.Get(step.Click); !ok {
-					panic(fmt.Sprintf("❌ Region '%s' not found in area.json", step.Click))
-				}
-
-				g.logger.Info("Clicking region", slog.String("click", step.Click))
-
-				if err := g.adb.ClickRegion(step.Click, g.lookup); err != nil {
-					panic(fmt.Sprintf("❌ ADB click failed for action '%s': %v", step.Click, err))
-				}
+			started := time.Now()
+			checked, err := g.runSteps(ctx, steps)
+			if err != nil {
+				return err
 			}
 
-			// Check conditions for swipe
-			if step.Swipe != nil {
-				g.logger.Info("Swiping",
-					slog.Int("x1", step.Swipe.X1), slog.Int("y1", step.Swipe.Y1),
-					slog.Int("x2", step.Swipe.X2), slog.Int("y2", step.Swipe.Y2),
-					slog.Duration("wait", step.Wait),
-				)
-
-				if err := g.adb.Swipe(step.Swipe.X1, step.Swipe.Y1, step.Swipe.X2, step.Swipe.Y2, step.Wait); err != nil {
-					panic(fmt.Sprintf("❌ ADB swipe failed for action '%s': %v", step.Swipe, err))
-				}
-			}
-
-			// SKIP state check if this is a swipe
-			if step.Swipe != nil {
-				time.Sleep(step.Wait)
+			// no click on this edge (swipes or a free transition) or no way to recognize
+			// the screen (neither title nor identify signals) – nothing to verify
+			if !checked || !recognizable(graph, expected) {
+				g.confirmState(ctx, expected)
 				continue
 			}
 
-			wait := step.Wait + time.Duration(rand.Intn(300)+700)*time.Millisecond
-			g.logger.Info("Waiting after action", slog.String("click", step.Click), slog.Duration("wait", wait))
-			time.Sleep(wait)
-
-			expected := target
-			if i+1 < len(path) {
-				expected = path[i+1]
-			}
-
-			actual, errCheckState := g.ExpectState(expected)
+			actual, errCheckState := g.ExpectState(ctx, expected)
 			if errCheckState != nil {
+				g.recordEdge(from, expected, time.Since(started), false)
 				g.logger.Error("❌ Error checking state after action",
-					slog.String("click", step.Click),
+					slog.String("from", from),
 					slog.String("expected", expected),
 					slog.String("actual", actual),
 					slog.Any("error", errCheckState),
@@ -113,46 +72,33 @@ func (g *GameFSM) ForceTo(target string, updateStateFromScreen func(ctx context.
 			}
 
 			if actual != expected {
+				g.recordEdge(from, expected, time.Since(started), false)
 				g.logger.Warn("⚠️ State mismatch detected after action",
-					slog.String("click", step.Click),
+					slog.String("from", from),
 					slog.String("expected", expected),
 					slog.String("actual", actual),
 				)
 
 				// fix actual state immediately in FSM and player state!
-				g.fsm.SetState(actual)
-				g.gamerState.ScreenState.CurrentState = actual
+				g.confirmState(ctx, actual)
 
 				// try to build path to target from current position
-				return g.ForceTo(target, updateStateFromScreen)
+				return g.ForceTo(ctx, target, updateStateFromScreen)
 			}
 
 			// Successful step: synchronize FSM and player state
-			g.fsm.SetState(actual)
-			g.gamerState.ScreenState.CurrentState = actual
+			g.recordEdge(from, expected, time.Since(started), true)
+			g.confirmState(ctx, actual)
 
-			// --- callback & screenshot -----------------------------------------------
+			// --- callback -------------------------------------------------------------
 			if g.callback != nil {
 				if updateStateFromScreen != nil {
-					updateStateFromScreen(
-						context.Background(),
-						actual,
-						fmt.Sprintf(
-							"out/bot_%s_%s.png",
-							g.gamerState.Nickname,
-							target,
-						),
-					)
+					updateStateFromScreen(ctx, actual)
 				}
 
-				next := target
-				if i+1 < len(path) {
-					next = path[i+1]
-				}
 				g.logger.Info("FSM state confirmed, next planned",
 					slog.String("current", actual),
-					slog.String("next", next),
-					slog.String("step", step.Click),
+					slog.String("target", target),
 				)
 			}
 		}
@@ -160,7 +106,7 @@ func (g *GameFSM) ForceTo(target string, updateStateFromScreen func(ctx context.
 
 	// final synchronization
 	eventName := fmt.Sprintf("%s_to_%s", prev, target)
-	if err := g.fsm.Event(context.Background(), eventName); err != nil {
+	if err := g.fsm.Event(ctx, eventName); err != nil {
 		// If event is not defined, force state change everywhere!
 		g.fsm.SetState(target)
 		g.logger.Warn("FSM forcefully moved to new state",
@@ -170,7 +116,83 @@ func (g *GameFSM) ForceTo(target string, updateStateFromScreen func(ctx context.
 	}
 
 	// In any case, after FSM transition (or manual SetState) — synchronize gamerState:
-	g.gamerState.ScreenState.CurrentState = target
+	g.confirmState(ctx, target)
 
 	return nil
 }
+
+// recognizable reports whether the classifier can tell the screen: it needs a title or identify signals.
+func recognizable(graph *config.FSMGraph, name string) bool {
+	screen, ok := graph.Screen(name)
+	return ok && (screen.Title != "" || screen.Identify != nil)
+}
+
+// runSteps executes the steps of a single transition.
+// It reports whether a click was made, i.e. whether the resulting screen has to be verified.
+func (g *GameFSM) runSteps(ctx context.Context, steps []TransitionStep) (bool, error) {
+	clicked := false
+
+	for _, step := range steps {
+		// Check Trigger (CEL)
+		if step.Trigger != "" {
+			ok, err := g.triggerEvaluator.EvaluateTrigger(step.Trigger, g.gamerState)
+			if err != nil {
+				g.logger.Error("Trigger evaluation failed",
+					slog.String("trigger", step.Trigger),
+					slog.Any("error", err),
+				)
+				panic("Trigger evaluation failed")
+			}
+			if !ok {
+				g.logger.Info("Trigger condition not met, skipping step",
+					slog.String("trigger", step.Trigger),
+				)
+
+				return false, EventNotActive
+			}
+		}
+
+		// Check conditions for click
+		if step.Click != "" {
+			if _, ok := g.lookup.Get(step.Click); !ok {
+				panic(fmt.Sprintf("❌ Region '%s' not found in area.json", step.Click))
+			}
+
+			g.logger.Info("Clicking region", slog.String("click", step.Click))
+
+			if err := g.adb.ClickRegion(ctx, step.Click, g.lookup); err != nil {
+				panic(fmt.Sprintf("❌ ADB click failed for action '%s': %v", step.Click, err))
+			}
+		}
+
+		// Check conditions for swipe
+		if step.Swipe != nil {
+			// presets are in reference coordinates
+			scale := g.lookup.Scale()
+			x1, y1 := scale.Point(step.Swipe.X1, step.Swipe.Y1)
+			x2, y2 := scale.Point(step.Swipe.X2, step.Swipe.Y2)
+
+			g.logger.Info("Swiping",
+				slog.Int("x1", x1), slog.Int("y1", y1),
+				slog.Int("x2", x2), slog.Int("y2", y2),
+				slog.Duration("wait", step.Wait),
+			)
+
+			if err := g.adb.Swipe(ctx, x1, y1, x2, y2, step.Wait); err != nil {
+				panic(fmt.Sprintf("❌ ADB swipe failed for action '%v': %v", *step.Swipe, err))
+			}
+
+			// SKIP state check if this is a swipe
+			time.Sleep(step.Wait)
+			continue
+		}
+
+		wait := step.Wait + time.Duration(rand.Intn(300)+700)*time.Millisecond
+		g.logger.Info("Waiting after action", slog.String("click", step.Click), slog.Duration("wait", wait))
+		time.Sleep(wait)
+
+		clicked = true
+	}
+
+	return clicked, nil
+}
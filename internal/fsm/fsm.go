@@ -1,8 +1,8 @@
 package fsm
 
 import (
+	"container/heap"
 	"context"
-	"fmt"
 	"log/slog"
 	"time"
 
@@ -11,33 +11,22 @@ import (
 
 	"github.com/batazor/whiteout-survival-autopilot/internal/adb"
 	"github.com/batazor/whiteout-survival-autopilot/internal/analyzer"
+	"github.com/batazor/whiteout-survival-autopilot/internal/classifier"
 	"github.com/batazor/whiteout-survival-autopilot/internal/config"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
 	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
 )
 
-func init() {
-	mergeTransitions(transitionPaths, tundraAdventureTransitionPaths)
-	mergeTransitions(transitionPaths, mainMenuTransitionPaths)
-	mergeTransitions(transitionPaths, troopsTransitionPaths)
-}
-
-func buildFSMGraph() map[string][]string {
-	// 🧼 Complete reset to avoid remnants from other FSMs
-	fsmGraph := map[string][]string{}
-
-	for from, targets := range transitionPaths {
-		for to := range targets {
-			fsmGraph[from] = append(fsmGraph[from], to)
-		}
-	}
+type StateUpdateCallback interface{}
 
-	return fsmGraph
+// stateAnalyzer reads the title signals of the current screen (implemented by analyzer.Analyzer).
+type stateAnalyzer interface {
+	AnalyzeAndUpdateState(ctx context.Context, state *domain.Gamer, rules []domain.AnalyzeRule, queue *redis_queue.Queue) (*domain.Gamer, error)
 }
 
-type StateUpdateCallback interface{}
-
 // TransitionStep describes one FSM transition step between screens.
 type TransitionStep struct {
 	Click   string        // Region name for click (leave empty if not needed)
@@ -61,534 +50,31 @@ type Swipe struct {
 	Delta          int    // Смещение в пикселях для Direction
 }
 
-var transitionPaths = map[string]map[string][]TransitionStep{
-	state.StateMainCity: {
-		state.StateExploration: {
-			{Click: "to_exploration", Wait: 300 * time.Millisecond},
-		},
-		//StateEvents:         {{Click: "to_events", Wait: 300 * time.Millisecond}},
-		//StateProfile:        {{Click: "to_profile", Wait: 300 * time.Millisecond}},
-		//StateLeaderboard:    {{Click: "to_leaderboard", Wait: 300 * time.Millisecond}},
-		//StateSettings:       {{Click: "to_settings", Wait: 300 * time.Millisecond}},
-		//StateVIP:            {{Click: "to_vip", Wait: 300 * time.Millisecond}},
-		//StateChiefOrders:    {{Click: "to_chief_orders", Wait: 300 * time.Millisecond}},
-		//StateDawnMarket:     {{Click: "to_dawn_market", Wait: 300 * time.Millisecond}},
-		state.StateAllianceManage: {
-			{Click: "to_alliance_manage", Wait: 300 * time.Millisecond},
-		},
-		//StateAllianceSettings: {
-		//	{Click: "to_alliance_manage", Wait: 300 * time.Millisecond},
-		//	{Click: "to_alliance_settings", Wait: 300 * time.Millisecond},
-		//},
-		state.StateChiefProfile: {
-			{Click: "to_chief_profile", Wait: 300 * time.Millisecond},
-		},
-		state.StateMainMenuCity: {
-			{Click: "to_main_menu_city", Wait: 300 * time.Millisecond},
-		},
-		state.StatePets: {
-			{Click: "to_pets", Wait: 300 * time.Millisecond},
-		},
-		state.StateWorld: {
-			{Click: "to_world", Wait: 300 * time.Millisecond},
-		},
-		state.StateMail: {
-			{Click: "to_mail", Wait: 300 * time.Millisecond},
-		},
-		state.StateTundraAdventure: {
-			{
-				Click:   "events.tundraAdventure.state.isExist",
-				Wait:    300 * time.Millisecond,
-				Trigger: "events.tundraAdventure.state.isExist",
-			},
-		},
-		state.StateVIP: {
-			{Click: "to_vip", Wait: 300 * time.Millisecond},
-		},
-		state.StateChiefOrders: {
-			{Click: "to_chief_orders", Wait: 300 * time.Millisecond},
-		},
-		state.StateDailyMissions: {
-			{Click: "to_daily_missions", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMainMenuWilderness: {
-		state.StateMainMenuCity: {
-			{Click: "to_main_menu_city", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfile: {
-		state.StateChiefProfileSetting: {
-			{Click: "to_chief_profile_setting", Wait: 300 * time.Millisecond},
-		},
-		state.StateMainCity: {
-			{Click: "chief_profile_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfileSetting: {
-		state.StateChiefProfileAccount: {
-			{Click: "to_chief_profile_account", Wait: 300 * time.Millisecond},
-		},
-		state.StateChiefCharacters: {
-			{Click: "to_chief_characters", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfileAccount: {
-		state.StateChiefProfileAccountChangeAccount: {
-			{Click: "to_change_account", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfileAccountChangeAccount: {
-		state.StateChiefProfileAccountChangeGoogle: {
-			{Click: "to_google_account", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfileAccountChangeGoogle: {
-		state.StateChiefProfileAccountChangeGoogleConfirm: {
-			{Click: "to_google_continue", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefProfileAccountChangeGoogleConfirm: {},
-	state.StateChiefCharacters:                        {},
-	//StateEvents: {
-	//	state.StateActivityTriumph: {{Click: "to_activity_triumph", Wait: 300 * time.Millisecond}},
-	//},
-	state.StateAllianceManage: {
-		state.StateAllianceTech: {
-			{Click: "to_alliance_tech", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceRanking:  {{Click: "to_alliance_power_rankings", Wait: 300 * time.Millisecond}},
-		state.StateAllianceSettings: {{Click: "to_alliance_settings", Wait: 300 * time.Millisecond}},
-		state.StateMainCity: {
-			{Click: "to_alliance_back", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceWar: {
-			{Click: "to_alliance_war", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceChests: {
-			{Click: "to_alliance_chests", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceTerritory: {
-		state.StateAllianceManage: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceChests: {
-		state.StateAllianceChestLoot: {
-			{Click: "to_alliance_chest_loot", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceChestGift: {
-			{Click: "to_alliance_chest_gift", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceManage: {
-			{Click: "to_alliance_manager_from_alliance_chest", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceChestLoot: {
-		state.StateAllianceManage: {
-			{Click: "to_alliance_manager_from_alliance_chest", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceChestGift: {
-			{Click: "to_alliance_chest_gift", Wait: 100 * time.Millisecond},
-		},
-	},
-	state.StateAllianceChestGift: {
-		state.StateAllianceManage: {
-			{Click: "to_alliance_manager_from_alliance_chest", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceChestLoot: {
-			{Click: "to_alliance_chest_loot", Wait: 100 * time.Millisecond},
-		},
-	},
-	state.StateAllianceTech: {
-		state.StateAllianceManage: {
-			{Click: "from_tech_to_alliance", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateExploration: {
-		state.StateExplorationBattle: {{Click: "to_exploration_battle", Wait: 300 * time.Millisecond}},
-		state.StateMainCity:          {{Click: "exploration_back", Wait: 300 * time.Millisecond}},
-	},
-	state.StateExplorationBattle: {
-		state.StateExploration: {{Click: "to_exploration_battle_back", Wait: 300 * time.Millisecond}},
-	},
-	state.StateTopUpCenter: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceWar: {
-		state.StateAllianceWarRally: {
-			{Click: "to_alliance_war_rally", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceWarSolo: {
-			{Click: "to_alliance_war_solo", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceWarEvents: {
-			{Click: "to_alliance_war_events", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceManage: {
-			{Click: "from_war_to_alliance_manage", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceWarRally: {
-		state.StateAllianceWarRallyAutoJoin: {
-			{Click: "to_alliance_war_auto_join", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceWar: {},
-		state.StateAllianceWarSolo: {
-			{Click: "to_alliance_war_solo", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceWarEvents: {
-			{Click: "to_alliance_war_events", Wait: 300 * time.Millisecond},
-		},
-		state.StateAllianceManage: {
-			{Click: "from_war_to_alliance_manage", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateAllianceWarRallyAutoJoin: {
-		state.StateAllianceWar: {
-			{Click: "alliance_war_auto_join_close", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateWorld: {
-		state.StateMainCity: {
-			{Click: "to_main_city", Wait: 300 * time.Millisecond},
-		},
-		state.StateWorldSearch: {
-			{Click: "to_search_resources", Wait: 300 * time.Millisecond},
-		},
-		state.StateWorldGlobalMap: {
-			{Click: "to_global_map", Wait: 300 * time.Millisecond},
-		},
-		state.StateMail: {
-			{Click: "to_mail", Wait: 300 * time.Millisecond},
-		},
-		state.StateHealInjured: {
-			{
-				Click:   "healInjured.state.isAvailable",
-				Wait:    300 * time.Millisecond,
-				Trigger: "healInjured.state.isAvailable",
-			},
-		},
-	},
-	state.StateMail: {
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMailWars: {
-		state.StateMail: {},
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMailAlliance: {
-		state.StateMail: {},
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMailSystem: {
-		state.StateMail: {},
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMailReports: {
-		state.StateMail: {},
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateMailStarred: {
-		state.StateMail: {},
-		state.StateMainCity: {
-			{Click: "mail_close", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailWars: {
-			{Click: "to_mail_wars", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailAlliance: {
-			{Click: "to_mail_alliance", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailSystem: {
-			{Click: "to_mail_system", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailReports: {
-			{Click: "to_mail_reports", Wait: 300 * time.Millisecond},
-		},
-		state.StateMailStarred: {
-			{Click: "to_mail_starred", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateVIP: {
-		state.StateMainCity: {
-			{Click: "from_vip_to_main_city", Wait: 300 * time.Millisecond},
-		},
-		state.StateVIPAdd: {
-			{Click: "to_vip_add", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateVIPAdd: {
-		state.StateVIP: {
-			{Click: "from_vip_add_to_vip", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChiefOrders: {
-		state.StateMainCity: {
-			{Click: "from_chief_orders_to_main_city", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateDailyMissions: {
-		state.StateMainCity: {
-			{
-				Click: "from_daily_missions_to_main_city",
-				Wait:  300 * time.Millisecond,
-			},
-		},
-		state.StateGrowthMissions: {
-			{Click: "to_growth_missions", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateGrowthMissions: {
-		state.StateMainCity: {
-			{Click: "from_growth_missions_to_main_city", Wait: 300 * time.Millisecond},
-		},
-		state.StateDailyMissions: {
-			{Click: "from_growth_missions_to_daily_missions", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpack: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpackResources: {
-		state.StateBackpack: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpackSpeedups: {
-		state.StateBackpack: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpackBonus: {
-		state.StateBackpack: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpackGear: {
-		state.StateBackpack: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateBackpackOther: {
-		state.StateBackpack: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChat: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChatAlliance: {
-		state.StateChat: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChatWorld: {
-		state.StateChat: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateChatPersonal: {
-		state.StateChat: {},
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateHeroes: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateNatalia: {
-		state.StateHeroes: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateEvents: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateDeals: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateIntel: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateArenaCityView: {
-		state.StateMainCity: {},
-		state.StateMainMenuCity: {
-			{Click: "to_main_menu_city", Wait: 300 * time.Millisecond},
-		},
-		state.StateArenaMain: {
-			{Click: "to_arena_main", Wait: 2 * time.Second},
-		},
-	},
-	state.StateArenaMain: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-		state.StateArenaDefensiveSquadLineup: {
-			{Click: "to_arena_defensive_squad_lineup", Wait: 300 * time.Millisecond},
-		},
-		state.StateArenaChallengeList: {
-			{Click: "to_arena_challenge_list", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateArenaDefensiveSquadLineup: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateHealInjured: {
-		state.StateWorld: {
-			{Click: "from_heal_injured_to_world", Wait: 300 * time.Millisecond},
-		},
-		state.StateMainCity: {
-			{Click: "to_main_city", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateActivityTriumph: {
-		state.StateAllianceManage: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateLabyrinth: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateCaveOfMonsters: {
-		state.StateLabyrinth: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-	state.StateEnlistmentOffice: {
-		state.StateMainCity: {
-			{Click: "page_back", Wait: 300 * time.Millisecond},
-		},
-	},
-}
-
 type GameFSM struct {
 	fsm              *lpfsm.FSM
-	analyzer         *analyzer.Analyzer
+	analyzer         stateAnalyzer
+	classifier       *classifier.Classifier
 	logger           *slog.Logger
 	onStateChange    func(state string)
 	callback         StateUpdateCallback
 	gamerState       *domain.Gamer
 	adb              adb.DeviceController
 	lookup           *config.AreaLookup
-	fsmGraph         map[string][]string
+	graph            *config.FSMGraphLoader
 	triggerEvaluator config.TriggerEvaluator
 	rulesCheckState  config.ScreenAnalyzeRules
 	OCRClient        *ocrclient.Client
 
 	// previousState stores the previous FSM state
 	previousState string
+
+	// screenRepo persists confirmed screens per device (optional)
+	screenRepo repository.ScreenRepository
+	deviceID   string
+
+	// edgeStats – learned duration and reliability of transitions, used as path weights
+	edgeStats     edgeStatsCache
+	edgeStatsRepo repository.EdgeStatsRepository
 }
 
 func NewGame(
@@ -612,6 +98,14 @@ func NewGame(
 		panic("Failed to load analyze rules")
 	}
 
+	// ─── Transition graph (shared, hot-reloaded) ─────────────
+	graph, err := config.DefaultFSMGraph()
+	if err != nil {
+		logger.Error("Failed to load FSM graph", slog.Any("error", err))
+
+		panic("Failed to load FSM graph")
+	}
+
 	// Start from main screen
 	if gamerState != nil {
 		gamerState.ScreenState.CurrentState = state.StateMainCity
@@ -621,11 +115,13 @@ func NewGame(
 		logger:           logger,
 		adb:              adb,
 		lookup:           lookup,
-		fsmGraph:         buildFSMGraph(),
+		graph:            graph,
 		triggerEvaluator: triggerEvaluator,
 		gamerState:       gamerState,
 		rulesCheckState:  rulesCheckState,
 		analyzer:         analyzer.NewAnalyzer(lookup, logger, OCRClient),
+		classifier:       classifier.New(lookup, logger),
+		OCRClient:        OCRClient,
 	}
 
 	transitions := lpfsm.Events{}
@@ -679,108 +175,88 @@ func (g *GameFSM) logAutoPath(path []string) {
 	}
 }
 
+// ValidateTransitionActions checks that every clicked region of the graph exists in the device area lookup.
 func (g *GameFSM) ValidateTransitionActions() {
-	missing := make(map[string][]string)
-
-	for from, targets := range transitionPaths {
-		for to, steps := range targets {
-			for _, step := range steps {
-				switch {
-				case step.Click == "" && step.Swipe == nil:
-					missing[from] = append(missing[from], fmt.Sprintf("%s → %s: missing Click and Swipe", from, to))
-				case step.Click != "" && step.Swipe == nil:
-					if _, ok := g.lookup.Get(step.Click); !ok {
-						missing[from] = append(missing[from], fmt.Sprintf("%s → %s: '%s'", from, to, step.Click))
-					}
-				}
-			}
-		}
+	if err := g.graph.Graph().Validate(g.lookup); err != nil {
+		panic("❌ FSM graph doesn't match area.json: " + err.Error())
 	}
+}
 
-	if len(missing) == 0 {
-		return
+// transitionSteps returns the steps of a direct transition from -> to.
+func (g *GameFSM) transitionSteps(graph *config.FSMGraph, from, to string) ([]TransitionStep, bool) {
+	edge, ok := graph.Edge(from, to)
+	if !ok {
+		return nil, false
 	}
 
-	errMsg := "❌ Missing required region definitions in area.json:\n"
-	for _, issues := range missing {
-		for _, entry := range issues {
-			errMsg += " - " + entry + "\n"
+	steps := make([]TransitionStep, 0, len(edge.Steps))
+	for _, step := range edge.Steps {
+		ts := TransitionStep{
+			Click:   step.Click,
+			Wait:    step.Wait,
+			Trigger: step.Trigger,
+		}
+		if preset, ok := graph.Swipes[step.Swipe]; ok {
+			ts.Swipe = &Swipe{X1: preset.X1, Y1: preset.Y1, X2: preset.X2, Y2: preset.Y2}
 		}
+		steps = append(steps, ts)
 	}
-	panic(errMsg)
+
+	return steps, true
 }
 
 // cost returns the cost of an edge between states.
-// If a direct transition from -> to is defined, the cost is 1,
+// If a direct transition from -> to is defined, its cost from the graph is used,
 // otherwise – a fallback with cost 2 is assumed.
-func cost(from, to string) int {
-	if _, ok := transitionPaths[from][to]; ok {
-		return 1
+func cost(graph *config.FSMGraph, from, to string) int {
+	if edge, ok := graph.Edge(from, to); ok {
+		return edge.Cost
 	}
 	return 2
 }
 
-// FindPath finds the shortest path (by total edge cost) from state 'from' to 'to'
-// using Dijkstra's algorithm.
+// FindPath finds the cheapest path from state 'from' to 'to' using Dijkstra's algorithm
+// with a binary heap. Edge weights are learned from executed transitions (see edgeWeight).
 func (g *GameFSM) FindPath(from, to string) []string {
-	// Collect all states: graph keys and their neighbors.
-	nodes := make(map[string]bool)
-	for state, neighbors := range g.fsmGraph {
-		nodes[state] = true
-		for _, n := range neighbors {
-			nodes[n] = true
-		}
-	}
+	graph := g.graph.Graph()
 
-	const inf = int(^uint(0) >> 1)
-	dist := make(map[string]int)
+	dist := map[string]float64{from: 0}
 	prev := make(map[string]string)
-	for node := range nodes {
-		dist[node] = inf
-	}
-	dist[from] = 0
-
-	// Set of unvisited vertices.
-	unvisited := make(map[string]bool)
-	for node := range nodes {
-		unvisited[node] = true
-	}
-
-	for len(unvisited) > 0 {
-		var u string
-		minDist := inf
-		for node := range unvisited {
-			if d := dist[node]; d < minDist {
-				minDist = d
-				u = node
-			}
-		}
-		if u == "" {
-			break // no reachable vertices left
+	visited := make(map[string]bool)
+
+	queue := &pathQueue{{state: from}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		u := item.state
+		if visited[u] {
+			continue // stale queue entry
 		}
+		visited[u] = true
+
 		if u == to {
 			break // reached target state
 		}
-		delete(unvisited, u)
-		for _, v := range g.fsmGraph[u] {
-			if !unvisited[v] {
+
+		for _, v := range graph.Neighbors(u) {
+			if visited[v] {
 				continue
 			}
-			alt := dist[u] + cost(u, v)
-			if alt < dist[v] {
+			alt := item.dist + g.edgeWeight(graph, u, v)
+			if d, ok := dist[v]; !ok || alt < d {
 				dist[v] = alt
 				prev[v] = u
+				heap.Push(queue, pathItem{state: v, dist: alt})
 			}
 		}
 	}
 
-	if dist[to] == inf {
+	if !visited[to] {
 		return nil // path not found
 	}
 
 	// Reconstruct path
 	var path []string
-	for u := to; u != ""; u = prev[u] {
+	for u := to; ; u = prev[u] {
 		path = append([]string{u}, path...)
 		if u == from {
 			break
@@ -789,28 +265,22 @@ func (g *GameFSM) FindPath(from, to string) []string {
 	return path
 }
 
-func (g *GameFSM) pathToSteps(path []string) []TransitionStep {
-	var steps []TransitionStep
-	for i := 0; i < len(path)-1; i++ {
-		from := path[i]
-		to := path[i+1]
-		if s, ok := transitionPaths[from][to]; ok {
-			steps = append(steps, s...)
-		} else {
-			// No direct transition – throw error
-			panic(fmt.Sprintf("❌ FSM: direct transition from '%s' to '%s' not defined", from, to))
-		}
-	}
-	return steps
+// pathItem is a queued state with its tentative distance.
+type pathItem struct {
+	state string
+	dist  float64
 }
 
-func mergeTransitions(dst, src map[string]map[string][]TransitionStep) {
-	for from, targets := range src {
-		if _, ok := dst[from]; !ok {
-			dst[from] = make(map[string][]TransitionStep)
-		}
-		for to, steps := range targets {
-			dst[from][to] = steps
-		}
-	}
+// pathQueue is a min-heap of states ordered by distance.
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
 }
@@ -1,6 +1,7 @@
 package fsm_test
 
 import (
+	"context"
 	"image"
 	"log/slog"
 	"os"
@@ -11,33 +12,60 @@ import (
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
 	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
 )
 
+func TestMain(m *testing.M) {
+	// NewGame reads the shared references relative to the repository root
+	_ = os.Setenv("PATH_TO_FSM_GRAPH", "../../references/fsmGraph.yaml")
+	_ = os.Setenv("PATH_TO_AREA", "../../references/area.json")
+	_ = os.Setenv("PATH_TO_FSM_STATE_RULES", "../../references/fsmState.yaml")
+
+	os.Exit(m.Run())
+}
+
 // FakeADB implements the adb.DeviceController interface and records ClickRegion calls.
 type FakeADB struct {
 	Clicks []string
 }
 
-func (f *FakeADB) ClickOCRResult(result *domain.OCRResult) error {
+func (f *FakeADB) ClickOCRResult(_ context.Context, result *domain.OCRResult) error {
+	panic("not implemented")
+}
+func (f *FakeADB) Swipe(_ context.Context, x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error {
 	panic("not implemented")
 }
-func (f *FakeADB) Swipe(x1 int, y1 int, x2 int, y2 int, durationMs time.Duration) error {
+func (f *FakeADB) SwipeDirection(_ context.Context, direction string, delta int, durationMs time.Duration) error {
 	panic("not implemented")
 }
-func (f *FakeADB) ClickRegion(name string, lookup *config.AreaLookup) error {
+func (f *FakeADB) ClickRegion(_ context.Context, name string, lookup *config.AreaLookup) error {
 	f.Clicks = append(f.Clicks, name)
 	return nil
 }
-func (f *FakeADB) ListDevices() ([]string, error) { return []string{"fake"}, nil }
-func (f *FakeADB) SetActiveDevice(serial string)  {}
-func (f *FakeADB) GetActiveDevice() string        { return "fake" }
-func (f *FakeADB) RestartApplication() error {
+func (f *FakeADB) ListDevices(context.Context) ([]string, error) { return []string{"fake"}, nil }
+func (f *FakeADB) SetActiveDevice(serial string)                 {}
+func (f *FakeADB) GetActiveDevice() string                       { return "fake" }
+func (f *FakeADB) RestartApplication(context.Context) error {
 	return nil
 }
-func (f *FakeADB) Click(region image.Rectangle) error {
+func (f *FakeADB) Click(_ context.Context, region image.Rectangle) error {
 	return nil
 }
 
+// clickAnalyzer reports the title of the screen opened by the last click of FakeADB.
+type clickAnalyzer struct {
+	adb    *FakeADB
+	titles map[string]string
+}
+
+func (a clickAnalyzer) AnalyzeAndUpdateState(_ context.Context, gamer *domain.Gamer, _ []domain.AnalyzeRule, _ *redis_queue.Queue) (*domain.Gamer, error) {
+	out := *gamer
+	if n := len(a.adb.Clicks); n > 0 {
+		out.ScreenState.TitleFact = a.titles[a.adb.Clicks[n-1]]
+	}
+	return &out, nil
+}
+
 func TestForceTo(t *testing.T) {
 	lookup, err := config.LoadAreaReferences("../../references/area.json")
 	if err != nil {
@@ -67,10 +95,15 @@ func TestForceTo(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create FSM; initial state – StateMainCity.
 			gameFSM := fsm.NewGame(logger, fakeADB, lookup, nil, nil, nil)
+			gameFSM.SetAnalyzer(clickAnalyzer{adb: fakeADB, titles: map[string]string{
+				"to_chief_profile":         "Chief Profile",
+				"to_chief_profile_setting": "Settings",
+				"to_chief_profile_account": "Account",
+			}})
 
 			// Reset accumulated clicks before each test.
 			fakeADB.Clicks = nil
-			gameFSM.ForceTo(tc.target, nil)
+			gameFSM.ForceTo(context.Background(), tc.target, nil)
 
 			if len(fakeADB.Clicks) != len(tc.expectedClicks) {
 				t.Errorf("expected %d clicks, got %d: %v", len(tc.expectedClicks), len(fakeADB.Clicks), fakeADB.Clicks)
@@ -0,0 +1,18 @@
+package fsm
+
+import "time"
+
+// SetAnalyzer replaces the screen analyzer, so tests don't need an OCR service.
+func (g *GameFSM) SetAnalyzer(a stateAnalyzer) {
+	g.analyzer = a
+}
+
+// SetLocateBackWait shortens the wait of Locate after navigating back; the returned func restores it.
+func SetLocateBackWait(d time.Duration) (restore func()) {
+	prev := locateBackWait
+	locateBackWait = d
+	return func() { locateBackWait = prev }
+}
+
+// LocateBackRegion is the region Locate taps to leave an unknown screen.
+const LocateBackRegion = locateBackRegion
@@ -0,0 +1,215 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/classifier"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/repository"
+)
+
+const (
+	// maxLocateAttempts – how many times Locate navigates back before giving up.
+	maxLocateAttempts = 4
+
+	// locateBackRegion – region clicked to leave an unrecognized screen.
+	locateBackRegion = "page_back"
+)
+
+// locateBackWait – how long Locate lets the previous screen open after navigating back.
+var locateBackWait = time.Second
+
+// ErrUnknownScreen is returned when the current screen can't be identified.
+var ErrUnknownScreen = classifier.ErrUnknownScreen
+
+// SetScreenRepository enables persisting of every confirmed screen for the device.
+func (g *GameFSM) SetScreenRepository(deviceID string, repo repository.ScreenRepository) {
+	g.deviceID = deviceID
+	g.screenRepo = repo
+}
+
+// DetectState identifies the current screen: the title rules from fsmState.yaml select the group,
+// the identify signals of fsmGraph.yaml tell sub-screens apart.
+// Candidates that score the same are reported as ErrUnknownScreen: the hint (the expected
+// or last persisted screen) must not confirm a screen the signals can't tell apart.
+func (g *GameFSM) DetectState(ctx context.Context, hint string) (string, error) {
+	gamer := g.gamerState
+	if gamer == nil {
+		gamer = &domain.Gamer{}
+	}
+
+	analyzed, err := g.analyzer.AnalyzeAndUpdateState(ctx, gamer, g.titleRules(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	signals := classifier.Signals{
+		Title:    analyzed.ScreenState.TitleFact,
+		Family:   analyzed.ScreenState.IsMainCity,
+		Language: gamer.Language,
+	}
+	if g.OCRClient != nil {
+		signals.OCR = func() (domain.OCRResults, error) {
+			return g.OCRClient.FetchOCR(ctx, "classifier", nil)
+		}
+		signals.Icons = g.OCRClient
+	}
+
+	result, err := g.classifier.Classify(ctx, g.graph.Graph(), signals, hint)
+
+	g.logger.Debug("FSM: detect state",
+		slog.String("ocr_title", signals.Title),
+		slog.String("ocr_family", signals.Family),
+		slog.String("hint", hint),
+		slog.String("language", signals.Language),
+		slog.String("screen", result.Screen),
+		slog.Float64("confidence", result.Confidence),
+		slog.Bool("ambiguous", result.Ambiguous),
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	// the hint only orders equal candidates, it is no proof of the screen
+	if result.Ambiguous {
+		return "", fmt.Errorf("%w: %s and %s can't be told apart",
+			ErrUnknownScreen, result.Candidates[0].Screen, result.Candidates[1].Screen)
+	}
+
+	return result.Screen, nil
+}
+
+// Locate determines the actual screen, e.g. after a crash or restart mid-navigation.
+// It starts from the last persisted screen and, while the screen can't be recognized,
+// navigates back until a known anchor screen shows up.
+func (g *GameFSM) Locate(ctx context.Context) (string, error) {
+	hint := g.loadScreen(ctx)
+
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
+		current, err := g.DetectState(ctx, hint)
+		if err == nil {
+			g.logger.Info("📍 FSM located current screen",
+				slog.String("state", current),
+				slog.String("persisted", hint),
+				slog.Int("attempt", attempt),
+			)
+			g.confirmState(ctx, current)
+			return current, nil
+		}
+
+		if !errors.Is(err, ErrUnknownScreen) {
+			return "", err
+		}
+
+		if attempt >= maxLocateAttempts {
+			return "", err
+		}
+
+		g.logger.Warn("FSM: screen not recognized, navigating back",
+			slog.Int("attempt", attempt+1),
+			slog.Any("error", err),
+		)
+
+		if g.adb == nil {
+			return "", err
+		}
+
+		if errClick := g.adb.ClickRegion(ctx, locateBackRegion, g.lookup); errClick != nil {
+			return "", fmt.Errorf("navigate back: %w", errClick)
+		}
+
+		timer := time.NewTimer(locateBackWait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+
+		// after leaving the screen the persisted hint is no longer relevant
+		hint = ""
+	}
+}
+
+// Resume restores the FSM position on startup.
+// If the screen can't be located, the FSM stays at main_city as before.
+func (g *GameFSM) Resume(ctx context.Context) string {
+	current, err := g.Locate(ctx)
+	if err != nil {
+		g.logger.Warn("FSM: failed to locate current screen, assuming main city", slog.Any("error", err))
+		return g.Current()
+	}
+
+	return current
+}
+
+// confirmState synchronizes the FSM and player state and persists the confirmed screen.
+func (g *GameFSM) confirmState(ctx context.Context, current string) {
+	g.fsm.SetState(current)
+	if g.gamerState != nil {
+		g.gamerState.ScreenState.CurrentState = current
+	}
+
+	if g.screenRepo == nil {
+		return
+	}
+
+	if err := g.screenRepo.SaveScreen(ctx, g.deviceID, current); err != nil {
+		g.logger.Warn("FSM: failed to persist screen", slog.String("state", current), slog.Any("error", err))
+	}
+}
+
+// loadScreen returns the last persisted screen or an empty string.
+func (g *GameFSM) loadScreen(ctx context.Context) string {
+	if g.screenRepo == nil {
+		return ""
+	}
+
+	screen, err := g.screenRepo.LoadScreen(ctx, g.deviceID)
+	if err != nil {
+		g.logger.Warn("FSM: failed to load persisted screen", slog.Any("error", err))
+		return ""
+	}
+
+	return screen
+}
+
+// titleRules merges every rule group from fsmState.yaml ("default" first),
+// skipping duplicates by name.
+func (g *GameFSM) titleRules() []domain.AnalyzeRule {
+	keys := lo.Keys(g.rulesCheckState)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "default" || keys[j] == "default" {
+			return keys[i] == "default"
+		}
+		return keys[i] < keys[j]
+	})
+
+	seen := make(map[string]bool)
+	var rules []domain.AnalyzeRule
+	for _, key := range keys {
+		for _, rule := range g.rulesCheckState[key] {
+			if seen[rule.Name] {
+				continue
+			}
+			seen[rule.Name] = true
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,138 @@
+package fsm_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/config"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain/state"
+	"github.com/batazor/whiteout-survival-autopilot/internal/fsm"
+	"github.com/batazor/whiteout-survival-autopilot/internal/redis_queue"
+)
+
+// memScreenRepo keeps the persisted screens in memory.
+type memScreenRepo map[string]string
+
+func (r memScreenRepo) LoadScreen(_ context.Context, deviceID string) (string, error) {
+	return r[deviceID], nil
+}
+
+func (r memScreenRepo) SaveScreen(_ context.Context, deviceID string, screen string) error {
+	r[deviceID] = screen
+	return nil
+}
+
+// screenTitle reports the same title on every screen.
+type screenTitle string
+
+func (s screenTitle) AnalyzeAndUpdateState(_ context.Context, gamer *domain.Gamer, _ []domain.AnalyzeRule, _ *redis_queue.Queue) (*domain.Gamer, error) {
+	out := *gamer
+	out.ScreenState.TitleFact = string(s)
+	return &out, nil
+}
+
+func newLocateFSM(t *testing.T, adb *FakeADB, persisted string) (*fsm.GameFSM, memScreenRepo) {
+	t.Helper()
+
+	lookup, err := config.LoadAreaReferences("../../references/area.json")
+	require.NoError(t, err)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	g := fsm.NewGame(logger, adb, lookup, nil, nil, nil)
+	repo := memScreenRepo{}
+	if persisted != "" {
+		repo["device"] = persisted
+	}
+	g.SetScreenRepository("device", repo)
+	return g, repo
+}
+
+func TestLocate_TableDriven(t *testing.T) {
+	defer fsm.SetLocateBackWait(time.Millisecond)()
+
+	tests := []struct {
+		name      string
+		persisted string
+		title     string
+		backTitle string // the title once Locate navigated back
+		want      string
+		clicks    []string
+	}{
+		{
+			name:      "the persisted screen is confirmed",
+			persisted: "heroes",
+			title:     "Heroes",
+			want:      "heroes",
+		},
+		{
+			name:      "a stale persisted screen is re-detected",
+			persisted: "mail",
+			title:     "Heroes",
+			want:      "heroes",
+		},
+		{
+			name:      "an unknown screen is left by navigating back",
+			persisted: "mail",
+			title:     "Loading",
+			backTitle: "Heroes",
+			want:      "heroes",
+			clicks:    []string{fsm.LocateBackRegion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adb := &FakeADB{}
+			g, repo := newLocateFSM(t, adb, tt.persisted)
+			if tt.backTitle != "" {
+				g.SetAnalyzer(clickAnalyzer{adb: adb, titles: map[string]string{fsm.LocateBackRegion: tt.backTitle}})
+			} else {
+				g.SetAnalyzer(screenTitle(tt.title))
+			}
+
+			got, err := g.Locate(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.clicks, adb.Clicks)
+			assert.Equal(t, tt.want, g.Current())
+			assert.Equal(t, tt.want, repo["device"], "the confirmed screen is persisted")
+		})
+	}
+}
+
+func TestLocate_UnknownScreen(t *testing.T) {
+	defer fsm.SetLocateBackWait(time.Millisecond)()
+
+	adb := &FakeADB{}
+	g, repo := newLocateFSM(t, adb, "mail")
+	g.SetAnalyzer(screenTitle("Loading"))
+
+	_, err := g.Locate(context.Background())
+	assert.ErrorIs(t, err, fsm.ErrUnknownScreen)
+	assert.Len(t, adb.Clicks, 4, "navigated back until giving up")
+	assert.Equal(t, "mail", repo["device"], "nothing confirmed")
+
+	assert.Equal(t, state.StateMainCity, g.Resume(context.Background()), "Resume stays at the main city")
+}
+
+func TestLocate_WaitHonorsContext(t *testing.T) {
+	defer fsm.SetLocateBackWait(time.Hour)()
+
+	g, _ := newLocateFSM(t, &FakeADB{}, "")
+	g.SetAnalyzer(screenTitle("Loading"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := g.Locate(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
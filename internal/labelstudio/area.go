@@ -0,0 +1,88 @@
+package labelstudio
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// AreaEntry is an entry of area.json with the annotation fields of the JSON-MIN export.
+// An entry read from the file is written back byte for byte (unknown fields included) unless a review replaced it.
+type AreaEntry struct {
+	domain.AreaReference
+	Annotator    int       `json:"annotator"`
+	AnnotationID int       `json:"annotation_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	LeadTime     float64   `json:"lead_time"`
+
+	raw json.RawMessage // the entry as read
+}
+
+// Reviewed tells the entries of a review from the ones written by hand: those have no annotation
+// (annotation_id 0) until their screen is reviewed.
+func (e AreaEntry) Reviewed() bool {
+	return e.AnnotationID != 0
+}
+
+// UnreviewedScreens returns the screens with entries nobody reviewed, in file order.
+func UnreviewedScreens(refs []AreaEntry) []string {
+	var screens []string
+	for _, ref := range refs {
+		if screen := ScreenName(ref.OCR); !ref.Reviewed() && !slices.Contains(screens, screen) {
+			screens = append(screens, screen)
+		}
+	}
+	return screens
+}
+
+// areaEntryJSON has the field order of the JSON-MIN export.
+type areaEntryJSON struct {
+	OCR           string        `json:"ocr"`
+	ID            int           `json:"id"`
+	BBox          domain.BBoxes `json:"bbox"`
+	Transcription []string      `json:"transcription"`
+	Annotator     int           `json:"annotator"`
+	AnnotationID  int           `json:"annotation_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	LeadTime      float64       `json:"lead_time"`
+}
+
+func (e *AreaEntry) UnmarshalJSON(data []byte) error {
+	var v areaEntryJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*e = AreaEntry{
+		AreaReference: domain.AreaReference{OCR: v.OCR, ID: v.ID, BBox: v.BBox, Transcription: v.Transcription},
+		Annotator:     v.Annotator,
+		AnnotationID:  v.AnnotationID,
+		CreatedAt:     v.CreatedAt,
+		UpdatedAt:     v.UpdatedAt,
+		LeadTime:      v.LeadTime,
+		raw:           append(json.RawMessage(nil), data...),
+	}
+	return nil
+}
+
+func (e AreaEntry) MarshalJSON() ([]byte, error) {
+	if e.raw != nil {
+		return e.raw, nil
+	}
+
+	return json.Marshal(areaEntryJSON{
+		OCR:           e.OCR,
+		ID:            e.ID,
+		BBox:          e.BBox,
+		Transcription: e.Transcription,
+		Annotator:     e.Annotator,
+		AnnotationID:  e.AnnotationID,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+		LeadTime:      e.LeadTime,
+	})
+}
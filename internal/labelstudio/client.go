@@ -0,0 +1,93 @@
+// Package labelstudio moves screenshots, area.json boxes and OCR readings to a Label Studio
+// project for review and turns the reviewed annotations back into area.json and OCR ground truth.
+package labelstudio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Client calls the Label Studio API.
+type Client struct {
+	URL   string
+	Token string // legacy API token of the user (Account & Settings)
+	HTTP  *http.Client
+}
+
+// NewClient creates a client for LABEL_STUDIO_URL with LABEL_STUDIO_TOKEN.
+func NewClient() *Client {
+	viper.SetDefault("LABEL_STUDIO_URL", "http://localhost:8082")
+
+	return &Client{
+		URL:   strings.TrimRight(viper.GetString("LABEL_STUDIO_URL"), "/"),
+		Token: viper.GetString("LABEL_STUDIO_TOKEN"),
+		HTTP:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ImportTasks adds tasks with their pre-annotations to the project.
+func (c *Client) ImportTasks(ctx context.Context, project int, tasks []Task) error {
+	body, err := json.Marshal(tasks)
+	if err != nil {
+		return fmt.Errorf("marshal tasks: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/projects/%d/import", project), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// ExportTasks returns every task of the project with its annotations.
+func (c *Client) ExportTasks(ctx context.Context, project int) ([]Task, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/projects/%d/export?exportType=JSON", project), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("decode export: %w", err)
+	}
+	return tasks, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("new request %s: %w", path, err)
+	}
+	req.Header.Set("Authorization", "Token "+c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, b)
+	}
+	return resp, nil
+}
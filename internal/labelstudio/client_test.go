@@ -0,0 +1,202 @@
+package labelstudio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// fakeLabelStudio keeps the imported tasks of one project; review annotates them.
+type fakeLabelStudio struct {
+	mu    sync.Mutex
+	tasks []Task
+}
+
+func (s *fakeLabelStudio) start(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/projects/7/import", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Token secret", r.Header.Get("Authorization"))
+
+		var tasks []Task
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&tasks))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, task := range tasks {
+			task.ID = len(s.tasks) + 1
+			s.tasks = append(s.tasks, task)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"task_count": len(tasks)})
+	})
+	mux.HandleFunc("GET /api/projects/7/export", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "JSON", r.URL.Query().Get("exportType"))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(s.tasks)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &Client{URL: server.URL, Token: "secret", HTTP: server.Client()}
+}
+
+// review accepts the predictions of the task with edit applied to the regions.
+func (s *fakeLabelStudio) review(id int, edit func([]Result) []Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := &s.tasks[id-1]
+	var result []Result
+	if len(task.Predictions) > 0 {
+		result = append(result, task.Predictions[0].Result...)
+	}
+	task.Annotations = append(task.Annotations,
+		Annotation{ID: 1, WasCancelled: true, UpdatedAt: time.Now().Add(time.Hour)},
+		Annotation{ID: 2, CompletedBy: 5, UpdatedAt: time.Now(), LeadTime: 42, Result: edit(result)},
+	)
+}
+
+var testArea = []domain.AreaReference{
+	{OCR: "/data/upload/1/ec986df7-city_main.png", ID: 1, Transcription: []string{"power", "gems"}, BBox: domain.BBoxes{
+		{X: 17, Y: 6, Width: 23, Height: 2, OriginalWidth: 1080, OriginalHeight: 2400},
+		{X: 80, Y: 4, Width: 15, Height: 2, OriginalWidth: 1080, OriginalHeight: 2400},
+	}},
+	{OCR: "/data/upload/1/331ebf97-mail.png", ID: 2, Transcription: []string{"mail.title"}, BBox: domain.BBoxes{
+		{X: 40, Y: 2, Width: 20, Height: 3, OriginalWidth: 1080, OriginalHeight: 2400},
+	}},
+	{OCR: "/data/upload/1/ec986df7-city_main.png", ID: 3, Transcription: []string{"to_mail"}, BBox: domain.BBoxes{
+		{X: 84, Y: 82, Width: 10, Height: 5, OriginalWidth: 1080, OriginalHeight: 2400},
+	}},
+}
+
+// testEntries is testArea as read from area.json: with annotation fields and a field MergeArea doesn't know.
+func testEntries(t *testing.T) []AreaEntry {
+	t.Helper()
+
+	var raw []map[string]any
+	data, err := json.Marshal(testArea)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &raw))
+	for _, entry := range raw {
+		entry["annotator"] = 1
+		entry["annotation_id"] = entry["id"]
+		entry["created_at"] = "2025-05-01T10:00:00.000000Z"
+		entry["updated_at"] = "2025-05-01T10:00:00.000000Z"
+		entry["lead_time"] = 12.5
+		entry["reviewer_note"] = "kept as is"
+	}
+
+	data, err = json.Marshal(raw)
+	require.NoError(t, err)
+	var entries []AreaEntry
+	require.NoError(t, json.Unmarshal(data, &entries))
+	return entries
+}
+
+func TestAreaRoundTrip(t *testing.T) {
+	ls := &fakeLabelStudio{}
+	client := ls.start(t)
+	ctx := context.Background()
+
+	tasks := []Task{
+		AreaTask("city_main.png", "/data/local-files/?d=screenshots/city_main.png", testArea),
+		AreaTask("arena/arena_main.png", "/data/local-files/?d=screenshots/arena/arena_main.png", testArea),
+	}
+	require.Len(t, tasks[0].Predictions, 1)
+	require.Len(t, tasks[0].Predictions[0].Result, 6, "a box and a text per region, of both entries of the screen")
+	assert.Empty(t, tasks[1].Predictions, "a screen without boxes")
+
+	require.NoError(t, client.ImportTasks(ctx, 7, tasks))
+
+	// the reviewer moves gems, drops to_mail and labels a region on the new screen
+	ls.review(1, func(result []Result) []Result {
+		var out []Result
+		for _, r := range result {
+			switch r.ID {
+			case "gems":
+				r.Value.X = 78
+			case "to_mail":
+				continue
+			}
+			out = append(out, r)
+		}
+		return out
+	})
+	ls.review(2, func([]Result) []Result {
+		return Region{ID: "xyz", Text: "arena.title", BBox: domain.BBox{X: 30, Y: 3, Width: 40, Height: 3, OriginalWidth: 1080, OriginalHeight: 2400}}.results()
+	})
+
+	exported, err := client.ExportTasks(ctx, 7)
+	require.NoError(t, err)
+
+	entries := testEntries(t)
+	area, reviewed := MergeArea(entries, exported)
+	assert.Equal(t, 2, reviewed)
+	require.Len(t, area, 3, "the second entry of city_main is merged into the first")
+
+	assert.Equal(t, 1, area[0].ID)
+	assert.Equal(t, "/data/upload/1/ec986df7-city_main.png", area[0].OCR, "the image of the entry is kept")
+	assert.Equal(t, []string{"power", "gems"}, area[0].Transcription)
+	assert.Equal(t, 78.0, area[0].BBox[1].X)
+	assert.Equal(t, 1080, area[0].BBox[1].OriginalWidth)
+
+	assert.Equal(t, 5, area[0].Annotator, "annotation fields of the review")
+	assert.Equal(t, 2, area[0].AnnotationID)
+	assert.Equal(t, 42.0, area[0].LeadTime)
+
+	untouched, err := json.Marshal(area[1])
+	require.NoError(t, err)
+	original, err := json.Marshal(entries[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, string(original), string(untouched), "not reviewed")
+	assert.Contains(t, string(untouched), `"reviewer_note":"kept as is"`)
+	assert.Contains(t, string(untouched), `"lead_time":12.5`)
+
+	assert.Equal(t, 4, area[2].ID)
+	assert.Equal(t, "/data/local-files/?d=screenshots/arena/arena_main.png", area[2].OCR)
+	assert.Equal(t, []string{"arena.title"}, area[2].Transcription)
+}
+
+func TestClient_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail": "Invalid token."}`, http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &Client{URL: server.URL, HTTP: server.Client()}
+	_, err := client.ExportTasks(context.Background(), 7)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestScreenName(t *testing.T) {
+	assert.Equal(t, "city_main.png", ScreenName("/data/upload/1/ec986df7-city_main.png"))
+	assert.Equal(t, "alliance_chest_gift.png", ScreenName("/data/local-files/?d=screenshots/alliance/alliance_chest_gift.png"))
+	assert.Equal(t, "main.png", ScreenName("main.png"))
+}
+
+func TestUnreviewedScreens(t *testing.T) {
+	entries := testEntries(t)
+	assert.Empty(t, UnreviewedScreens(entries))
+
+	var handWritten []AreaEntry
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"ocr": "/data/local-files/?d=screenshots/heroes.png", "id": 5, "transcription": ["hero_card.name"], "annotator": 0, "annotation_id": 0},
+		{"ocr": "/data/local-files/?d=screenshots/city_main.png", "id": 6, "transcription": ["resources.wood"]},
+		{"ocr": "/data/local-files/?d=screenshots/heroes.png", "id": 7, "transcription": ["hero_card.level"]}
+	]`), &handWritten))
+	assert.Equal(t, []string{"heroes.png", "city_main.png"}, UnreviewedScreens(append(entries, handWritten...)))
+}
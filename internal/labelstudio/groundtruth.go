@@ -0,0 +1,200 @@
+package labelstudio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+// GroundTruth is a dataset of OCR readings of area.json regions with their reviewed texts.
+type GroundTruth struct {
+	Engine      string   `json:"engine"`      // the engine that made the readings
+	Screenshots string   `json:"screenshots"` // screenshot dir, relative to the dataset file
+	MinAccuracy float64  `json:"minAccuracy"` // the regression test fails for regions read worse
+	Samples     []Sample `json:"samples"`
+}
+
+// Sample is one region of one screenshot.
+type Sample struct {
+	Screen string      `json:"screen"` // screenshot path relative to the screenshot dir
+	Region string      `json:"region"`
+	BBox   domain.BBox `json:"bbox"`
+	OCR    string      `json:"ocr"`  // engine reading
+	Text   string      `json:"text"` // reviewed text
+}
+
+// OCRTask is the task of a screenshot whose regions are pre-annotated with the engine readings.
+// Region IDs are the region names, so they survive the review.
+func OCRTask(screen, imageURL string, regions []Region) Task {
+	task := Task{Data: TaskData{OCR: imageURL, Screen: screen, Readings: make(map[string]string, len(regions))}}
+
+	var result []Result
+	for _, r := range regions {
+		task.Data.Readings[r.ID] = r.Text
+		result = append(result, r.results()...)
+	}
+	if len(result) > 0 {
+		task.Predictions = []Prediction{{ModelVersion: "ocr", Result: result}}
+	}
+	return task
+}
+
+// Samples returns the reviewed regions of the tasks pushed by OCRTask.
+// Boxes drawn by the reviewer have no region and are skipped.
+func Samples(tasks []Task) []Sample {
+	var samples []Sample
+	for _, task := range tasks {
+		annotation, ok := task.Reviewed()
+		if !ok {
+			continue
+		}
+
+		for _, r := range annotation.Regions() {
+			reading, ok := task.Data.Readings[r.ID]
+			if !ok {
+				continue
+			}
+			samples = append(samples, Sample{
+				Screen: task.Data.Screen,
+				Region: r.ID,
+				BBox:   r.BBox,
+				OCR:    reading,
+				Text:   r.Text,
+			})
+		}
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].Screen != samples[j].Screen {
+			return samples[i].Screen < samples[j].Screen
+		}
+		return samples[i].Region < samples[j].Region
+	})
+	return samples
+}
+
+// LoadGroundTruth reads the dataset.
+func LoadGroundTruth(path string) (*GroundTruth, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ground truth: %w", err)
+	}
+
+	var gt GroundTruth
+	if err := json.Unmarshal(data, &gt); err != nil {
+		return nil, fmt.Errorf("failed to parse ground truth %s: %w", path, err)
+	}
+	return &gt, nil
+}
+
+// Save writes the dataset.
+func (g *GroundTruth) Save(path string) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// RegionAccuracy is the mean accuracy of the readings of one region.
+type RegionAccuracy struct {
+	Region   string
+	Samples  int
+	Accuracy float64
+}
+
+// Accuracy scores read(sample) against the reviewed texts, per region, sorted by region.
+func (g *GroundTruth) Accuracy(read func(Sample) (string, error)) ([]RegionAccuracy, error) {
+	sums := make(map[string]*RegionAccuracy)
+	for _, s := range g.Samples {
+		got, err := read(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", s.Screen, s.Region, err)
+		}
+
+		acc, ok := sums[s.Region]
+		if !ok {
+			acc = &RegionAccuracy{Region: s.Region}
+			sums[s.Region] = acc
+		}
+		acc.Samples++
+		acc.Accuracy += TextAccuracy(got, s.Text)
+	}
+
+	out := make([]RegionAccuracy, 0, len(sums))
+	for _, acc := range sums {
+		acc.Accuracy /= float64(acc.Samples)
+		out = append(out, *acc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Region < out[j].Region })
+	return out, nil
+}
+
+// TextAccuracy is 1 minus the edit distance of got to want relative to the longer text.
+// Runs of spaces count as one space.
+func TextAccuracy(got, want string) float64 {
+	a := []rune(strings.Join(strings.Fields(got), " "))
+	b := []rune(strings.Join(strings.Fields(want), " "))
+
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance is the Levenshtein distance.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// ReadRegion reads the box of a screenshot with the engine and joins the texts found, top to bottom.
+func ReadRegion(ctx context.Context, engine ocrclient.OCREngine, img image.Image, bbox domain.BBox) (string, error) {
+	size := img.Bounds().Size()
+	rect := image.Rect(
+		int(bbox.X*float64(size.X)/100),
+		int(bbox.Y*float64(size.Y)/100),
+		int((bbox.X+bbox.Width)*float64(size.X)/100),
+		int((bbox.Y+bbox.Height)*float64(size.Y)/100),
+	).Add(img.Bounds().Min)
+
+	results, err := engine.Recognize(ctx, ocrclient.EngineRequest{
+		Pixels:  func() (image.Image, error) { return img, nil },
+		Regions: []ocrclient.Region{{X0: rect.Min.X, Y0: rect.Min.Y, X1: rect.Max.X, Y1: rect.Max.Y}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Y < results[j].Y })
+	texts := make([]string, len(results))
+	for i, r := range results {
+		texts[i] = r.Text
+	}
+	return strings.Join(texts, " "), nil
+}
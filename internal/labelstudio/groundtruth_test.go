@@ -0,0 +1,126 @@
+package labelstudio
+
+import (
+	"context"
+	"errors"
+	"image"
+	_ "image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+func TestTextAccuracy(t *testing.T) {
+	assert.Equal(t, 1.0, TextAccuracy("Mail", "Mail"))
+	assert.Equal(t, 1.0, TextAccuracy(" Heal  Injured ", "Heal Injured"))
+	assert.Equal(t, 1.0, TextAccuracy("", ""))
+	assert.Equal(t, 0.0, TextAccuracy("", "Mail"))
+	assert.InDelta(t, 0.9, TextAccuracy("25,431,87O", "25,431,870"), 1e-9)
+	assert.InDelta(t, 0.75, TextAccuracy("Maii", "Mail"), 1e-9)
+	assert.InDelta(t, 1-1.0/6, TextAccuracy("Почта!", "Почта"), 1e-9, "runes, not bytes")
+}
+
+func TestOCRGroundTruthRoundTrip(t *testing.T) {
+	ls := &fakeLabelStudio{}
+	client := ls.start(t)
+	ctx := context.Background()
+
+	bbox := domain.BBox{X: 17, Y: 6, Width: 23, Height: 2, OriginalWidth: 1080, OriginalHeight: 2400}
+	task := OCRTask("city_main.png", "/data/local-files/?d=screenshots/city_main.png", []Region{
+		{ID: "power", Text: "25,431,87O", BBox: bbox},
+		{ID: "gems", Text: "1250", BBox: bbox},
+	})
+	require.NoError(t, client.ImportTasks(ctx, 7, []Task{task}))
+
+	ls.review(1, func(result []Result) []Result {
+		for i := range result {
+			if result[i].ID == "power" && result[i].FromName == transcriptionName {
+				result[i].Value.Text = []string{"25,431,870"}
+			}
+		}
+		// a box drawn by the reviewer has no reading
+		return append(result, Region{ID: "new", Text: "VIP", BBox: bbox}.results()...)
+	})
+
+	exported, err := client.ExportTasks(ctx, 7)
+	require.NoError(t, err)
+
+	gt := &GroundTruth{MinAccuracy: 0.9, Samples: Samples(exported)}
+	require.Equal(t, []Sample{
+		{Screen: "city_main.png", Region: "gems", BBox: bbox, OCR: "1250", Text: "1250"},
+		{Screen: "city_main.png", Region: "power", BBox: bbox, OCR: "25,431,87O", Text: "25,431,870"},
+	}, gt.Samples)
+
+	path := filepath.Join(t.TempDir(), "gt.json")
+	require.NoError(t, gt.Save(path))
+	loaded, err := LoadGroundTruth(path)
+	require.NoError(t, err)
+
+	acc, err := loaded.Accuracy(func(s Sample) (string, error) { return s.OCR, nil })
+	require.NoError(t, err)
+	assert.Equal(t, []RegionAccuracy{{Region: "gems", Samples: 1, Accuracy: 1}, {Region: "power", Samples: 1, Accuracy: 0.9}}, acc)
+
+	_, err = loaded.Accuracy(func(Sample) (string, error) { return "", errors.New("engine down") })
+	assert.Error(t, err)
+}
+
+func TestReadRegion(t *testing.T) {
+	engine := &ocrclient.RecordedEngine{Results: domain.OCRResults{
+		{Text: "870", X: 300, Y: 190, Width: 40, Height: 20},
+		{Text: "Power", X: 200, Y: 150, Width: 40, Height: 20},
+		{Text: "outside", X: 900, Y: 900, Width: 40, Height: 20},
+	}}
+	img := image.NewRGBA(image.Rect(0, 0, 1080, 2400))
+
+	text, err := ReadRegion(context.Background(), engine, img, domain.BBox{X: 10, Y: 5, Width: 40, Height: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "Power 870", text)
+}
+
+// TestOCRGroundTruth measures the OCR accuracy of every region of references/ocr_groundtruth.json
+// (written by `labelStudio ocr-pull`). It scores the recorded readings; with OCR_GROUNDTRUTH_ENGINE
+// set (e.g. tesseract) the screenshots are read again by that engine.
+func TestOCRGroundTruth(t *testing.T) {
+	const path = "../../references/ocr_groundtruth.json"
+	gt, err := LoadGroundTruth(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("no OCR ground truth, see docs/labelstudio.md")
+	}
+	require.NoError(t, err)
+
+	read := func(s Sample) (string, error) { return s.OCR, nil }
+	if name := os.Getenv("OCR_GROUNDTRUTH_ENGINE"); name != "" {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		engine, err := ocrclient.NewEngine(name, nil, logger)
+		require.NoError(t, err)
+
+		read = func(s Sample) (string, error) {
+			f, err := os.Open(filepath.Join(filepath.Dir(path), gt.Screenshots, filepath.FromSlash(s.Screen)))
+			if err != nil {
+				return "", err
+			}
+			defer f.Close()
+
+			img, _, err := image.Decode(f)
+			if err != nil {
+				return "", err
+			}
+			return ReadRegion(context.Background(), engine, img, s.BBox)
+		}
+	}
+
+	acc, err := gt.Accuracy(read)
+	require.NoError(t, err)
+
+	for _, a := range acc {
+		t.Logf("%-40s %3d samples  %5.1f%%", a.Region, a.Samples, a.Accuracy*100)
+		assert.GreaterOrEqual(t, a.Accuracy, gt.MinAccuracy, "region %s", a.Region)
+	}
+}
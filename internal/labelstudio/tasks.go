@@ -0,0 +1,246 @@
+package labelstudio
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Names of the OCR labeling config of the project:
+//
+//	<Image name="image" value="$ocr"/>
+//	<Rectangle name="bbox" toName="image"/>
+//	<TextArea name="transcription" toName="image" perRegion="true"/>
+//
+// Its JSON-MIN export is the shape of area.json.
+const (
+	imageName         = "image"
+	bboxName          = "bbox"
+	transcriptionName = "transcription"
+)
+
+// Task is a Label Studio task: one screenshot.
+type Task struct {
+	ID          int          `json:"id,omitempty"`
+	Data        TaskData     `json:"data"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Predictions []Prediction `json:"predictions,omitempty"`
+}
+
+// TaskData is the data of a task.
+type TaskData struct {
+	OCR      string            `json:"ocr"`                // image URL
+	Screen   string            `json:"screen,omitempty"`   // screenshot path relative to the screenshot dir
+	Readings map[string]string `json:"readings,omitempty"` // ground truth: engine reading of every region at push time
+}
+
+// Prediction is a pre-annotation shown to the reviewer.
+type Prediction struct {
+	ModelVersion string   `json:"model_version"`
+	Result       []Result `json:"result"`
+}
+
+// Annotation is the work of a reviewer.
+type Annotation struct {
+	ID           int       `json:"id"`
+	CompletedBy  int       `json:"completed_by"`
+	WasCancelled bool      `json:"was_cancelled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	LeadTime     float64   `json:"lead_time"`
+	Result       []Result  `json:"result"`
+}
+
+// Result is one control of one region; the box and its text share the ID.
+type Result struct {
+	ID             string `json:"id"`
+	FromName       string `json:"from_name"`
+	ToName         string `json:"to_name"`
+	Type           string `json:"type"`
+	OriginalWidth  int    `json:"original_width"`
+	OriginalHeight int    `json:"original_height"`
+	Value          Value  `json:"value"`
+}
+
+// Value is the box in percent of the image, and the text of a textarea.
+type Value struct {
+	X        float64  `json:"x"`
+	Y        float64  `json:"y"`
+	Width    float64  `json:"width"`
+	Height   float64  `json:"height"`
+	Rotation float64  `json:"rotation"`
+	Text     []string `json:"text,omitempty"`
+}
+
+// Region is a labelled box of a task.
+type Region struct {
+	ID   string
+	Text string
+	BBox domain.BBox
+}
+
+// results returns the box and the text of the region.
+func (r Region) results() []Result {
+	value := Value{X: r.BBox.X, Y: r.BBox.Y, Width: r.BBox.Width, Height: r.BBox.Height, Rotation: r.BBox.Rotation}
+	rect := Result{
+		ID:             r.ID,
+		FromName:       bboxName,
+		ToName:         imageName,
+		Type:           "rectangle",
+		OriginalWidth:  r.BBox.OriginalWidth,
+		OriginalHeight: r.BBox.OriginalHeight,
+		Value:          value,
+	}
+
+	text := rect
+	text.FromName = transcriptionName
+	text.Type = "textarea"
+	text.Value.Text = []string{r.Text}
+	return []Result{rect, text}
+}
+
+// Regions pairs the boxes and texts of the annotation by their ID, in order.
+func (a Annotation) Regions() []Region {
+	var regions []Region
+	index := make(map[string]int)
+
+	for _, res := range a.Result {
+		i, ok := index[res.ID]
+		if !ok {
+			i = len(regions)
+			index[res.ID] = i
+			regions = append(regions, Region{
+				ID: res.ID,
+				BBox: domain.BBox{
+					X:              res.Value.X,
+					Y:              res.Value.Y,
+					Width:          res.Value.Width,
+					Height:         res.Value.Height,
+					Rotation:       res.Value.Rotation,
+					OriginalWidth:  res.OriginalWidth,
+					OriginalHeight: res.OriginalHeight,
+				},
+			})
+		}
+		if res.FromName == transcriptionName {
+			regions[i].Text = strings.TrimSpace(strings.Join(res.Value.Text, " "))
+		}
+	}
+	return regions
+}
+
+// Reviewed returns the latest annotation that wasn't skipped.
+func (t Task) Reviewed() (Annotation, bool) {
+	var latest Annotation
+	found := false
+	for _, a := range t.Annotations {
+		if a.WasCancelled {
+			continue
+		}
+		if !found || a.UpdatedAt.After(latest.UpdatedAt) {
+			latest, found = a, true
+		}
+	}
+	return latest, found
+}
+
+// uploadPrefix is the hash Label Studio prepends to uploaded file names.
+var uploadPrefix = regexp.MustCompile(`^[0-9a-f]{8}-`)
+
+// ScreenName returns the file name of a task image: "/data/upload/1/ec986df7-city_main.png"
+// and "/data/local-files/?d=screenshots/city_main.png" are both "city_main.png".
+func ScreenName(image string) string {
+	if i := strings.LastIndex(image, "?d="); i >= 0 {
+		image = image[i+len("?d="):]
+	}
+	return uploadPrefix.ReplaceAllString(path.Base(image), "")
+}
+
+// AreaTask is the task of a screenshot, pre-annotated with the boxes area.json has for the screen.
+func AreaTask(screen, imageURL string, refs []domain.AreaReference) Task {
+	task := Task{Data: TaskData{OCR: imageURL, Screen: screen}}
+
+	var regions []Region
+	for _, ref := range refs {
+		if ScreenName(ref.OCR) != path.Base(screen) {
+			continue
+		}
+		for i, name := range ref.Transcription {
+			if i < len(ref.BBox) {
+				regions = append(regions, Region{ID: name, Text: name, BBox: ref.BBox[i]})
+			}
+		}
+	}
+
+	if len(regions) > 0 {
+		var result []Result
+		for _, r := range regions {
+			result = append(result, r.results()...)
+		}
+		task.Predictions = []Prediction{{ModelVersion: "area.json", Result: result}}
+	}
+	return task
+}
+
+// MergeArea replaces the entries of the reviewed screens in area.json; the text of a region is its name
+// and the annotation fields come from the review. Entries of screens nobody reviewed stay as they are,
+// new screens are appended. It returns the updated file and the number of reviewed screens.
+func MergeArea(refs []AreaEntry, tasks []Task) ([]AreaEntry, int) {
+	out := slices.Clone(refs)
+
+	nextID := 0
+	for _, ref := range out {
+		nextID = max(nextID, ref.ID+1)
+	}
+
+	reviewed := 0
+	for _, task := range tasks {
+		annotation, ok := task.Reviewed()
+		if !ok {
+			continue
+		}
+		reviewed++
+
+		entry := AreaEntry{
+			AreaReference: domain.AreaReference{OCR: task.Data.OCR},
+			Annotator:     annotation.CompletedBy,
+			AnnotationID:  annotation.ID,
+			CreatedAt:     annotation.CreatedAt,
+			UpdatedAt:     annotation.UpdatedAt,
+			LeadTime:      annotation.LeadTime,
+		}
+		for _, r := range annotation.Regions() {
+			if r.Text == "" {
+				continue // a box without a region name
+			}
+			entry.BBox = append(entry.BBox, r.BBox)
+			entry.Transcription = append(entry.Transcription, r.Text)
+		}
+
+		screen := ScreenName(task.Data.OCR)
+		i := slices.IndexFunc(out, func(ref AreaEntry) bool { return ScreenName(ref.OCR) == screen })
+		if i < 0 {
+			entry.ID = nextID
+			nextID++
+			out = append(out, entry)
+			continue
+		}
+
+		// keep the id and image of the entry, so references to them stay valid;
+		// further entries of the screen are merged into it by the review
+		entry.ID, entry.OCR = out[i].ID, out[i].OCR
+		merged := out[:i:i]
+		merged = append(merged, entry)
+		for _, ref := range out[i+1:] {
+			if ScreenName(ref.OCR) != screen {
+				merged = append(merged, ref)
+			}
+		}
+		out = merged
+	}
+	return out, reviewed
+}
@@ -54,6 +54,52 @@ var (
 		},
 		[]string{"device_id", "type"},
 	)
+
+	// 🧭 FSM transitions by result (success/failure)
+	FSMTransitionTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_fsm_transition_total",
+			Help: "Number of executed FSM transitions by result",
+		},
+		[]string{"from", "to", "result"},
+	)
+
+	// ⏱️ FSM transition time
+	FSMTransitionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bot_fsm_transition_duration_seconds",
+			Help:    "Duration of FSM transitions in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"from", "to"},
+	)
+
+	// 🗂️ OCR cache lookups by result (hit/miss)
+	OCRCacheTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_ocr_cache_total",
+			Help: "Number of OCR region cache lookups by result",
+		},
+		[]string{"result"},
+	)
+
+	// 🔁 Regions re-read by the fallback OCR engine, by the engine whose reading won
+	OCRFallbackTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_ocr_fallback_total",
+			Help: "Number of regions re-read by the fallback OCR engine, by winning engine",
+		},
+		[]string{"primary", "fallback", "winner"},
+	)
+
+	// 🚫 Analyzer readings rejected by the update policy of their rule
+	AnalyzerRejectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_analyzer_rejected_total",
+			Help: "Number of analyzer readings not applied to the state, by rule and reason",
+		},
+		[]string{"rule", "reason"},
+	)
 )
 
 // 🚀 Register all metrics at startup
@@ -64,6 +110,11 @@ func Init() {
 		GamerPowerGauge,
 		GamerFurnaceLevel,
 		ADBErrorTotal,
+		FSMTransitionTotal,
+		FSMTransitionDuration,
+		OCRCacheTotal,
+		OCRFallbackTotal,
+		AnalyzerRejectedTotal,
 	)
 }
 
@@ -0,0 +1,213 @@
+package ocrclient
+
+import (
+	"context"
+	"image"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
+)
+
+// hashSide – the region is reduced to (hashSide+1)×hashSide gray cells for the difference hash.
+const hashSide = 16
+
+// regionHash is a 256-bit difference hash of a screen region.
+type regionHash [hashSide * hashSide / 64]uint64
+
+// distance is the number of differing bits.
+func (h regionHash) distance(o regionHash) int {
+	d := 0
+	for i := range h {
+		d += bits.OnesCount64(h[i] ^ o[i])
+	}
+	return d
+}
+
+// hashRegion computes a difference hash: every bit tells whether a cell is brighter than its right neighbour.
+// Compression noise doesn't change it; in a text-sized region a changed digit or a new badge does.
+// On a whole screen a cell covers too many pixels to notice a digit, so full-frame requests aren't cached.
+func hashRegion(img image.Image, rect image.Rectangle) regionHash {
+	rect = rect.Intersect(img.Bounds())
+
+	var cells [hashSide][hashSide + 1]float64
+	if !rect.Empty() {
+		for cy := 0; cy < hashSide; cy++ {
+			y0 := rect.Min.Y + cy*rect.Dy()/hashSide
+			y1 := max(rect.Min.Y+(cy+1)*rect.Dy()/hashSide, y0+1)
+			for cx := 0; cx <= hashSide; cx++ {
+				x0 := rect.Min.X + cx*rect.Dx()/(hashSide+1)
+				x1 := max(rect.Min.X+(cx+1)*rect.Dx()/(hashSide+1), x0+1)
+
+				var sum float64
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						r, g, b, _ := img.At(x, y).RGBA()
+						sum += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+					}
+				}
+				cells[cy][cx] = sum / float64((y1-y0)*(x1-x0))
+			}
+		}
+	}
+
+	var h regionHash
+	for cy := 0; cy < hashSide; cy++ {
+		for cx := 0; cx < hashSide; cx++ {
+			if cells[cy][cx] > cells[cy][cx+1] {
+				bit := cy*hashSide + cx
+				h[bit/64] |= 1 << (bit % 64)
+			}
+		}
+	}
+	return h
+}
+
+// CacheStats counts cache lookups.
+type CacheStats struct {
+	Hits   int
+	Misses int
+}
+
+// OCRCache keeps OCR results of screen regions for a short time.
+// A region is served from the cache while its pixels look the same.
+type OCRCache struct {
+	TTL         time.Duration
+	MaxDistance int  // differing hash bits still treated as the same region
+	OnlyChanged bool // OCR only the changed regions instead of the whole request
+
+	mu      sync.Mutex
+	entries map[Region]cacheEntry
+	stats   CacheStats
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	hash    regionHash
+	results domain.OCRResults
+	at      time.Time
+}
+
+// NewOCRCache creates a cache whose entries live for ttl.
+func NewOCRCache(ttl time.Duration) *OCRCache {
+	return &OCRCache{
+		TTL:         ttl,
+		OnlyChanged: true,
+		entries:     make(map[Region]cacheEntry),
+		now:         time.Now,
+	}
+}
+
+// Stats returns the number of hits and misses so far.
+func (c *OCRCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// get returns the cached results of the region if its hash still matches.
+func (c *OCRCache) get(region Region, hash regionHash) (domain.OCRResults, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[region]
+	if ok && c.now().Sub(entry.at) <= c.TTL && entry.hash.distance(hash) <= c.MaxDistance {
+		c.stats.Hits++
+		metrics.OCRCacheTotal.WithLabelValues("hit").Inc()
+		return entry.results, true
+	}
+
+	c.stats.Misses++
+	metrics.OCRCacheTotal.WithLabelValues("miss").Inc()
+	return nil, false
+}
+
+func (c *OCRCache) put(region Region, hash regionHash, results domain.OCRResults) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for r, e := range c.entries {
+		if now.Sub(e.at) > c.TTL {
+			delete(c.entries, r)
+		}
+	}
+	c.entries[region] = cacheEntry{hash: hash, results: results, at: now}
+}
+
+// FetchOCRCached is FetchOCRFrame backed by c.Cache; img must hold the pixels of the frame.
+// Regions that didn't change since the last OCR reuse its results.
+// Without a cache, pixels or regions it is a plain FetchOCRFrame.
+func (c *Client) FetchOCRCached(ctx context.Context, frameID string, img image.Image, debugName string, regions []Region) (domain.OCRResults, error) {
+	if c.Cache == nil || img == nil || len(regions) == 0 {
+		return c.FetchOCRFrame(ctx, frameID, debugName, regions)
+	}
+
+	requested := regions
+
+	hashes := make([]regionHash, len(requested))
+	cached := make([]domain.OCRResults, len(requested))
+	var changed []int
+	for i, r := range requested {
+		hashes[i] = hashRegion(img, r.rect())
+		if res, ok := c.Cache.get(r, hashes[i]); ok {
+			cached[i] = res
+			continue
+		}
+		changed = append(changed, i)
+	}
+
+	if len(changed) == 0 {
+		c.Logger.Debug("🖼️  OCR served from cache", "device_id", c.DeviceID, "regions", len(requested))
+		return flatten(cached), nil
+	}
+
+	// all regions are OCR'd again unless only the changed ones are asked for
+	if !c.Cache.OnlyChanged {
+		changed = changed[:0]
+		for i := range requested {
+			changed = append(changed, i)
+		}
+	}
+
+	ask := make([]Region, len(changed))
+	for j, i := range changed {
+		ask[j] = requested[i]
+	}
+
+	fresh, err := c.FetchOCRFrame(ctx, frameID, debugName, ask)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range changed {
+		cached[i] = within(fresh, requested[i].rect())
+		c.Cache.put(requested[i], hashes[i], cached[i])
+	}
+	return flatten(cached), nil
+}
+
+func (r Region) rect() image.Rectangle {
+	return image.Rect(r.X0, r.Y0, r.X1, r.Y1)
+}
+
+// within returns the results whose center lies in rect.
+func within(results domain.OCRResults, rect image.Rectangle) domain.OCRResults {
+	out := make(domain.OCRResults, 0)
+	for _, r := range results {
+		if (image.Point{X: r.X + r.Width/2, Y: r.Y + r.Height/2}).In(rect) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func flatten(parts []domain.OCRResults) domain.OCRResults {
+	var out domain.OCRResults
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package ocrclient
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ocrServer answers /ocr with one word in the middle of every requested region.
+func ocrServer(t *testing.T, requests *[][]Region) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req FetchOCRRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*requests = append(*requests, req.Regions)
+
+		zones := make([]OCRZone, 0)
+		for _, reg := range req.Regions {
+			cx, cy := (reg.X0+reg.X1)/2, (reg.Y0+reg.Y1)/2
+			zones = append(zones, OCRZone{Text: "word", Box: [][]int{{cx - 5, cy - 5}, {cx + 5, cy + 5}}})
+		}
+		_ = json.NewEncoder(w).Encode(zones)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{
+		ServiceURL: server.URL,
+		Logger:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+		HTTP:       server.Client(),
+	}
+}
+
+func loadScreen(t *testing.T) *image.RGBA {
+	t.Helper()
+
+	file, err := os.Open("../../references/screenshots/city_main.png")
+	require.NoError(t, err)
+	defer file.Close()
+
+	src, err := png.Decode(file)
+	require.NoError(t, err)
+
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, image.Point{}, draw.Src)
+	return img
+}
+
+func TestFetchOCRCached_OnlyChangedRegions(t *testing.T) {
+	var requests [][]Region
+	client := ocrServer(t, &requests)
+	client.Cache = NewOCRCache(time.Minute)
+
+	screen := loadScreen(t)
+	top := Region{X0: 0, Y0: 0, X1: 300, Y1: 100}
+	bottom := Region{X0: 0, Y0: 2200, X1: 300, Y1: 2300}
+
+	res, err := client.FetchOCRCached(context.Background(), "f1", screen, "", []Region{top, bottom})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	// the same pixels – nothing is sent
+	res, err = client.FetchOCRCached(context.Background(), "f2", screen, "", []Region{top, bottom})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Len(t, requests, 1)
+
+	// a new badge at the bottom
+	draw.Draw(screen, image.Rect(100, 2220, 200, 2280), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	res, err = client.FetchOCRCached(context.Background(), "f3", screen, "", []Region{top, bottom})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, []Region{bottom}, requests[1], "only the changed region is OCR'd")
+
+	require.Equal(t, CacheStats{Hits: 3, Misses: 3}, client.Cache.Stats())
+}
+
+func TestFetchOCRCached_Expires(t *testing.T) {
+	var requests [][]Region
+	client := ocrServer(t, &requests)
+	client.Cache = NewOCRCache(2 * time.Second)
+
+	now := time.Now()
+	client.Cache.now = func() time.Time { return now }
+
+	screen := loadScreen(t)
+	regions := []Region{{X0: 0, Y0: 0, X1: 300, Y1: 100}}
+
+	_, err := client.FetchOCRCached(context.Background(), "f1", screen, "", regions)
+	require.NoError(t, err)
+
+	now = now.Add(time.Second)
+	_, err = client.FetchOCRCached(context.Background(), "f2", screen, "", regions)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+
+	now = now.Add(3 * time.Second)
+	_, err = client.FetchOCRCached(context.Background(), "f3", screen, "", regions)
+	require.NoError(t, err)
+	require.Len(t, requests, 2, "a stale entry is OCR'd again")
+}
+
+func TestFetchOCRCached_AllRegions(t *testing.T) {
+	var requests [][]Region
+	client := ocrServer(t, &requests)
+	client.Cache = NewOCRCache(time.Minute)
+	client.Cache.OnlyChanged = false
+
+	screen := loadScreen(t)
+	top := Region{X0: 0, Y0: 0, X1: 300, Y1: 100}
+	bottom := Region{X0: 0, Y0: 2200, X1: 300, Y1: 2300}
+
+	_, err := client.FetchOCRCached(context.Background(), "f1", screen, "", []Region{top})
+	require.NoError(t, err)
+
+	res, err := client.FetchOCRCached(context.Background(), "f2", screen, "", []Region{top, bottom})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, []Region{top, bottom}, requests[1])
+}
+
+func TestFetchOCRCached_WholeScreenNotCached(t *testing.T) {
+	var requests [][]Region
+	client := ocrServer(t, &requests)
+	client.Cache = NewOCRCache(time.Minute)
+
+	screen := loadScreen(t)
+	for _, id := range []string{"f1", "f2"} {
+		_, err := client.FetchOCRCached(context.Background(), id, screen, "", nil)
+		require.NoError(t, err)
+	}
+	require.Len(t, requests, 2)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
@@ -40,6 +41,7 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		retry.Attempts(t.Attempts),
 		retry.Delay(t.Delay),
 		retry.LastErrorOnly(true),
+		retry.Context(req.Context()),
 	)
 	return resp, err
 }
@@ -50,6 +52,11 @@ type Client struct {
 	DeviceID   string
 	Logger     *slog.Logger
 	HTTP       *http.Client
+	Cache      *OCRCache // nil – every request is OCR'd
+	Transport  string    // TransportHTTP (default) or TransportWebSocket
+
+	streamMu sync.Mutex
+	stream   *StreamClient
 }
 
 // NewClient creates an OCR client with retry middleware.
@@ -57,6 +64,9 @@ type Client struct {
 // retried 3 times with a 500ms delay.
 func NewClient(deviceID string, logger *slog.Logger) *Client {
 	viper.SetDefault("OCR_SERVICE_URL", "http://localhost:8000")
+	viper.SetDefault("OCR_TRANSPORT", TransportHTTP)
+	viper.SetDefault("OCR_CACHE_TTL", "2s")
+	viper.SetDefault("OCR_CACHE_ONLY_CHANGED", true)
 
 	transport := &RetryTransport{
 		Base:     http.DefaultTransport,
@@ -69,10 +79,19 @@ func NewClient(deviceID string, logger *slog.Logger) *Client {
 		Timeout:   40 * time.Second,
 	}
 
-	return &Client{
+	client := &Client{
 		ServiceURL: viper.GetString("OCR_SERVICE_URL"),
 		DeviceID:   deviceID,
 		Logger:     logger,
 		HTTP:       httpClient,
+		Transport:  viper.GetString("OCR_TRANSPORT"),
+	}
+
+	// OCR_CACHE_TTL=0 disables the cache
+	if ttl := viper.GetDuration("OCR_CACHE_TTL"); ttl > 0 {
+		client.Cache = NewOCRCache(ttl)
+		client.Cache.OnlyChanged = viper.GetBool("OCR_CACHE_ONLY_CHANGED")
 	}
+
+	return client
 }
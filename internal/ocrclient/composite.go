@@ -0,0 +1,144 @@
+package ocrclient
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/metrics"
+)
+
+// CompositeEngine reads with Primary and asks Fallback again for the regions Primary read poorly:
+// a box scored below MinScore, or the text doesn't parse as the value type of the region.
+// Of the two readings of a region the better one wins.
+type CompositeEngine struct {
+	Primary  OCREngine
+	Fallback OCREngine
+	MinScore float64 // 0..1, as OCRResult.Score
+	Logger   *slog.Logger
+}
+
+func (e *CompositeEngine) Name() string {
+	return e.Primary.Name() + "+" + e.Fallback.Name()
+}
+
+func (e *CompositeEngine) Recognize(ctx context.Context, req EngineRequest) (domain.OCRResults, error) {
+	primary, err := e.Primary.Recognize(ctx, req)
+	if err != nil {
+		e.Logger.Warn("⚠️ OCR engine failed, using the fallback",
+			slog.String("engine", e.Primary.Name()),
+			slog.String("fallback", e.Fallback.Name()),
+			slog.Any("error", err),
+		)
+		return e.Fallback.Recognize(ctx, req)
+	}
+
+	// the whole screen is judged as one region
+	regions := req.Regions
+	if len(regions) == 0 {
+		return e.arbitrate(ctx, req, []domain.OCRResults{primary})
+	}
+
+	parts := make([]domain.OCRResults, len(regions))
+	for i, r := range regions {
+		parts[i] = within(primary, r.rect())
+	}
+	return e.arbitrate(ctx, req, parts)
+}
+
+// arbitrate re-reads the weak parts with Fallback and keeps the better reading of each.
+func (e *CompositeEngine) arbitrate(ctx context.Context, req EngineRequest, parts []domain.OCRResults) (domain.OCRResults, error) {
+	var weak []int
+	for i, part := range parts {
+		if !e.judge(req, i, part).good(e.MinScore) {
+			weak = append(weak, i)
+		}
+	}
+	if len(weak) == 0 {
+		return flatten(parts), nil
+	}
+
+	retry := req
+	retry.Regions = nil
+	for _, i := range weak {
+		if len(req.Regions) > 0 {
+			retry.Regions = append(retry.Regions, req.Regions[i])
+		}
+	}
+
+	second, err := e.Fallback.Recognize(ctx, retry)
+	if err != nil {
+		e.Logger.Warn("⚠️ Fallback OCR engine failed, keeping the first reading",
+			slog.String("engine", e.Fallback.Name()),
+			slog.Any("error", err),
+		)
+		return flatten(parts), nil
+	}
+
+	replaced := 0
+	for _, i := range weak {
+		candidate := second
+		if len(req.Regions) > 0 {
+			candidate = within(second, req.Regions[i].rect())
+		}
+
+		winner := e.Primary.Name()
+		if e.judge(req, i, candidate).better(e.judge(req, i, parts[i])) {
+			parts[i] = candidate
+			winner = e.Fallback.Name()
+			replaced++
+		}
+		metrics.OCRFallbackTotal.WithLabelValues(e.Primary.Name(), e.Fallback.Name(), winner).Inc()
+	}
+
+	e.Logger.Info("🔁 OCR fallback arbitration",
+		slog.String("engine", e.Name()),
+		slog.Int("weak", len(weak)),
+		slog.Int("replaced", replaced),
+	)
+	return flatten(parts), nil
+}
+
+// reading is the quality of the boxes read in one region.
+type reading struct {
+	boxes  int
+	score  float64 // the lowest box score
+	typed  bool    // the region expects a value type
+	parses bool
+}
+
+func (e *CompositeEngine) judge(req EngineRequest, i int, boxes domain.OCRResults) reading {
+	q := reading{boxes: len(boxes)}
+	for j, b := range boxes {
+		if j == 0 || b.Score < q.score {
+			q.score = b.Score
+		}
+	}
+
+	if req.Parses != nil && i < len(req.Regions) {
+		if typ := req.Types[req.Regions[i]]; typ != "" {
+			q.typed = true
+			q.parses = req.Parses(typ, boxes)
+		}
+	}
+	return q
+}
+
+// good: the value parses and every box is confident. An empty untyped region (an icon, a badge) is fine.
+func (q reading) good(minScore float64) bool {
+	if q.typed && !q.parses {
+		return false
+	}
+	return q.boxes == 0 || q.score >= minScore
+}
+
+// better prefers a parsed value, then confident boxes, then any text over none.
+func (q reading) better(o reading) bool {
+	if q.parses != o.parses {
+		return q.parses
+	}
+	if (q.boxes > 0) != (o.boxes > 0) {
+		return q.boxes > 0
+	}
+	return q.score > o.score
+}
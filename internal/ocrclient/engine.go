@@ -0,0 +1,113 @@
+package ocrclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// OCREngine recognizes text in regions of a screen.
+type OCREngine interface {
+	Name() string
+	Recognize(ctx context.Context, req EngineRequest) (domain.OCRResults, error)
+}
+
+// EngineRequest is one OCR pass over a frame.
+type EngineRequest struct {
+	FrameID   string                      // frame kept by the OCR service, "" – a fresh screenshot
+	Pixels    func() (image.Image, error) // pixels of the frame, for engines running locally; may be nil
+	DebugName string
+	Regions   []Region                                       // empty – the whole screen
+	Types     map[Region]string                              // value type expected in a region ("integer", "amount"…), for arbitration
+	Parses    func(typ string, boxes domain.OCRResults) bool // whether the boxes hold a value of typ; nil – types aren't checked
+}
+
+// image returns the pixels of the frame.
+func (r EngineRequest) image() (image.Image, error) {
+	if r.Pixels == nil {
+		return nil, fmt.Errorf("engine needs the frame pixels")
+	}
+	return r.Pixels()
+}
+
+// NewEngine creates the engine registered under name.
+func NewEngine(name string, client *Client, logger *slog.Logger) (OCREngine, error) {
+	switch name {
+	case domain.EngineHTTP:
+		return &HTTPEngine{Client: client}, nil
+	case domain.EngineTesseract:
+		return NewTesseractEngine(logger), nil
+	case domain.EngineRecorded:
+		viper.SetDefault("OCR_RECORDING", "references/ocr_recording.json")
+		return LoadRecordedEngine(viper.GetString("OCR_RECORDING"))
+	default:
+		return nil, fmt.Errorf("unknown OCR engine %q", name)
+	}
+}
+
+// HTTPEngine is the OCR service; with the client cache enabled, unchanged regions reuse earlier results.
+type HTTPEngine struct {
+	Client *Client
+}
+
+func (e *HTTPEngine) Name() string { return domain.EngineHTTP }
+
+// Recognize uses the cache only when the frame is kept by the service, as the cache hashes its pixels.
+func (e *HTTPEngine) Recognize(ctx context.Context, req EngineRequest) (domain.OCRResults, error) {
+	if e.Client.Cache == nil || req.FrameID == "" || req.Pixels == nil {
+		return e.Client.FetchOCRFrame(ctx, req.FrameID, req.DebugName, req.Regions)
+	}
+
+	img, err := req.Pixels()
+	if err != nil {
+		e.Client.Logger.Warn("⚠️ Frame download failed, OCR cache skipped", slog.Any("error", err))
+		return e.Client.FetchOCRFrame(ctx, req.FrameID, req.DebugName, req.Regions)
+	}
+	return e.Client.FetchOCRCached(ctx, req.FrameID, img, req.DebugName, req.Regions)
+}
+
+// RecordedEngine answers with results recorded earlier, e.g. a saved /ocr response.
+// Every request gets the recorded boxes that lie in its regions.
+type RecordedEngine struct {
+	Results domain.OCRResults
+	Err     error // returned instead of results, to test failures
+}
+
+// LoadRecordedEngine reads a JSON list of OCR zones in the /ocr response format.
+func LoadRecordedEngine(path string) (*RecordedEngine, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read OCR recording: %w", err)
+	}
+
+	var zones []OCRZone
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return nil, fmt.Errorf("decode OCR recording %s: %w", path, err)
+	}
+	return &RecordedEngine{Results: toOCRResults(zones)}, nil
+}
+
+func (e *RecordedEngine) Name() string { return domain.EngineRecorded }
+
+func (e *RecordedEngine) Recognize(_ context.Context, req EngineRequest) (domain.OCRResults, error) {
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	if len(req.Regions) == 0 {
+		return append(domain.OCRResults(nil), e.Results...), nil
+	}
+
+	var out domain.OCRResults
+	for _, r := range req.Regions {
+		out = append(out, within(e.Results, r.rect())...)
+	}
+	return out, nil
+}
@@ -0,0 +1,193 @@
+package ocrclient
+
+import (
+	"context"
+	"errors"
+	"image"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// countingEngine is a RecordedEngine that remembers the regions it was asked for.
+type countingEngine struct {
+	RecordedEngine
+	name  string
+	asked [][]Region
+}
+
+func (e *countingEngine) Name() string { return e.name }
+
+func (e *countingEngine) Recognize(ctx context.Context, req EngineRequest) (domain.OCRResults, error) {
+	e.asked = append(e.asked, req.Regions)
+	return e.RecordedEngine.Recognize(ctx, req)
+}
+
+func box(text string, score float64, x, y int) domain.OCRResult {
+	return domain.OCRResult{Text: text, Score: score, X: x, Y: y, Width: 40, Height: 20}
+}
+
+// isNumber stands in for the analyzer's parsers.
+func isNumber(_ string, boxes domain.OCRResults) bool {
+	if len(boxes) == 0 {
+		return false
+	}
+	_, err := strconv.Atoi(strings.ReplaceAll(boxes[0].Text, ",", ""))
+	return err == nil
+}
+
+func TestCompositeEngine(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	power := Region{X0: 0, Y0: 0, X1: 200, Y1: 50}
+	title := Region{X0: 0, Y0: 100, X1: 200, Y1: 150}
+	badge := Region{X0: 0, Y0: 200, X1: 200, Y1: 250}
+	req := EngineRequest{
+		Regions: []Region{power, title, badge},
+		Types:   map[Region]string{power: "integer"},
+		Parses:  isNumber,
+	}
+
+	tests := []struct {
+		name      string
+		primary   domain.OCRResults
+		fallback  domain.OCRResults
+		fallErr   error
+		wantAsked [][]Region
+		wantTexts []string
+	}{
+		{
+			name:      "confident readings are kept",
+			primary:   domain.OCRResults{box("25,431", 0.95, 10, 10), box("Mail", 0.9, 10, 110)},
+			fallback:  domain.OCRResults{box("99", 0.99, 10, 10)},
+			wantAsked: nil,
+			wantTexts: []string{"25,431", "Mail"},
+		},
+		{
+			name:      "an unparsable number is re-read",
+			primary:   domain.OCRResults{box("2S,43l", 0.95, 10, 10), box("Mail", 0.9, 10, 110)},
+			fallback:  domain.OCRResults{box("25,431", 0.7, 10, 10)},
+			wantAsked: [][]Region{{power}},
+			wantTexts: []string{"25,431", "Mail"},
+		},
+		{
+			name:      "a low score is re-read and the better box wins",
+			primary:   domain.OCRResults{box("25,431", 0.95, 10, 10), box("Maii", 0.4, 10, 110)},
+			fallback:  domain.OCRResults{box("Mail", 0.85, 10, 110)},
+			wantAsked: [][]Region{{title}},
+			wantTexts: []string{"25,431", "Mail"},
+		},
+		{
+			name:      "a worse fallback reading is dropped",
+			primary:   domain.OCRResults{box("25,431", 0.95, 10, 10), box("Maii", 0.4, 10, 110)},
+			fallback:  domain.OCRResults{box("Ma", 0.3, 10, 110)},
+			wantAsked: [][]Region{{title}},
+			wantTexts: []string{"25,431", "Maii"},
+		},
+		{
+			name:      "a failed fallback keeps the first reading",
+			primary:   domain.OCRResults{box("Maii", 0.4, 10, 110)},
+			fallErr:   errors.New("boom"),
+			wantAsked: [][]Region{{power, title}},
+			wantTexts: []string{"Maii"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &countingEngine{name: "second", RecordedEngine: RecordedEngine{Results: tt.fallback, Err: tt.fallErr}}
+			engine := &CompositeEngine{
+				Primary:  &RecordedEngine{Results: tt.primary},
+				Fallback: fallback,
+				MinScore: 0.8,
+				Logger:   logger,
+			}
+
+			got, err := engine.Recognize(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAsked, fallback.asked)
+
+			texts := make([]string, len(got))
+			for i, r := range got {
+				texts[i] = r.Text
+			}
+			assert.Equal(t, tt.wantTexts, texts)
+		})
+	}
+}
+
+func TestCompositeEngine_PrimaryFails(t *testing.T) {
+	engine := &CompositeEngine{
+		Primary:  &RecordedEngine{Err: errors.New("service down")},
+		Fallback: &RecordedEngine{Results: domain.OCRResults{box("Mail", 0.9, 10, 10)}},
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+	assert.Equal(t, "recorded+recorded", engine.Name())
+
+	got, err := engine.Recognize(context.Background(), EngineRequest{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Mail", got[0].Text)
+}
+
+func TestLoadRecordedEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ocr.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"box": [[10, 10], [90, 10], [90, 40], [10, 40]], "text": "Mail", "score": 0.93},
+		{"box": [[10, 300], [90, 330]], "text": "Claim", "score": 0.88}
+	]`), 0o644))
+
+	engine, err := LoadRecordedEngine(path)
+	require.NoError(t, err)
+
+	got, err := engine.Recognize(context.Background(), EngineRequest{Regions: []Region{{X0: 0, Y0: 0, X1: 100, Y1: 100}}})
+	require.NoError(t, err)
+	require.Len(t, got, 1, "only the boxes in the regions")
+	assert.Equal(t, domain.OCRResult{Text: "Mail", Score: 0.93, X: 10, Y: 10, Width: 80, Height: 30}, got[0])
+
+	got, err = engine.Recognize(context.Background(), EngineRequest{})
+	require.NoError(t, err)
+	assert.Len(t, got, 2, "the whole screen")
+
+	_, err = LoadRecordedEngine(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestParseTesseractTSV(t *testing.T) {
+	tsv := strings.Join([]string{
+		"level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext",
+		"1\t1\t0\t0\t0\t0\t0\t0\t300\t100\t-1\t",
+		"4\t1\t1\t1\t1\t0\t5\t5\t200\t20\t-1\t",
+		"5\t1\t1\t1\t1\t1\t5\t5\t60\t20\t96.5\tPower:",
+		"5\t1\t1\t1\t1\t2\t70\t6\t100\t19\t89.5\t25,431,870",
+		"5\t1\t1\t1\t2\t1\t5\t50\t40\t20\t80\tVIP",
+		"5\t1\t1\t1\t2\t2\t50\t50\t10\t20\t-1\t ",
+	}, "\n")
+
+	got, err := parseTesseractTSV([]byte(tsv), image.Pt(100, 1000))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "Power: 25,431,870", got[0].Text)
+	assert.InDelta(t, 0.93, got[0].Score, 1e-9)
+	assert.Equal(t, domain.OCRResult{Text: got[0].Text, Score: got[0].Score, X: 105, Y: 1005, Width: 165, Height: 20}, got[0])
+
+	assert.Equal(t, "VIP", got[1].Text)
+	assert.Equal(t, 1050, got[1].Y)
+
+	_, err = parseTesseractTSV([]byte("h\n5\t1\t1\t1\t1\t1\t5\t5\t60\t20\tbad\tx"), image.Point{})
+	assert.Error(t, err)
+}
+
+func TestTesseractEngine_NeedsPixels(t *testing.T) {
+	_, err := (&TesseractEngine{Path: "tesseract"}).Recognize(context.Background(), EngineRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package ocrclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Frame is a screenshot kept by the OCR service for a short time,
+// so OCR and icon search of one analysis pass see the same screen.
+type Frame struct {
+	ID     string `json:"frame_id"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// FrameRequest is the JSON payload for the /frame endpoint.
+type FrameRequest struct {
+	DeviceID string `json:"device_id,omitempty"`
+}
+
+// CaptureFrame asks the service to capture the screen once.
+func (c *Client) CaptureFrame(ctx context.Context) (frame *Frame, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.CaptureFrame")
+	defer func() { endSpan(span, err) }()
+
+	body, err := json.Marshal(FrameRequest{DeviceID: c.DeviceID})
+	if err != nil {
+		return nil, fmt.Errorf("marshal /frame payload: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/frame", body)
+	if err != nil {
+		return nil, fmt.Errorf("new request /frame: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http post /frame: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, b)
+	}
+
+	frame = &Frame{}
+	if err := json.NewDecoder(resp.Body).Decode(frame); err != nil {
+		return nil, fmt.Errorf("decode /frame json: %w", err)
+	}
+	return frame, nil
+}
+
+// FrameImage downloads a captured frame, e.g. for local template matching.
+func (c *Client) FrameImage(ctx context.Context, frameID string) (img image.Image, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.FrameImage", attribute.String("frame_id", frameID))
+	defer func() { endSpan(span, err) }()
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/frame/"+url.PathEscape(frameID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request /frame: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http get /frame: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, b)
+	}
+
+	img, err = png.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decode frame %s: %w", frameID, err)
+	}
+	return img, nil
+}
@@ -3,15 +3,16 @@
 package ocrclient
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"image"
 	"io"
 	"net/http"
-	"net/url"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
 )
 
@@ -54,21 +55,39 @@ func (r *FindImageResponse) ToRects() []image.Rectangle {
 // FetchOCRRequest is the JSON payload for the /ocr endpoint.
 type FetchOCRRequest struct {
 	DeviceID  string   `json:"device_id,omitempty"`
+	FrameID   string   `json:"frame_id,omitempty"`
 	DebugName string   `json:"debug_name,omitempty"`
 	Regions   []Region `json:"regions,omitempty"`
 }
 
 // FetchOCR performs a one‐shot OCR by POSTing JSON to the /ocr endpoint,
 // using a 20s timeout on the HTTP request.
-func (c *Client) FetchOCR(debugName string, regions []Region) (domain.OCRResults, error) {
+func (c *Client) FetchOCR(ctx context.Context, debugName string, regions []Region) (domain.OCRResults, error) {
+	return c.FetchOCRFrame(ctx, "", debugName, regions)
+}
+
+// FetchOCRFrame is FetchOCR on a frame captured by CaptureFrame (empty frameID – a fresh screenshot).
+func (c *Client) FetchOCRFrame(ctx context.Context, frameID, debugName string, regions []Region) (results domain.OCRResults, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.FetchOCR",
+		attribute.String("frame_id", frameID),
+		attribute.Int("regions", len(regions)),
+	)
+	defer func() { endSpan(span, err) }()
+
 	c.Logger.Info("🖼️  Fetching OCR",
 		"device_id", c.DeviceID,
+		"frame_id", frameID,
 		"debug_name", debugName,
 	)
 
+	if c.Transport == TransportWebSocket {
+		return c.fetchOCRStream(ctx, frameID, debugName, regions)
+	}
+
 	// prepare JSON body
 	reqBody := FetchOCRRequest{
 		DeviceID:  c.DeviceID,
+		FrameID:   frameID,
 		DebugName: debugName,
 		Regions:   regions,
 	}
@@ -78,11 +97,10 @@ func (c *Client) FetchOCR(debugName string, regions []Region) (domain.OCRResults
 	}
 
 	// build POST request
-	req, err := http.NewRequest("POST", c.ServiceURL+"/ocr", bytes.NewReader(body))
+	req, err := c.newRequest(ctx, http.MethodPost, "/ocr", body)
 	if err != nil {
 		return nil, fmt.Errorf("new request /ocr: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
 	// 3) Execute through c.HTTP — with automatic retry and timeout
 	resp, err := c.HTTP.Do(req)
@@ -104,11 +122,7 @@ func (c *Client) FetchOCR(debugName string, regions []Region) (domain.OCRResults
 	}
 
 	// convert to domain.OCRResults
-	results := make(domain.OCRResults, len(zones))
-	for i, z := range zones {
-		results[i] = z.ToOCRResult()
-	}
-	return results, nil
+	return toOCRResults(zones), nil
 }
 
 // WaitForTextRequest — payload for /wait_for_text.
@@ -121,7 +135,13 @@ type WaitForTextRequest struct {
 
 // WaitForText polls /wait_for_text until one of stopWords appears or timeout elapses.
 // timeout and interval are now time.Duration.
-func (c *Client) WaitForText(stopWords []string, timeout, interval time.Duration, debugName string) (domain.OCRResults, error) {
+func (c *Client) WaitForText(ctx context.Context, stopWords []string, timeout, interval time.Duration, debugName string) (results domain.OCRResults, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.WaitForText",
+		attribute.StringSlice("stop_words", stopWords),
+		attribute.String("timeout", timeout.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
 	c.Logger.Info("🖼️ Waiting for text",
 		"device_id", c.DeviceID,
 		"debug_name", debugName,
@@ -130,6 +150,10 @@ func (c *Client) WaitForText(stopWords []string, timeout, interval time.Duration
 		"interval", interval,
 	)
 
+	if c.Transport == TransportWebSocket {
+		return c.waitForTextStream(ctx, stopWords, timeout, interval, debugName)
+	}
+
 	// 1) Prepare request body
 	reqBody := WaitForTextRequest{
 		StopWords: stopWords,
@@ -142,25 +166,18 @@ func (c *Client) WaitForText(stopWords []string, timeout, interval time.Duration
 		return nil, fmt.Errorf("marshal /wait_for_text payload: %w", err)
 	}
 
-	// 2) Build URL with debug_name
-	u, err := url.Parse(c.ServiceURL + "/wait_for_text")
+	// 2) Build HTTP request, debug_name goes in the query
+	req, err := c.newRequest(ctx, http.MethodPost, "/wait_for_text", bodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("parse wait_for_text URL: %w", err)
+		return nil, fmt.Errorf("new request /wait_for_text: %w", err)
 	}
 	if debugName != "" {
-		q := u.Query()
+		q := req.URL.Query()
 		q.Set("debug_name", debugName)
-		u.RawQuery = q.Encode()
+		req.URL.RawQuery = q.Encode()
 	}
 
-	// 3) Build HTTP request
-	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("new request /wait_for_text: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// 4) Execute through c.HTTP (retry + timeout)
+	// 3) Execute through c.HTTP (retry + timeout)
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http post /wait_for_text: %w", err)
@@ -178,17 +195,14 @@ func (c *Client) WaitForText(stopWords []string, timeout, interval time.Duration
 	}
 
 	// Convert each OCRZone to OCRResult
-	results := make(domain.OCRResults, len(zones))
-	for i, z := range zones {
-		results[i] = z.ToOCRResult()
-	}
-	return results, nil
+	return toOCRResults(zones), nil
 }
 
 // FindImageRequest is the JSON payload for the /find_image endpoint.
 type FindImageRequest struct {
 	ImageName string   `json:"image_name"`
 	DeviceID  string   `json:"device_id,omitempty"`
+	FrameID   string   `json:"frame_id,omitempty"`
 	Threshold float64  `json:"threshold"`
 	DebugName string   `json:"debug_name,omitempty"`
 	Regions   []Region `json:"regions,omitempty"`
@@ -196,18 +210,35 @@ type FindImageRequest struct {
 
 // FindImage searches for all occurrences of imageName in the screen.
 // It uses a 30s timeout on the HTTP request.
-func (c *Client) FindImage(imageName string, threshold float64, debugName string) (*FindImageResponse, error) {
+func (c *Client) FindImage(ctx context.Context, imageName string, threshold float64, debugName string) (*FindImageResponse, error) {
+	return c.FindImageFrame(ctx, "", imageName, threshold, debugName)
+}
+
+// FindImageFrame is FindImage on a frame captured by CaptureFrame (empty frameID – a fresh screenshot).
+func (c *Client) FindImageFrame(ctx context.Context, frameID, imageName string, threshold float64, debugName string) (found *FindImageResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.FindImage",
+		attribute.String("frame_id", frameID),
+		attribute.String("image_name", imageName),
+	)
+	defer func() { endSpan(span, err) }()
+
 	c.Logger.Info("🖼️  Finding image",
 		"device_id", c.DeviceID,
+		"frame_id", frameID,
 		"image_name", imageName,
 		"threshold", threshold,
 		"debug_name", debugName,
 	)
 
+	if c.Transport == TransportWebSocket {
+		return c.findImageStream(ctx, frameID, imageName, threshold, debugName)
+	}
+
 	// 1) Prepare request
 	reqBody := FindImageRequest{
 		ImageName: imageName,
 		DeviceID:  c.DeviceID,
+		FrameID:   frameID,
 		Threshold: threshold,
 		DebugName: debugName,
 	}
@@ -217,12 +248,10 @@ func (c *Client) FindImage(imageName string, threshold float64, debugName string
 	}
 
 	// 2) Build HTTP request
-	url := fmt.Sprintf("%s/find_image", c.ServiceURL)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
+	req, err := c.newRequest(ctx, http.MethodPost, "/find_image", bodyBytes)
 	if err != nil {
 		return nil, fmt.Errorf("new request /find_image: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
 	// 3) Execute through c.HTTP (retry + timeout)
 	resp, err := c.HTTP.Do(req)
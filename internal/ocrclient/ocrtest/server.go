@@ -0,0 +1,218 @@
+// Package ocrtest provides a stand-in for the /ws protocol of the OCR service, for tests.
+package ocrtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+// Server answers /ws messages like the OCR service does, with pluggable OCR and icon search.
+type Server struct {
+	// OCR returns the zones of one screen (whole screen when regions are empty).
+	OCR func(frameID string, q ocrclient.OCRQuery) ([]ocrclient.OCRZone, error)
+	// FindImage answers one icon search.
+	FindImage func(frameID string, q ocrclient.FindImageQuery) (ocrclient.FindImageResponse, error)
+
+	mu        sync.Mutex
+	requests  []ocrclient.StreamRequest
+	cancelled []string
+	frames    int
+}
+
+// Start serves the protocol; its URL is the service URL, the caller closes it.
+func (s *Server) Start() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/ws", websocket.Handler(s.serve))
+	return httptest.NewServer(mux)
+}
+
+// Requests returns every non-cancel message received so far.
+func (s *Server) Requests() []ocrclient.StreamRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ocrclient.StreamRequest(nil), s.requests...)
+}
+
+// Cancelled returns the ids of cancelled requests.
+func (s *Server) Cancelled() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cancelled...)
+}
+
+func (s *Server) serve(conn *websocket.Conn) {
+	var sendMu sync.Mutex
+	send := func(resp ocrclient.StreamResponse) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		_ = websocket.JSON.Send(conn, resp)
+	}
+
+	var wg sync.WaitGroup
+	cancels := make(map[string]context.CancelFunc)
+	var cancelsMu sync.Mutex
+
+	defer func() {
+		cancelsMu.Lock()
+		for _, cancel := range cancels {
+			cancel()
+		}
+		cancelsMu.Unlock()
+		wg.Wait()
+	}()
+
+	for {
+		var req ocrclient.StreamRequest
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return
+		}
+
+		if req.Type == ocrclient.StreamCancel {
+			s.mu.Lock()
+			s.cancelled = append(s.cancelled, req.ID)
+			s.mu.Unlock()
+
+			cancelsMu.Lock()
+			if cancel, ok := cancels[req.ID]; ok {
+				cancel()
+			}
+			cancelsMu.Unlock()
+			continue
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		s.mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelsMu.Lock()
+		cancels[req.ID] = cancel
+		cancelsMu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				cancelsMu.Lock()
+				delete(cancels, req.ID)
+				cancelsMu.Unlock()
+				cancel()
+			}()
+
+			resp, err := s.handle(ctx, req, send)
+			if ctx.Err() != nil {
+				return // cancelled requests get no answer
+			}
+			if err != nil {
+				resp = ocrclient.StreamResponse{Type: ocrclient.StreamError, Error: err.Error()}
+			}
+			resp.ID = req.ID
+			send(resp)
+		}()
+	}
+}
+
+func (s *Server) handle(ctx context.Context, req ocrclient.StreamRequest, send func(ocrclient.StreamResponse)) (ocrclient.StreamResponse, error) {
+	switch req.Type {
+	case ocrclient.StreamBatch:
+		return s.batch(req)
+	case ocrclient.StreamWaitForText:
+		if req.WaitQuery == nil {
+			return ocrclient.StreamResponse{}, fmt.Errorf("wait_for_text without a query")
+		}
+		return s.waitForText(ctx, req.ID, *req.WaitQuery, send)
+	default:
+		return ocrclient.StreamResponse{}, fmt.Errorf("unknown message type %q", req.Type)
+	}
+}
+
+func (s *Server) batch(req ocrclient.StreamRequest) (ocrclient.StreamResponse, error) {
+	// every part of the batch sees the same screen
+	frameID := req.FrameID
+	parts := len(req.FindImage)
+	if req.OCR != nil {
+		parts++
+	}
+	if frameID == "" && parts > 1 {
+		s.mu.Lock()
+		s.frames++
+		frameID = fmt.Sprintf("frame-%d", s.frames)
+		s.mu.Unlock()
+	}
+
+	resp := ocrclient.StreamResponse{Type: ocrclient.StreamResult}
+	if req.OCR != nil {
+		zones, err := s.ocr(frameID, *req.OCR)
+		if err != nil {
+			return resp, err
+		}
+		resp.OCR = zones
+	}
+
+	for _, q := range req.FindImage {
+		found := ocrclient.FindImageResponse{}
+		if s.FindImage != nil {
+			var err error
+			if found, err = s.FindImage(frameID, q); err != nil {
+				return resp, err
+			}
+		}
+		resp.FindImage = append(resp.FindImage, found)
+	}
+	return resp, nil
+}
+
+// waitForText polls OCR every interval, streaming the polls without a stop word.
+func (s *Server) waitForText(ctx context.Context, id string, q ocrclient.WaitQuery, send func(ocrclient.StreamResponse)) (ocrclient.StreamResponse, error) {
+	deadline := time.Now().Add(time.Duration(q.Timeout * float64(time.Second)))
+
+	for {
+		zones, err := s.ocr("", ocrclient.OCRQuery{DebugName: q.DebugName, Regions: q.Regions})
+		if err != nil {
+			return ocrclient.StreamResponse{}, err
+		}
+		if hasStopWord(zones, q.StopWords) {
+			return ocrclient.StreamResponse{Type: ocrclient.StreamResult, OCR: zones}, nil
+		}
+
+		send(ocrclient.StreamResponse{ID: id, Type: ocrclient.StreamProgress, OCR: zones})
+
+		if !time.Now().Before(deadline) {
+			return ocrclient.StreamResponse{Type: ocrclient.StreamResult}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ocrclient.StreamResponse{}, ctx.Err()
+		case <-time.After(time.Duration(q.Interval * float64(time.Second))):
+		}
+	}
+}
+
+func (s *Server) ocr(frameID string, q ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+	if s.OCR == nil {
+		return nil, nil
+	}
+	return s.OCR(frameID, q)
+}
+
+func hasStopWord(zones []ocrclient.OCRZone, stopWords []string) bool {
+	for _, z := range zones {
+		text := strings.ToLower(z.Text)
+		for _, w := range stopWords {
+			if strings.Contains(text, strings.ToLower(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
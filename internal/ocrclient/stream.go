@@ -0,0 +1,291 @@
+package ocrclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// Transports of the OCR client.
+const (
+	TransportHTTP      = "http"
+	TransportWebSocket = "ws"
+)
+
+// Message types of the /ws protocol.
+const (
+	StreamBatch       = "batch"
+	StreamWaitForText = "wait_for_text"
+	StreamCancel      = "cancel"
+	StreamProgress    = "progress"
+	StreamResult      = "result"
+	StreamError       = "error"
+)
+
+// streamTimeout bounds a streamed call of the Client methods.
+const streamTimeout = 40 * time.Second
+
+// ErrStreamClosed is returned for calls on a broken connection.
+var ErrStreamClosed = errors.New("ocr stream closed")
+
+// OCRQuery is the OCR part of a batch.
+type OCRQuery struct {
+	DebugName string   `json:"debug_name,omitempty"`
+	Regions   []Region `json:"regions,omitempty"`
+}
+
+// FindImageQuery is one icon search of a batch.
+type FindImageQuery struct {
+	ImageName string   `json:"image_name"`
+	Threshold float64  `json:"threshold"`
+	DebugName string   `json:"debug_name,omitempty"`
+	Regions   []Region `json:"regions,omitempty"`
+}
+
+// WaitQuery is the payload of a streamed wait_for_text.
+type WaitQuery struct {
+	StopWords []string `json:"stop_words"`
+	Timeout   float64  `json:"timeout"`
+	Interval  float64  `json:"interval"`
+	DebugName string   `json:"debug_name,omitempty"`
+	Regions   []Region `json:"regions,omitempty"`
+}
+
+// StreamRequest is a client message of the /ws protocol.
+type StreamRequest struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	DeviceID  string            `json:"device_id,omitempty"`
+	FrameID   string            `json:"frame_id,omitempty"`
+	OCR       *OCRQuery         `json:"ocr,omitempty"`
+	FindImage []FindImageQuery  `json:"find_image,omitempty"`
+	Trace     map[string]string `json:"trace,omitempty"` // W3C trace context of the caller
+	*WaitQuery
+}
+
+// StreamResponse is a server message of the /ws protocol.
+type StreamResponse struct {
+	ID        string              `json:"id"`
+	Type      string              `json:"type"`
+	OCR       []OCRZone           `json:"ocr,omitempty"`
+	FindImage []FindImageResponse `json:"find_image,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// BatchRequest combines OCR and several icon searches evaluated on one screen.
+type BatchRequest struct {
+	FrameID   string // empty – the service captures the screen once for the whole batch
+	OCR       *OCRQuery
+	FindImage []FindImageQuery
+}
+
+// BatchResult holds the answers in the order of the request.
+type BatchResult struct {
+	OCR       domain.OCRResults
+	FindImage []*FindImageResponse
+}
+
+// StreamClient is a persistent WebSocket connection to the OCR service.
+// Calls are multiplexed by id and can be cancelled through their context.
+type StreamClient struct {
+	deviceID string
+	logger   *slog.Logger
+	conn     *websocket.Conn
+
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]*pendingCall
+	closed  chan struct{}
+	err     error
+}
+
+type pendingCall struct {
+	ch   chan StreamResponse
+	done chan struct{}
+}
+
+// DialStream connects to the /ws endpoint of serviceURL.
+func DialStream(ctx context.Context, serviceURL, deviceID string, logger *slog.Logger) (*StreamClient, error) {
+	wsURL := "ws" + strings.TrimPrefix(serviceURL, "http") + "/ws"
+
+	config, err := websocket.NewConfig(wsURL, serviceURL)
+	if err != nil {
+		return nil, fmt.Errorf("ws config: %w", err)
+	}
+
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", wsURL, err)
+	}
+
+	s := &StreamClient{
+		deviceID: deviceID,
+		logger:   logger,
+		conn:     conn,
+		pending:  make(map[string]*pendingCall),
+		closed:   make(chan struct{}),
+	}
+	go s.readLoop()
+
+	return s, nil
+}
+
+// Close closes the connection; pending calls fail with ErrStreamClosed.
+func (s *StreamClient) Close() error {
+	return s.conn.Close()
+}
+
+// Err returns the reason the connection broke, nil while it is alive.
+func (s *StreamClient) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Batch runs OCR and icon searches in one round trip.
+func (s *StreamClient) Batch(ctx context.Context, req BatchRequest) (*BatchResult, error) {
+	resp, err := s.call(ctx, StreamRequest{
+		Type:      StreamBatch,
+		DeviceID:  s.deviceID,
+		FrameID:   req.FrameID,
+		OCR:       req.OCR,
+		FindImage: req.FindImage,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.FindImage) != len(req.FindImage) {
+		return nil, fmt.Errorf("batch: %d find_image answers for %d queries", len(resp.FindImage), len(req.FindImage))
+	}
+
+	result := &BatchResult{OCR: toOCRResults(resp.OCR)}
+	for i := range resp.FindImage {
+		result.FindImage = append(result.FindImage, &resp.FindImage[i])
+	}
+	return result, nil
+}
+
+// WaitForText streams OCR polls until a stop word shows up or the query times out.
+// onProgress (optional) receives every poll that didn't match.
+func (s *StreamClient) WaitForText(ctx context.Context, q WaitQuery, onProgress func(domain.OCRResults)) (domain.OCRResults, error) {
+	resp, err := s.call(ctx, StreamRequest{
+		Type:      StreamWaitForText,
+		DeviceID:  s.deviceID,
+		WaitQuery: &q,
+	}, func(progress StreamResponse) {
+		if onProgress != nil {
+			onProgress(toOCRResults(progress.OCR))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toOCRResults(resp.OCR), nil
+}
+
+// call sends req and waits for its result; a cancelled ctx cancels the request on the server.
+func (s *StreamClient) call(ctx context.Context, req StreamRequest, onProgress func(StreamResponse)) (StreamResponse, error) {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return StreamResponse{}, s.err
+	}
+	s.nextID++
+	req.ID = strconv.FormatUint(s.nextID, 10)
+	call := &pendingCall{ch: make(chan StreamResponse, 1), done: make(chan struct{})}
+	s.pending[req.ID] = call
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, req.ID)
+		s.mu.Unlock()
+		close(call.done)
+	}()
+
+	req.Trace = traceCarrier(ctx)
+	if err := s.send(req); err != nil {
+		return StreamResponse{}, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.send(StreamRequest{ID: req.ID, Type: StreamCancel}); err != nil {
+				s.logger.Warn("⚠️ OCR stream cancel failed", slog.String("id", req.ID), slog.Any("error", err))
+			}
+			return StreamResponse{}, ctx.Err()
+
+		case <-s.closed:
+			return StreamResponse{}, s.Err()
+
+		case resp := <-call.ch:
+			switch resp.Type {
+			case StreamProgress:
+				if onProgress != nil {
+					onProgress(resp)
+				}
+			case StreamError:
+				return StreamResponse{}, fmt.Errorf("%s: %s", req.Type, resp.Error)
+			default:
+				return resp, nil
+			}
+		}
+	}
+}
+
+func (s *StreamClient) send(req StreamRequest) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	if err := websocket.JSON.Send(s.conn, req); err != nil {
+		return fmt.Errorf("ws send %s: %w", req.Type, err)
+	}
+	return nil
+}
+
+// readLoop routes responses to their calls until the connection breaks.
+func (s *StreamClient) readLoop() {
+	for {
+		var resp StreamResponse
+		if err := websocket.JSON.Receive(s.conn, &resp); err != nil {
+			s.mu.Lock()
+			s.err = fmt.Errorf("%w: %v", ErrStreamClosed, err)
+			s.mu.Unlock()
+			close(s.closed)
+			return
+		}
+
+		s.mu.Lock()
+		call, ok := s.pending[resp.ID]
+		s.mu.Unlock()
+		if !ok {
+			continue // cancelled meanwhile
+		}
+
+		select {
+		case call.ch <- resp:
+		case <-call.done:
+		}
+	}
+}
+
+func toOCRResults(zones []OCRZone) domain.OCRResults {
+	results := make(domain.OCRResults, len(zones))
+	for i, z := range zones {
+		results[i] = z.ToOCRResult()
+	}
+	return results
+}
@@ -0,0 +1,168 @@
+package ocrclient_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient/ocrtest"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+func dial(t *testing.T, server *ocrtest.Server) *ocrclient.StreamClient {
+	t.Helper()
+
+	httpServer := server.Start()
+	t.Cleanup(httpServer.Close)
+
+	stream, err := ocrclient.DialStream(context.Background(), httpServer.URL, "device-1", testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = stream.Close() })
+	return stream
+}
+
+func TestStream_Batch(t *testing.T) {
+	var mu sync.Mutex
+	var frames []string
+
+	server := &ocrtest.Server{
+		OCR: func(frameID string, _ ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+			mu.Lock()
+			frames = append(frames, frameID)
+			mu.Unlock()
+			return []ocrclient.OCRZone{{Text: "Chests", Box: [][]int{{10, 20}, {110, 60}}}}, nil
+		},
+		FindImage: func(frameID string, q ocrclient.FindImageQuery) (ocrclient.FindImageResponse, error) {
+			mu.Lock()
+			frames = append(frames, frameID)
+			mu.Unlock()
+			return ocrclient.FindImageResponse{Found: q.ImageName == "claim"}, nil
+		},
+	}
+	stream := dial(t, server)
+
+	res, err := stream.Batch(context.Background(), ocrclient.BatchRequest{
+		OCR:       &ocrclient.OCRQuery{},
+		FindImage: []ocrclient.FindImageQuery{{ImageName: "claim", Threshold: 0.9}, {ImageName: "close", Threshold: 0.9}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, domain.OCRResults{{Text: "Chests", X: 10, Y: 20, Width: 100, Height: 40}}, res.OCR)
+	require.True(t, res.FindImage[0].Found)
+	require.False(t, res.FindImage[1].Found)
+
+	require.Len(t, server.Requests(), 1, "one round trip")
+	require.Equal(t, []string{"frame-1", "frame-1", "frame-1"}, frames, "the batch shares one screen")
+}
+
+func TestStream_WaitForTextStreamsPolls(t *testing.T) {
+	polls := 0
+	server := &ocrtest.Server{
+		OCR: func(string, ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+			polls++
+			if polls < 3 {
+				return []ocrclient.OCRZone{{Text: "Loading"}}, nil
+			}
+			return []ocrclient.OCRZone{{Text: "Welcome back"}}, nil
+		},
+	}
+	stream := dial(t, server)
+
+	var progress []string
+	res, err := stream.WaitForText(context.Background(), ocrclient.WaitQuery{
+		StopWords: []string{"welcome"},
+		Timeout:   5,
+		Interval:  0.01,
+	}, func(r domain.OCRResults) { progress = append(progress, r[0].Text) })
+	require.NoError(t, err)
+
+	require.Equal(t, "Welcome back", res[0].Text)
+	require.Equal(t, []string{"Loading", "Loading"}, progress)
+}
+
+func TestStream_Cancel(t *testing.T) {
+	server := &ocrtest.Server{
+		OCR: func(string, ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+			return []ocrclient.OCRZone{{Text: "Loading"}}, nil
+		},
+	}
+	stream := dial(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := stream.WaitForText(ctx, ocrclient.WaitQuery{StopWords: []string{"never"}, Timeout: 30, Interval: 0.01}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Eventually(t, func() bool { return len(server.Cancelled()) == 1 }, time.Second, 10*time.Millisecond,
+		"the server is told to stop polling")
+
+	// the connection stays usable
+	_, err = stream.Batch(context.Background(), ocrclient.BatchRequest{OCR: &ocrclient.OCRQuery{}})
+	require.NoError(t, err)
+}
+
+func TestStream_ErrorsAndClose(t *testing.T) {
+	server := &ocrtest.Server{
+		OCR: func(string, ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+			return nil, errors.New("paddle is down")
+		},
+	}
+	stream := dial(t, server)
+
+	_, err := stream.Batch(context.Background(), ocrclient.BatchRequest{OCR: &ocrclient.OCRQuery{}})
+	require.ErrorContains(t, err, "paddle is down")
+
+	require.NoError(t, stream.Close())
+	require.Eventually(t, func() bool { return stream.Err() != nil }, time.Second, 10*time.Millisecond)
+
+	_, err = stream.Batch(context.Background(), ocrclient.BatchRequest{OCR: &ocrclient.OCRQuery{}})
+	require.ErrorIs(t, err, ocrclient.ErrStreamClosed)
+}
+
+func TestClient_WebSocketTransport(t *testing.T) {
+	server := &ocrtest.Server{
+		OCR: func(string, ocrclient.OCRQuery) ([]ocrclient.OCRZone, error) {
+			return []ocrclient.OCRZone{{Text: "Mail"}}, nil
+		},
+		FindImage: func(string, ocrclient.FindImageQuery) (ocrclient.FindImageResponse, error) {
+			return ocrclient.FindImageResponse{Found: true}, nil
+		},
+	}
+	httpServer := server.Start()
+	t.Cleanup(httpServer.Close)
+
+	client := &ocrclient.Client{
+		ServiceURL: httpServer.URL,
+		DeviceID:   "device-1",
+		Logger:     testLogger,
+		Transport:  ocrclient.TransportWebSocket,
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	res, err := client.FetchOCR(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "Mail", res[0].Text)
+
+	found, err := client.FindImage(context.Background(), "claim", 0.9, "")
+	require.NoError(t, err)
+	require.True(t, found.Found)
+
+	res, err = client.WaitForText(context.Background(), []string{"mail"}, time.Second, 10*time.Millisecond, "")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	require.Len(t, server.Requests(), 3, "all calls share one connection")
+	for _, req := range server.Requests() {
+		require.Equal(t, "device-1", req.DeviceID)
+	}
+}
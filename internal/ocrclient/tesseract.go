@@ -0,0 +1,166 @@
+package ocrclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// TesseractEngine runs the tesseract CLI locally on the frame pixels, one call per region.
+type TesseractEngine struct {
+	Path   string // tesseract binary
+	Lang   string // traineddata, e.g. "eng" or "eng+rus"
+	PSM    int    // page segmentation mode
+	Logger *slog.Logger
+}
+
+// NewTesseractEngine creates the engine configured by TESSERACT_PATH, TESSERACT_LANG and TESSERACT_PSM.
+func NewTesseractEngine(logger *slog.Logger) *TesseractEngine {
+	viper.SetDefault("TESSERACT_PATH", "tesseract")
+	viper.SetDefault("TESSERACT_LANG", "eng")
+	viper.SetDefault("TESSERACT_PSM", 6)
+
+	return &TesseractEngine{
+		Path:   viper.GetString("TESSERACT_PATH"),
+		Lang:   viper.GetString("TESSERACT_LANG"),
+		PSM:    viper.GetInt("TESSERACT_PSM"),
+		Logger: logger,
+	}
+}
+
+func (e *TesseractEngine) Name() string { return domain.EngineTesseract }
+
+func (e *TesseractEngine) Recognize(ctx context.Context, req EngineRequest) (domain.OCRResults, error) {
+	img, err := req.image()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract: %w", err)
+	}
+
+	rects := make([]image.Rectangle, 0, len(req.Regions))
+	for _, r := range req.Regions {
+		rects = append(rects, r.rect().Intersect(img.Bounds()))
+	}
+	if len(req.Regions) == 0 {
+		rects = append(rects, img.Bounds())
+	}
+
+	var out domain.OCRResults
+	for _, rect := range rects {
+		if rect.Empty() {
+			continue
+		}
+
+		res, err := e.recognizeRect(ctx, img, rect)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+
+	if e.Logger != nil {
+		e.Logger.Debug("🔤 Tesseract OCR done", "debug_name", req.DebugName, "regions", len(rects), "boxes", len(out))
+	}
+	return out, nil
+}
+
+// recognizeRect runs tesseract on one crop and maps the boxes back to screen coordinates.
+func (e *TesseractEngine) recognizeRect(ctx context.Context, img image.Image, rect image.Rectangle) (domain.OCRResults, error) {
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(crop, crop.Bounds(), img, rect.Min, draw.Src)
+
+	var in bytes.Buffer
+	if err := png.Encode(&in, crop); err != nil {
+		return nil, fmt.Errorf("tesseract: encode crop: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, e.Path, "stdin", "stdout",
+		"-l", e.Lang, "--psm", strconv.Itoa(e.PSM), "tsv")
+	cmd.Stdin = &in
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTesseractTSV(data, rect.Min)
+}
+
+// parseTesseractTSV joins the words of every text line of tesseract's TSV output into one box.
+// Score is the mean word confidence scaled to 0..1, as the OCR service reports it.
+func parseTesseractTSV(data []byte, offset image.Point) (domain.OCRResults, error) {
+	type line struct {
+		words []string
+		box   image.Rectangle
+		conf  float64
+	}
+
+	var (
+		lines []*line
+		index = make(map[string]*line)
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 0; scanner.Scan(); n++ {
+		cols := strings.Split(scanner.Text(), "\t")
+		if n == 0 || len(cols) < 12 || cols[0] != "5" {
+			continue // header and non-word levels
+		}
+
+		text := strings.TrimSpace(cols[11])
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("tesseract: bad confidence %q", cols[10])
+		}
+		if text == "" || conf < 0 {
+			continue
+		}
+
+		var xywh [4]int
+		for i := range xywh {
+			if xywh[i], err = strconv.Atoi(cols[6+i]); err != nil {
+				return nil, fmt.Errorf("tesseract: bad box %q", cols[6+i])
+			}
+		}
+		box := image.Rect(xywh[0], xywh[1], xywh[0]+xywh[2], xywh[1]+xywh[3]).Add(offset)
+
+		key := strings.Join(cols[1:5], ".") // page.block.par.line
+		l, ok := index[key]
+		if !ok {
+			l = &line{box: box}
+			index[key] = l
+			lines = append(lines, l)
+		}
+		l.words = append(l.words, text)
+		l.box = l.box.Union(box)
+		l.conf += conf
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("tesseract: read output: %w", err)
+	}
+
+	out := make(domain.OCRResults, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, domain.OCRResult{
+			Text:   strings.Join(l.words, " "),
+			Score:  l.conf / float64(len(l.words)) / 100,
+			X:      l.box.Min.X,
+			Y:      l.box.Min.Y,
+			Width:  l.box.Dx(),
+			Height: l.box.Dy(),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package ocrclient
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// streamConn returns the WebSocket connection, dialing again if the previous one broke.
+func (c *Client) streamConn(ctx context.Context) (*StreamClient, error) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	if c.stream != nil && c.stream.Err() == nil {
+		return c.stream, nil
+	}
+
+	stream, err := DialStream(ctx, c.ServiceURL, c.DeviceID, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+	c.stream = stream
+	return stream, nil
+}
+
+// Batch runs OCR and several icon searches on one screen.
+// Over the WebSocket transport it is a single round trip; over HTTP the screen is captured
+// as a frame and the requests follow one by one.
+func (c *Client) Batch(ctx context.Context, req BatchRequest) (result *BatchResult, err error) {
+	ctx, span := c.startSpan(ctx, "ocr.Batch",
+		attribute.String("frame_id", req.FrameID),
+		attribute.Int("find_image", len(req.FindImage)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if c.Transport == TransportWebSocket {
+		stream, err := c.streamConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return stream.Batch(ctx, req)
+	}
+
+	frameID := req.FrameID
+	if frameID == "" && len(req.FindImage)+boolToInt(req.OCR != nil) > 1 {
+		if frame, err := c.CaptureFrame(ctx); err == nil {
+			frameID = frame.ID
+		}
+	}
+
+	result = &BatchResult{}
+	if req.OCR != nil {
+		ocr, err := c.FetchOCRFrame(ctx, frameID, req.OCR.DebugName, req.OCR.Regions)
+		if err != nil {
+			return nil, err
+		}
+		result.OCR = ocr
+	}
+
+	for _, q := range req.FindImage {
+		found, err := c.FindImageFrame(ctx, frameID, q.ImageName, q.Threshold, q.DebugName)
+		if err != nil {
+			return nil, err
+		}
+		result.FindImage = append(result.FindImage, found)
+	}
+	return result, nil
+}
+
+// fetchOCRStream is FetchOCRFrame over the WebSocket transport.
+func (c *Client) fetchOCRStream(ctx context.Context, frameID, debugName string, regions []Region) (domain.OCRResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+
+	res, err := c.Batch(ctx, BatchRequest{FrameID: frameID, OCR: &OCRQuery{DebugName: debugName, Regions: regions}})
+	if err != nil {
+		return nil, err
+	}
+	return res.OCR, nil
+}
+
+// findImageStream is FindImageFrame over the WebSocket transport.
+func (c *Client) findImageStream(ctx context.Context, frameID, imageName string, threshold float64, debugName string) (*FindImageResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+
+	res, err := c.Batch(ctx, BatchRequest{
+		FrameID:   frameID,
+		FindImage: []FindImageQuery{{ImageName: imageName, Threshold: threshold, DebugName: debugName}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.FindImage[0], nil
+}
+
+// waitForTextStream is WaitForText over the WebSocket transport.
+func (c *Client) waitForTextStream(ctx context.Context, stopWords []string, timeout, interval time.Duration, debugName string) (domain.OCRResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+streamTimeout)
+	defer cancel()
+
+	stream, err := c.streamConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stream.WaitForText(ctx, WaitQuery{
+		StopWords: stopWords,
+		Timeout:   timeout.Seconds(),
+		Interval:  interval.Seconds(),
+		DebugName: debugName,
+	}, nil)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Close closes the WebSocket connection, if any.
+func (c *Client) Close() error {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	if c.stream == nil {
+		return nil
+	}
+	err := c.stream.Close()
+	c.stream = nil
+	return err
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoNumber   = errors.New("no number")
+	ErrNoDuration = errors.New("no duration")
+	ErrNoDate     = errors.New("no date")
+)
+
+// Locale describes how the game UI of one language writes numbers and durations.
+type Locale struct {
+	Name     string
+	Decimal  rune                     // decimal separator of suffixed amounts ("4,3M" in ru)
+	Suffixes map[string]float64       // amount suffixes, matched case-insensitively
+	Units    map[string]time.Duration // duration units; a unit also matches its longer forms ("h" – "hours")
+
+	unitRe *regexp.Regexp
+}
+
+// Locales of the game UI. Latin K/M/B and d/h/m/s are understood by all of them:
+// the game keeps them in some screens of every language.
+var (
+	English = newLocale("en", '.', nil, nil)
+
+	Russian = newLocale("ru", ',',
+		map[string]float64{"тыс": 1e3, "к": 1e3, "млн": 1e6, "м": 1e6, "млрд": 1e9, "б": 1e9},
+		map[string]time.Duration{"д": day, "ч": time.Hour, "м": time.Minute, "с": time.Second},
+	)
+
+	Chinese = newLocale("zh", '.',
+		map[string]float64{"万": 1e4, "亿": 1e8},
+		map[string]time.Duration{"天": day, "小时": time.Hour, "时": time.Hour, "分": time.Minute, "秒": time.Second},
+	)
+)
+
+const day = 24 * time.Hour
+
+// LocaleByName returns the locale of OCR_LOCALE ("en", "ru", "zh").
+func LocaleByName(name string) (Locale, error) {
+	for _, l := range []Locale{English, Russian, Chinese} {
+		if strings.EqualFold(l.Name, name) {
+			return l, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("unknown locale %q", name)
+}
+
+func newLocale(name string, decimal rune, suffixes map[string]float64, units map[string]time.Duration) Locale {
+	l := Locale{
+		Name:     name,
+		Decimal:  decimal,
+		Suffixes: map[string]float64{"k": 1e3, "m": 1e6, "b": 1e9},
+		Units:    map[string]time.Duration{"d": day, "h": time.Hour, "m": time.Minute, "s": time.Second},
+	}
+	for k, v := range suffixes {
+		l.Suffixes[k] = v
+	}
+	for k, v := range units {
+		l.Units[k] = v
+	}
+
+	l.unitRe = regexp.MustCompile(`(\d+)\s*(` + alternatives(l.Units) + `)`)
+	return l
+}
+
+// alternatives builds a case-insensitive regexp alternation of the keys, longest first.
+func alternatives[V any](m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, regexp.QuoteMeta(k))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return `(?i:` + strings.Join(keys, "|") + `)`
+}
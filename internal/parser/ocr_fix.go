@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// digitConfusions are letters OCR returns for digits.
+var digitConfusions = map[rune]rune{
+	'O': '0', 'o': '0', 'Q': '0',
+	'I': '1', 'l': '1', '|': '1',
+	'Z': '2', 'z': '2',
+	'S': '5', 's': '5',
+	'B': '8',
+}
+
+// FixDigits replaces letters OCR confuses with digits ("1O5" → "105", "l2:3O" → "12:30") and full-width
+// digits. Only words of digits and confusable letters are fixed, so "VIP 4" stays and a trailing
+// suffix or unit ("1B", "30s") keeps its meaning.
+func (l Locale) FixDigits(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return '0' + (r - '０')
+		case r == '：':
+			return ':'
+		case r == '，':
+			return ','
+		case r == '．':
+			return '.'
+		}
+		return r
+	}, s)
+
+	words := strings.SplitAfter(s, " ")
+	for i, w := range words {
+		words[i] = l.fixWord(w)
+	}
+	return strings.Join(words, "")
+}
+
+func (l Locale) fixWord(w string) string {
+	runes := []rune(w)
+
+	digits, end := 0, len(runes)
+	for i, r := range runes {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case l.isSuffix(string(runes[i:])):
+			end = i
+		case digitConfusions[r] != 0, unicode.IsPunct(r), unicode.IsSpace(r), unicode.IsSymbol(r):
+			continue
+		default:
+			return w // a real word
+		}
+		if end != len(runes) {
+			break
+		}
+	}
+	if digits == 0 {
+		return w
+	}
+
+	for i := 0; i < end; i++ {
+		if d, ok := digitConfusions[runes[i]]; ok {
+			runes[i] = d
+		}
+	}
+	return string(runes)
+}
+
+// isSuffix reports whether the rest of a word is an amount suffix or a duration unit ("M", "тыс.", "s)").
+func (l Locale) isSuffix(rest string) bool {
+	rest = strings.ToLower(strings.TrimRightFunc(rest, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}))
+	if _, ok := l.Suffixes[rest]; ok {
+		return true
+	}
+	_, ok := l.Units[rest]
+	return ok
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	compactDurationRe = regexp.MustCompile(`^(\d+)d(\d{2})(\d{2})(\d{2})$`)
+	clockRe           = regexp.MustCompile(`(\d+):(\d{2})(?::(\d{2}))?`)
+)
+
+// ParseDuration recognizes countdowns like
+//   - 01:23:45, 23:45     → h:m:s, m:s
+//   - 2d 03:04:05         → days and a clock
+//   - 42d171612           → 42 days 17 h 16 m 12 s
+//   - 3d4h30m, 1ч 30м     → units of the locale
+func (l Locale) ParseDuration(s string) (time.Duration, error) {
+	clean := strings.ToLower(l.FixDigits(s))
+
+	if m := compactDurationRe.FindStringSubmatch(strings.ReplaceAll(clean, " ", "")); m != nil {
+		return atoi(m[1])*day + atoi(m[2])*time.Hour + atoi(m[3])*time.Minute + atoi(m[4])*time.Second, nil
+	}
+
+	var d time.Duration
+	found := false
+
+	if loc := clockRe.FindStringSubmatchIndex(clean); loc != nil {
+		m := clockRe.FindStringSubmatch(clean)
+		if m[3] != "" {
+			d = atoi(m[1])*time.Hour + atoi(m[2])*time.Minute + atoi(m[3])*time.Second
+		} else {
+			d = atoi(m[1])*time.Minute + atoi(m[2])*time.Second
+		}
+		found = true
+		clean = clean[:loc[0]] // only days may precede the clock
+	}
+
+	for _, m := range l.unitRe.FindAllStringSubmatch(clean, -1) {
+		d += atoi(m[1]) * l.Units[m[2]]
+		found = true
+	}
+
+	if !found {
+		return 0, fmt.Errorf("%w in %q", ErrNoDuration, s)
+	}
+	return d, nil
+}
+
+func atoi(s string) time.Duration {
+	n, _ := strconv.Atoi(s)
+	return time.Duration(n)
+}
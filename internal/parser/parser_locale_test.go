@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Readings as the OCR service returns them for counters, resources and timers of the game UI.
+// Add every misread that reaches the logs here.
+
+func TestLocale_ParseNumber(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		input  string
+		want   int
+	}{
+		// English grouping and suffixes
+		{English, "25,431,870", 25_431_870},
+		{English, "1 234 567", 1_234_567},
+		{English, "12,500", 12_500},
+		{English, "4.3M", 4_300_000},
+		{English, "4,3M", 4_300_000},
+		{English, "1.234M", 1_234_000},
+		{English, "350.5K", 350_500},
+		{English, "2.5B", 2_500_000_000},
+		{English, "1.0m", 1_000_000},
+		{English, "12k", 12_000},
+		{English, "900 K", 900_000},
+		{English, "12.5", 12},
+		{English, "1.234", 1_234},
+		{English, "Power: 25,431,870", 25_431_870},
+		{English, "Wood 1.2M", 1_200_000},
+		{English, "V VIP 4", 4},
+		{English, "Lv.5", 5},
+		{English, "x3", 3},
+		{English, "(17)", 17},
+		{English, "5 min", 5},
+		{English, "#128", 128},
+
+		// OCR confusions in numbers
+		{English, "1O5", 105},
+		{English, "l2,5OO", 12_500},
+		{English, "2S,431,87O", 25_431_870},
+		{English, "1B5", 185},
+		{English, "1B", 1_000_000_000},
+		{English, "|0K", 10_000},
+		{English, "３２０", 320},
+		{English, "１，２００", 1_200},
+
+		// Russian
+		{Russian, "1 234 567", 1_234_567},
+		{Russian, "1\u00a0234\u00a0567", 1_234_567},
+		{Russian, "1\u202f234", 1_234},
+		{Russian, "4,3 млн", 4_300_000},
+		{Russian, "1,234М", 1_234_000},
+		{Russian, "350 тыс.", 350_000},
+		{Russian, "12,5К", 12_500},
+		{Russian, "2 млрд", 2_000_000_000},
+		{Russian, "Сила: 25 431 870", 25_431_870},
+		{Russian, "4.3M", 4_300_000},
+
+		// Chinese
+		{Chinese, "1.2万", 12_000},
+		{Chinese, "3亿", 300_000_000},
+		{Chinese, "战力 25,431,870", 25_431_870},
+		{Chinese, "1.5万金币", 15_000},
+		{Chinese, "4.3M", 4_300_000},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.locale.ParseNumber(tt.input)
+		require.NoError(t, err, "%s: %q", tt.locale.Name, tt.input)
+		assert.Equal(t, tt.want, got, "%s: %q", tt.locale.Name, tt.input)
+	}
+}
+
+// TestLocale_RecordedReadings parses the boxes the OCR service returned for
+// references/screenshots/welcome_back.png (ocr/output.png), misreads included.
+func TestLocale_RecordedReadings(t *testing.T) {
+	numbers := map[string]int{
+		"66.0K":                     66_000,
+		"17.0K":                     17_000,
+		"3,659":                     3_659,
+		"32.3K":                     32_300,
+		"Max offline income: 12hrs": 12,
+	}
+	for input, want := range numbers {
+		got, err := English.ParseNumber(input)
+		require.NoError(t, err, "%q", input)
+		assert.Equal(t, want, got, "%q", input)
+	}
+
+	durations := map[string]time.Duration{
+		"00:45:45":                  45*time.Minute + 45*time.Second,
+		"Max offline income: 12hrs": 12 * time.Hour,
+	}
+	for input, want := range durations {
+		got, err := English.ParseDuration(input)
+		require.NoError(t, err, "%q", input)
+		assert.Equal(t, want, got, "%q", input)
+	}
+
+	for _, input := range []string{"Welcome back!.", "X", "Time Offline", "Offline Income", "Overview", "Confirma"} {
+		_, err := English.ParseNumber(input)
+		assert.ErrorIs(t, err, ErrNoNumber, "%q", input)
+	}
+}
+
+func TestLocale_ParseNumber_Errors(t *testing.T) {
+	for _, input := range []string{"", "invalid", "VIP", "Power", "---", "OK", "SOS"} {
+		_, err := English.ParseNumber(input)
+		assert.ErrorIs(t, err, ErrNoNumber, "input: %q", input)
+	}
+
+	// the zero of a real reading is not an error
+	got, err := English.ParseNumber("0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, got)
+}
+
+func TestLocale_ParseDuration(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		input  string
+		want   time.Duration
+	}{
+		{English, "01:23:45", time.Hour + 23*time.Minute + 45*time.Second},
+		{English, "23:45", 23*time.Minute + 45*time.Second},
+		{English, "2d 03:04:05", 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{English, "2d03:04:05", 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{English, "42d171612", 42*day + 17*time.Hour + 16*time.Minute + 12*time.Second},
+		{English, "3d4h30m", 3*day + 4*time.Hour + 30*time.Minute},
+		{English, "90m10s", 90*time.Minute + 10*time.Second},
+		{English, "1h 30m", 90 * time.Minute},
+		{English, "5 hours", 5 * time.Hour},
+		{English, "2 days", 2 * day},
+		{English, "Refreshes In: 10:00:00", 10 * time.Hour},
+		{English, "Time left 0O:3O:l5", 30*time.Minute + 15*time.Second},
+		{English, "O1:OO:OO", time.Hour},
+		{English, "30s", 30 * time.Second},
+
+		{Russian, "1ч 30м", 90 * time.Minute},
+		{Russian, "2д 03:04:05", 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{Russian, "3 дня", 3 * day},
+		{Russian, "45 сек", 45 * time.Second},
+		{Russian, "Осталось: 12:00:00", 12 * time.Hour},
+
+		{Chinese, "1小时30分", 90 * time.Minute},
+		{Chinese, "2天 03:04:05", 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{Chinese, "剩余时间：０１：００：００", time.Hour},
+		{Chinese, "45秒", 45 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.locale.ParseDuration(tt.input)
+		require.NoError(t, err, "%s: %q", tt.locale.Name, tt.input)
+		assert.Equal(t, tt.want, got, "%s: %q", tt.locale.Name, tt.input)
+	}
+}
+
+func TestLocale_ParseDuration_Errors(t *testing.T) {
+	for _, input := range []string{"", "Free", "Claim", "soon"} {
+		_, err := English.ParseDuration(input)
+		assert.ErrorIs(t, err, ErrNoDuration, "input: %q", input)
+	}
+}
+
+func TestLocale_FixDigits(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"1O5", "105"},
+		{"l2:3O", "12:30"},
+		{"VIP 4", "VIP 4"},
+		{"S5", "55"},
+		{"1B", "1B"},
+		{"1B5", "185"},
+		{"30s", "30s"},
+		{"Power 2S,431", "Power 25,431"},
+		{"SOS", "SOS"},
+		{"１２：３０", "12:30"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, English.FixDigits(tt.input), "input: %q", tt.input)
+	}
+}
+
+func TestLocaleByName(t *testing.T) {
+	l, err := LocaleByName("RU")
+	require.NoError(t, err)
+	assert.Equal(t, "ru", l.Name)
+
+	_, err = LocaleByName("xx")
+	assert.Error(t, err)
+}
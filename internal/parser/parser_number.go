@@ -1,54 +1,134 @@
 package parser
 
 import (
+	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-// ParseNumber converts strings like "6)", "1 234 567", "13,350,651", "4.3M", "4,3M", "2.1K", "900K", "1.0m", "12k", "V VIP 4" to their integer values.
+// ParseNumber converts strings like "6)", "1 234 567", "13,350,651", "4.3M", "4,3M", "2.1K", "900K", "1.0m", "12k", "1.2B", "V VIP 4"
+// to their integer values, 0 when there is no number. Use Locale.ParseNumber to tell a missing number from zero.
 func ParseNumber(s string) int {
-	// Remove spaces and trailing punctuations like ')', '.', ',', ':', ';'
-	clean := strings.ReplaceAll(s, " ", "")
-	clean = strings.TrimRight(clean, ").,:;")
-	clean = strings.ToUpper(clean)
-
-	// Regex: integer part (with optional grouping commas) + optional fractional + optional K/M
-	re := regexp.MustCompile(`^(\d{1,3}(?:,\d{3})*|\d+)([.,]\d+)?([KM])?$`)
-	m := re.FindStringSubmatch(clean)
-	if len(m) == 0 {
-		// Fallback: extract the first sequence of digits anywhere in the string
-		digitRe := regexp.MustCompile(`\d+`)
-		ds := digitRe.FindString(clean)
-		if ds == "" {
-			return 0
+	n, _ := English.ParseNumber(s)
+	return n
+}
+
+// numberRe matches digits with grouping/decimal separators; a space only groups exactly three digits ("1 234 567").
+var numberRe = regexp.MustCompile(`\d+(?:[.,'\x{00A0}\x{202F}]\d+|[ ]\d{3}\b)*`)
+
+// amount is a number found in a text.
+type amount struct {
+	start, end int // bytes of the text, including the suffix
+	value      float64
+	suffixed   bool
+}
+
+// ParseNumber returns the first number of the text as an integer, with its K/M/B (or locale) suffix applied.
+func (l Locale) ParseNumber(s string) (int, error) {
+	amounts := l.scan(s)
+	if len(amounts) == 0 {
+		return 0, fmt.Errorf("%w in %q", ErrNoNumber, s)
+	}
+	if amounts[0].suffixed {
+		return int(math.Round(amounts[0].value)), nil
+	}
+	return int(amounts[0].value), nil
+}
+
+// ParseFloat returns the first number of the text with its suffix applied.
+func (l Locale) ParseFloat(s string) (float64, error) {
+	amounts := l.scan(s)
+	if len(amounts) == 0 {
+		return 0, fmt.Errorf("%w in %q", ErrNoNumber, s)
+	}
+	return amounts[0].value, nil
+}
+
+// scan finds every number of the text, after fixing OCR confusions.
+func (l Locale) scan(s string) []amount {
+	s = l.FixDigits(s)
+
+	var out []amount
+	for _, loc := range numberRe.FindAllStringIndex(s, -1) {
+		mult, suffixLen := l.suffix(s[loc[1]:])
+		v, err := l.value(s[loc[0]:loc[1]], suffixLen > 0)
+		if err != nil {
+			continue
 		}
-		val, _ := strconv.Atoi(ds)
-		return val
+		out = append(out, amount{start: loc[0], end: loc[1] + suffixLen, value: v * mult, suffixed: suffixLen > 0})
+	}
+	return out
+}
+
+// suffix returns the multiplier of the suffix the text starts with and its length in bytes.
+// A suffix must be a whole word: the "m" of "5 min" is not millions.
+func (l Locale) suffix(rest string) (float64, int) {
+	trimmed := strings.TrimLeft(rest, " ")
+	skipped := len(rest) - len(trimmed)
+
+	word := []rune{}
+	for _, r := range trimmed {
+		if !unicode.IsLetter(r) {
+			break
+		}
+		if unicode.Is(unicode.Han, r) {
+			if len(word) == 0 {
+				word = append(word, r) // CJK suffixes are a single character
+			}
+			break
+		}
+		word = append(word, r)
 	}
 
-	// Remove grouping commas from integer part
-	intPart := strings.ReplaceAll(m[1], ",", "")
-	fracPart := ""
-	if m[2] != "" {
-		// unify decimal separator
-		fracPart = strings.ReplaceAll(m[2], ",", ".")
+	mult, ok := l.Suffixes[strings.ToLower(string(word))]
+	if !ok {
+		return 1, 0
 	}
-	numberStr := intPart + fracPart
+	n := skipped + len(string(word))
+	if strings.HasPrefix(trimmed[len(string(word)):], ".") {
+		n++ // "тыс."
+	}
+	return mult, n
+}
 
-	// Parse the numeric value
-	num, err := strconv.ParseFloat(numberStr, 64)
-	if err != nil {
-		return 0
+// value reads the digits of one number. Separators group thousands unless the last one is followed by other
+// than three digits ("4.3M", "12,5"), or the amount is suffixed and its only separator is the decimal one
+// of the locale ("1.234M" in en, "1,234М" in ru).
+func (l Locale) value(chunk string, suffixed bool) (float64, error) {
+	var runs []string
+	var seps []rune
+	cur := strings.Builder{}
+	for _, r := range chunk {
+		if r >= '0' && r <= '9' {
+			cur.WriteRune(r)
+			continue
+		}
+		runs = append(runs, cur.String())
+		seps = append(seps, r)
+		cur.Reset()
 	}
+	runs = append(runs, cur.String())
 
-	// Apply multiplier for K/M suffix
-	switch m[3] {
-	case "M":
-		num *= 1_000_000
-	case "K":
-		num *= 1_000
+	decimal := -1
+	if last := len(runs) - 1; last > 0 {
+		sep := seps[last-1]
+		switch {
+		case len(runs[last]) != 3 && (sep == '.' || sep == ','):
+			decimal = last
+		case suffixed && last == 1 && sep == l.Decimal:
+			decimal = last
+		}
 	}
 
-	return int(num)
+	digits := strings.Builder{}
+	for i, run := range runs {
+		if i == decimal {
+			digits.WriteByte('.')
+		}
+		digits.WriteString(run)
+	}
+	return strconv.ParseFloat(digits.String(), 64)
 }
@@ -19,6 +19,7 @@ func TestParseNumber(t *testing.T) {
 		{"900K", 900000},
 		{"1.0m", 1000000},
 		{"12k", 12000},
+		{"1.2B", 1200000000},
 		{"invalid", 0},
 		{"13,350,651", 13350651},
 		{"6)", 6},
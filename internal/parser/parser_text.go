@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseFraction parses "current/max" counters like "12/20", "87 / 100" or "1.2M/5M".
+func (l Locale) ParseFraction(s string) (current, max int, err error) {
+	s = l.FixDigits(s)
+
+	slash := strings.Index(s, "/")
+	if slash < 0 {
+		return 0, 0, fmt.Errorf("%w: no fraction in %q", ErrNoNumber, s)
+	}
+
+	var left, right *amount
+	amounts := l.scan(s)
+	for i := range amounts {
+		a := &amounts[i]
+		if a.end <= slash && strings.TrimSpace(s[a.end:slash]) == "" {
+			left = a
+		}
+		if a.start > slash && strings.TrimSpace(s[slash+1:a.start]) == "" {
+			right = a
+		}
+	}
+	if left == nil || right == nil {
+		return 0, 0, fmt.Errorf("%w: no fraction in %q", ErrNoNumber, s)
+	}
+	return int(left.value), int(right.value), nil
+}
+
+// ParsePercent parses "45%" or "12,5 %" to 45 and 12.5.
+func (l Locale) ParsePercent(s string) (float64, error) {
+	s = l.FixDigits(s)
+
+	for _, a := range l.scan(s) {
+		if strings.HasPrefix(strings.TrimLeft(s[a.end:], " "), "%") {
+			return a.value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: no percent in %q", ErrNoNumber, s)
+}
+
+// dateLayouts are the date formats shown by the game, the most specific first.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04",
+	"02.01.2006 15:04",
+	"2006年01月02日 15:04",
+	"2006-01-02",
+	"02.01.2006",
+	"2006年01月02日",
+	"01-02 15:04", // the current year
+}
+
+// ParseDate parses a date of the game UI (server time, UTC). A date without a year is in the year of now.
+func (l Locale) ParseDate(s string, now time.Time) (time.Time, error) {
+	clean := strings.Join(strings.Fields(strings.Trim(l.FixDigits(s), " .,;:()")), " ")
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, clean)
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			t = t.AddDate(now.UTC().Year(), 0, 0)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w in %q", ErrNoDate, s)
+}
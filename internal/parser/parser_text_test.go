@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFraction(t *testing.T) {
+	tests := []struct {
+		input        string
+		current, max int
+		ok           bool
+	}{
+		{"12/20", 12, 20, true},
+		{"Members 87 / 100", 87, 100, true},
+		{"1.2M/5M", 1_200_000, 5_000_000, true},
+		{"Lv.5 12/20", 12, 20, true},
+		{"l2/2O", 12, 20, true},
+		{"12", 0, 0, false},
+		{"12/", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		current, max, err := English.ParseFraction(tt.input)
+		assert.Equal(t, tt.ok, err == nil, "input: %q", tt.input)
+		assert.Equal(t, tt.current, current, "input: %q", tt.input)
+		assert.Equal(t, tt.max, max, "input: %q", tt.input)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		ok    bool
+	}{
+		{"45%", 45, true},
+		{"Progress 12,5 %", 12.5, true},
+		{"1OO%", 100, true},
+		{"45", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := English.ParsePercent(tt.input)
+		assert.Equal(t, tt.ok, err == nil, "input: %q", tt.input)
+		assert.Equal(t, tt.want, got, "input: %q", tt.input)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+		ok    bool
+	}{
+		{"2025-04-12 10:00", time.Date(2025, 4, 12, 10, 0, 0, 0, time.UTC), true},
+		{"2025-04-12 10:00:30", time.Date(2025, 4, 12, 10, 0, 30, 0, time.UTC), true},
+		{"12.04.2025", time.Date(2025, 4, 12, 0, 0, 0, 0, time.UTC), true},
+		{"(04-12 10:00)", time.Date(2025, 4, 12, 10, 0, 0, 0, time.UTC), true},
+		{"2025年04月12日 10:00", time.Date(2025, 4, 12, 10, 0, 0, 0, time.UTC), true},
+		{"soon", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := English.ParseDate(tt.input, now)
+		assert.Equal(t, tt.ok, err == nil, "input: %q", tt.input)
+		assert.True(t, tt.want.Equal(got), "input: %q, got %v", tt.input, got)
+	}
+}
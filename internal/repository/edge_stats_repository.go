@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+const edgeStatsIndexKey = "bot:fsm:edges"
+
+// EdgeStatsRepository accumulates duration and failures of FSM transitions,
+// shared by all devices.
+type EdgeStatsRepository interface {
+	RecordEdge(ctx context.Context, from, to string, duration time.Duration, success bool) error
+	LoadEdgeStats(ctx context.Context) ([]domain.EdgeStats, error)
+}
+
+func NewRedisEdgeStatsRepository(rdb *redis.Client) EdgeStatsRepository {
+	return &redisEdgeStatsRepo{rdb: rdb}
+}
+
+type redisEdgeStatsRepo struct {
+	rdb *redis.Client
+}
+
+func (r *redisEdgeStatsRepo) key(edge string) string {
+	return fmt.Sprintf("bot:fsm:edge:%s", edge)
+}
+
+// edge encodes a transition as a single index member.
+func edgeID(from, to string) string {
+	return from + "|" + to
+}
+
+func (r *redisEdgeStatsRepo) RecordEdge(ctx context.Context, from, to string, duration time.Duration, success bool) error {
+	edge := edgeID(from, to)
+	key := r.key(edge)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.SAdd(ctx, edgeStatsIndexKey, edge)
+	pipe.HIncrBy(ctx, key, "attempts", 1)
+	pipe.HIncrBy(ctx, key, "duration_ms", duration.Milliseconds())
+	if !success {
+		pipe.HIncrBy(ctx, key, "failures", 1)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("record edge %s → %s: %w", from, to, err)
+	}
+	return nil
+}
+
+func (r *redisEdgeStatsRepo) LoadEdgeStats(ctx context.Context) ([]domain.EdgeStats, error) {
+	edges, err := r.rdb.SMembers(ctx, edgeStatsIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("load edge index: %w", err)
+	}
+
+	pipe := r.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(edges))
+	for i, edge := range edges {
+		cmds[i] = pipe.HGetAll(ctx, r.key(edge))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("load edge stats: %w", err)
+	}
+
+	stats := make([]domain.EdgeStats, 0, len(edges))
+	for i, edge := range edges {
+		from, to, ok := strings.Cut(edge, "|")
+		if !ok {
+			continue
+		}
+
+		var fields struct {
+			Attempts   int64 `redis:"attempts"`
+			Failures   int64 `redis:"failures"`
+			DurationMs int64 `redis:"duration_ms"`
+		}
+		if err := cmds[i].Scan(&fields); err != nil {
+			return nil, fmt.Errorf("scan edge stats %s: %w", edge, err)
+		}
+
+		stats = append(stats, domain.EdgeStats{
+			From:          from,
+			To:            to,
+			Attempts:      fields.Attempts,
+			Failures:      fields.Failures,
+			TotalDuration: time.Duration(fields.DurationMs) * time.Millisecond,
+		})
+	}
+
+	return stats, nil
+}
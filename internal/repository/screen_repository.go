@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScreenRepository persists the last confirmed FSM screen per device,
+// so a restarted process can resume navigation from where it stopped.
+type ScreenRepository interface {
+	LoadScreen(ctx context.Context, deviceID string) (string, error)
+	SaveScreen(ctx context.Context, deviceID string, screen string) error
+}
+
+func NewRedisScreenRepository(rdb *redis.Client) ScreenRepository {
+	return &redisScreenRepo{rdb: rdb}
+}
+
+type redisScreenRepo struct {
+	rdb *redis.Client
+}
+
+func (r *redisScreenRepo) key(deviceID string) string {
+	return fmt.Sprintf("bot:screen:%s", deviceID)
+}
+
+// LoadScreen returns the last saved screen or an empty string if nothing was saved yet.
+func (r *redisScreenRepo) LoadScreen(ctx context.Context, deviceID string) (string, error) {
+	screen, err := r.rdb.Get(ctx, r.key(deviceID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load screen for %s: %w", deviceID, err)
+	}
+	return screen, nil
+}
+
+func (r *redisScreenRepo) SaveScreen(ctx context.Context, deviceID string, screen string) error {
+	if err := r.rdb.Set(ctx, r.key(deviceID), screen, 0).Err(); err != nil {
+		return fmt.Errorf("save screen for %s: %w", deviceID, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respServer is a minimal Redis (RESP2) with GET and SET; other commands are unknown.
+type respServer struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func startRESPServer(t *testing.T) (*respServer, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	s := &respServer{data: map[string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, ln.Addr().String()
+}
+
+func (s *respServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		s.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if v, ok := s.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			s.data[args[1]] = args[2]
+			reply = "+OK\r\n"
+		default:
+			reply = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a RESP array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil { // $<len>
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestRedisScreenRepository(t *testing.T) {
+	server, addr := startRESPServer(t)
+	rdb := redis.NewClient(&redis.Options{Addr: addr, Protocol: 2, DisableIdentity: true})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	repo := NewRedisScreenRepository(rdb)
+	ctx := context.Background()
+
+	screen, err := repo.LoadScreen(ctx, "127.0.0.1:5555")
+	require.NoError(t, err)
+	assert.Empty(t, screen, "nothing saved yet")
+
+	require.NoError(t, repo.SaveScreen(ctx, "127.0.0.1:5555", "alliance_tech"))
+	require.NoError(t, repo.SaveScreen(ctx, "emulator-5556", "mail"))
+
+	screen, err = repo.LoadScreen(ctx, "127.0.0.1:5555")
+	require.NoError(t, err)
+	assert.Equal(t, "alliance_tech", screen)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "mail", server.data["bot:screen:emulator-5556"], "one key per device")
+}
+
+func TestRedisScreenRepository_Unavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	rdb := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	_, err = NewRedisScreenRepository(rdb).LoadScreen(context.Background(), "d")
+	assert.ErrorContains(t, err, "load screen for d")
+}
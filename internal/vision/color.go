@@ -0,0 +1,194 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// HSV is a color with hue in degrees [0, 360), saturation and value in [0, 1].
+type HSV struct {
+	H, S, V float64
+}
+
+// ToHSV converts a color to HSV.
+func ToHSV(c color.Color) HSV {
+	r16, g16, b16, _ := c.RGBA()
+	r, g, b := float64(r16>>8)/255, float64(g16>>8)/255, float64(b16>>8)/255
+
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	hsv := HSV{V: maxC}
+	if maxC > 0 {
+		hsv.S = delta / maxC
+	}
+	if delta == 0 {
+		return hsv
+	}
+
+	switch maxC {
+	case r:
+		hsv.H = 60 * math.Mod((g-b)/delta, 6)
+	case g:
+		hsv.H = 60 * ((b-r)/delta + 2)
+	default:
+		hsv.H = 60 * ((r-g)/delta + 4)
+	}
+	if hsv.H < 0 {
+		hsv.H += 360
+	}
+	return hsv
+}
+
+// InRange reports whether the color belongs to the range.
+func (c HSV) InRange(r domain.HSVRange) bool {
+	sMax, vMax := r.SMax, r.VMax
+	if sMax == 0 {
+		sMax = 1
+	}
+	if vMax == 0 {
+		vMax = 1
+	}
+	if c.S < r.SMin || c.S > sMax || c.V < r.VMin || c.V > vMax {
+		return false
+	}
+
+	switch {
+	case r.HMin == 0 && r.HMax == 0:
+		return true // any hue
+	case r.HMin <= r.HMax:
+		return c.H >= r.HMin && c.H <= r.HMax
+	default:
+		return c.H >= r.HMin || c.H <= r.HMax
+	}
+}
+
+// palette – named colors of the game UI, checked in order (achromatic first).
+var palette = []struct {
+	name string
+	hsv  domain.HSVRange
+}{
+	{"black", domain.HSVRange{VMax: 0.2}},
+	{"white", domain.HSVRange{SMax: 0.15, VMin: 0.85}},
+	{"gray", domain.HSVRange{SMax: 0.25}},
+	{"red", domain.HSVRange{HMin: 345, HMax: 15}},
+	{"orange", domain.HSVRange{HMin: 15, HMax: 40}},
+	{"yellow", domain.HSVRange{HMin: 40, HMax: 70}},
+	{"green", domain.HSVRange{HMin: 70, HMax: 170}},
+	{"blue", domain.HSVRange{HMin: 170, HMax: 260}},
+	{"purple", domain.HSVRange{HMin: 260, HMax: 345}},
+}
+
+// ColorName returns the palette name of a color.
+func ColorName(c HSV) string {
+	for _, p := range palette {
+		if c.InRange(p.hsv) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+// IsPaletteColor reports whether name is a known palette color.
+func IsPaletteColor(name string) bool {
+	for _, p := range palette {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PaletteRange returns the HSV range of a palette color.
+func PaletteRange(name string) (domain.HSVRange, bool) {
+	for _, p := range palette {
+		if p.name == name {
+			return p.hsv, true
+		}
+	}
+	return domain.HSVRange{}, false
+}
+
+// ColorStats is the color distribution of a region.
+type ColorStats struct {
+	Dominant string
+	Counts   map[string]int
+	Total    int
+}
+
+// Share returns the fraction of pixels of the named color.
+func (s ColorStats) Share(name string) float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Counts[name]) / float64(s.Total)
+}
+
+// SampleColors classifies the pixels of a region by palette.
+func SampleColors(img image.Image, region image.Rectangle) ColorStats {
+	stats := ColorStats{Counts: make(map[string]int)}
+
+	eachPixel(img, region, func(c HSV) {
+		stats.Counts[ColorName(c)]++
+		stats.Total++
+	})
+
+	for name, n := range stats.Counts {
+		if n > stats.Counts[stats.Dominant] || (n == stats.Counts[stats.Dominant] && name < stats.Dominant) {
+			stats.Dominant = name
+		}
+	}
+	return stats
+}
+
+// MatchShare returns the fraction of pixels of a region that fall into any of the ranges.
+func MatchShare(img image.Image, region image.Rectangle, ranges []domain.HSVRange) float64 {
+	var matched, total int
+	eachPixel(img, region, func(c HSV) {
+		total++
+		for _, r := range ranges {
+			if c.InRange(r) {
+				matched++
+				return
+			}
+		}
+	})
+
+	if total == 0 {
+		return 0
+	}
+	return float64(matched) / float64(total)
+}
+
+// CountSegments splits a region into n equal columns and counts those whose MatchShare reaches
+// threshold, e.g. the filled stars of a star bar.
+func CountSegments(img image.Image, region image.Rectangle, n int, ranges []domain.HSVRange, threshold float64) int {
+	if n <= 0 {
+		return 0
+	}
+
+	count := 0
+	for i := 0; i < n; i++ {
+		segment := image.Rect(
+			region.Min.X+region.Dx()*i/n, region.Min.Y,
+			region.Min.X+region.Dx()*(i+1)/n, region.Max.Y,
+		)
+		if MatchShare(img, segment, ranges) >= threshold {
+			count++
+		}
+	}
+	return count
+}
+
+func eachPixel(img image.Image, region image.Rectangle, fn func(HSV)) {
+	region = region.Intersect(img.Bounds())
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			fn(ToHSV(img.At(x, y)))
+		}
+	}
+}
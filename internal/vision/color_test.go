@@ -0,0 +1,75 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/domain"
+)
+
+// the Contribute button of alliance tech (region alliance.state.isAllianceContributeButton)
+var contributeButton = image.Rect(580, 1773, 941, 1882)
+
+func TestColorName(t *testing.T) {
+	for rgb, want := range map[color.RGBA]string{
+		{0, 0, 0, 255}:       "black",
+		{255, 255, 255, 255}: "white",
+		{128, 128, 128, 255}: "gray",
+		{230, 30, 40, 255}:   "red",
+		{89, 179, 97, 255}:   "green",
+		{72, 85, 119, 255}:   "blue",
+		{250, 140, 30, 255}:  "orange",
+	} {
+		require.Equal(t, want, ColorName(ToHSV(rgb)), "%v", rgb)
+	}
+}
+
+func TestSampleColors_Button(t *testing.T) {
+	enabled := loadTestPNG(t, "../../references/screenshots/alliance/alliance_tech_contribute.png")
+	disabled := loadTestPNG(t, "../../references/screenshots/alliance/alliance_tech_contribute_disabled.png")
+
+	require.Equal(t, "blue", SampleColors(enabled, contributeButton).Dominant)
+	require.Equal(t, "gray", SampleColors(disabled, contributeButton).Dominant, "greyed-out button")
+}
+
+func TestSampleColors_NotificationDot(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/city_main.png")
+
+	shop := SampleColors(screen, image.Rect(640, 2230, 700, 2290))
+	heroes := SampleColors(screen, image.Rect(290, 2230, 350, 2290))
+
+	require.Equal(t, "blue", shop.Dominant, "the dot is small")
+	require.Greater(t, shop.Share("red"), 0.05)
+	require.Zero(t, heroes.Share("red"))
+}
+
+func TestMatchShare(t *testing.T) {
+	disabled := loadTestPNG(t, "../../references/screenshots/alliance/alliance_tech_contribute_disabled.png")
+
+	lowSaturation := []domain.HSVRange{{SMax: 0.25, VMin: 0.2}}
+	require.Greater(t, MatchShare(disabled, contributeButton, lowSaturation), 0.8)
+
+	// wrapped hue range around red
+	require.True(t, ToHSV(color.RGBA{250, 10, 60, 255}).InRange(domain.HSVRange{HMin: 340, HMax: 20, SMin: 0.5}))
+	require.False(t, ToHSV(color.RGBA{10, 250, 60, 255}).InRange(domain.HSVRange{HMin: 340, HMax: 20, SMin: 0.5}))
+}
+
+func TestCountSegments_Stars(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/heroes.png")
+	filled := []domain.HSVRange{{HMin: 170, HMax: 200, SMin: 0.3, VMin: 0.75}} // cyan stars
+
+	for name, tc := range map[string]struct {
+		bar   image.Rectangle
+		stars int
+	}{
+		"first card":             {image.Rect(70, 606, 264, 644), 3},
+		"fourth card":            {image.Rect(820, 606, 1014, 644), 4},
+		"a star half filled":     {image.Rect(820, 1450, 1014, 1488), 2},
+		"purple card background": {image.Rect(70, 1028, 264, 1066), 3},
+	} {
+		require.Equal(t, tc.stars, CountSegments(screen, tc.bar, 5, filled, 0.2), name)
+	}
+}
@@ -0,0 +1,96 @@
+package vision
+
+import (
+	"context"
+	"image"
+	"path/filepath"
+	"sync"
+
+	"github.com/batazor/whiteout-survival-autopilot/internal/ocrclient"
+)
+
+// iconScales – the game UI is rendered for 1080x2400, slight zoom differences are tolerated.
+var iconScales = []float64{0.9, 1, 1.1}
+
+// IconMatcher finds icons from references/icons locally, without the OCR service.
+// FindImage mirrors ocrclient.Client.FindImage, so both can be used interchangeably.
+type IconMatcher struct {
+	Scale float64 // UI scale of the device screen, 0 – reference resolution
+
+	dir    string
+	screen ScreenSource
+
+	mu        sync.Mutex
+	templates map[string]image.Image
+}
+
+func NewIconMatcher(dir string, screen ScreenSource) *IconMatcher {
+	return &IconMatcher{
+		dir:       dir,
+		screen:    screen,
+		templates: make(map[string]image.Image),
+	}
+}
+
+// FindImage captures the screen and searches every occurrence of the icon (".png" is appended to imageName).
+func (m *IconMatcher) FindImage(ctx context.Context, imageName string, threshold float64, _ string) (*ocrclient.FindImageResponse, error) {
+	if _, err := m.template(imageName); err != nil {
+		return nil, err
+	}
+
+	screen, err := m.screen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.FindImageIn(screen, imageName, threshold)
+}
+
+// FindImageIn searches the icon on an already captured screen.
+func (m *IconMatcher) FindImageIn(screen image.Image, imageName string, threshold float64) (*ocrclient.FindImageResponse, error) {
+	tmpl, err := m.template(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	scales := iconScales
+	if m.Scale > 0 && m.Scale != 1 {
+		scales = make([]float64, len(iconScales))
+		for i, s := range iconScales {
+			scales[i] = s * m.Scale
+		}
+	}
+
+	matches := FindTemplate(screen, tmpl, MatchOptions{Threshold: threshold, Scales: scales})
+	return ToFindImageResponse(matches), nil
+}
+
+// template loads an icon once.
+func (m *IconMatcher) template(name string) (image.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tmpl, ok := m.templates[name]; ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := LoadPNG(filepath.Join(m.dir, name+".png"))
+	if err != nil {
+		return nil, err
+	}
+
+	m.templates[name] = tmpl
+	return tmpl, nil
+}
+
+// ToFindImageResponse converts matches to the OCR service response: each box is a 4-point polygon.
+func ToFindImageResponse(matches []Match) *ocrclient.FindImageResponse {
+	resp := &ocrclient.FindImageResponse{Found: len(matches) > 0}
+	for _, m := range matches {
+		r := m.Rect
+		resp.Boxes = append(resp.Boxes, [][]int{
+			{r.Min.X, r.Min.Y}, {r.Max.X, r.Min.Y}, {r.Max.X, r.Max.Y}, {r.Min.X, r.Max.Y},
+		})
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package vision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIconMatcher_FindImage(t *testing.T) {
+	m := NewIconMatcher("../../references/icons", PNGScreen("../../references/screenshots/city_main.png"))
+
+	resp, err := m.FindImage(context.Background(), "alliance.state.isNeedSupport", 0.9, "test")
+	require.NoError(t, err)
+	require.True(t, resp.Found)
+	require.Equal(t, [][][]int{{{751, 2162}, {858, 2162}, {858, 2223}, {751, 2223}}}, resp.Boxes)
+	require.Len(t, resp.ToRects(), 1)
+
+	resp, err = m.FindImage(context.Background(), "healInjured.state.isAvailable", 0.9, "test")
+	require.NoError(t, err)
+	require.False(t, resp.Found)
+
+	_, err = m.FindImage(context.Background(), "no_such_icon", 0.9, "test")
+	require.Error(t, err)
+}
+
+func TestIconMatcher_BackpackItems(t *testing.T) {
+	m := NewIconMatcher("../../references/icons", PNGScreen("../../references/screenshots/backpack.png"))
+
+	for name, want := range map[string][][][]int{
+		"backpack.item.wood": {{{343, 1130}, {493, 1130}, {493, 1270}, {343, 1270}}},
+		"backpack.item.iron": {{{343, 1630}, {493, 1630}, {493, 1770}, {343, 1770}}},
+	} {
+		resp, err := m.FindImage(context.Background(), name, 0.85, "test")
+		require.NoError(t, err)
+		require.Equal(t, want, resp.Boxes, name)
+	}
+}
@@ -0,0 +1,52 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+)
+
+// ScreenSource returns the current screen.
+type ScreenSource func(ctx context.Context) (image.Image, error)
+
+// ADBScreen captures the device screen with `adb exec-out screencap -p`.
+func ADBScreen(deviceID string) ScreenSource {
+	return func(ctx context.Context) (image.Image, error) {
+		out, err := exec.CommandContext(ctx, "adb", "-s", deviceID, "exec-out", "screencap", "-p").Output()
+		if err != nil {
+			return nil, fmt.Errorf("adb screencap: %w", err)
+		}
+
+		img, err := png.Decode(bytes.NewReader(out))
+		if err != nil {
+			return nil, fmt.Errorf("decode screencap: %w", err)
+		}
+		return img, nil
+	}
+}
+
+// PNGScreen serves a saved screenshot, e.g. from references/screenshots.
+func PNGScreen(path string) ScreenSource {
+	return func(context.Context) (image.Image, error) {
+		return LoadPNG(path)
+	}
+}
+
+// LoadPNG reads a PNG file.
+func LoadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
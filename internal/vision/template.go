@@ -0,0 +1,309 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Match is one occurrence of a template on the screen.
+type Match struct {
+	Rect  image.Rectangle
+	Score float64 // normalized cross-correlation, [-1, 1]
+}
+
+// MatchOptions tunes FindTemplate.
+type MatchOptions struct {
+	Threshold  float64   // minimal score (default 0.9)
+	Scales     []float64 // template scales to try (default 1.0)
+	MaxResults int       // 0 – all matches above the threshold
+	Region     image.Rectangle
+}
+
+const (
+	defaultMatchThreshold = 0.9
+
+	// coarseMinSide – the template is downscaled for the coarse search until its short side reaches this size.
+	coarseMinSide = 12
+	// coarseSlack – the coarse search is blurry, candidates slightly below the threshold are refined too.
+	coarseSlack = 0.2
+	// maxCoarseCandidates – how many coarse peaks are refined per scale.
+	maxCoarseCandidates = 32
+	// nmsOverlap – matches overlapping more than this (IoU) are merged.
+	nmsOverlap = 0.3
+)
+
+// FindTemplate searches every occurrence of tmpl on screen using normalized cross-correlation
+// over the RGB channels (close to OpenCV TM_CCOEFF_NORMED).
+// Each scale is searched coarse-to-fine: a downscaled pass finds candidates,
+// full resolution refines them. Overlapping matches are suppressed, the best come first.
+func FindTemplate(screen, tmpl image.Image, opts MatchOptions) []Match {
+	if opts.Threshold == 0 {
+		opts.Threshold = defaultMatchThreshold
+	}
+	if len(opts.Scales) == 0 {
+		opts.Scales = []float64{1}
+	}
+
+	src := newPlane(screen, opts.Region)
+	if src.w == 0 || src.h == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, scale := range opts.Scales {
+		t := newPlane(tmpl, image.Rectangle{})
+		if scale != 1 {
+			t = t.resize(int(math.Round(float64(t.w)*scale)), int(math.Round(float64(t.h)*scale)))
+		}
+		if t.w < 2 || t.h < 2 || t.w > src.w || t.h > src.h {
+			continue
+		}
+
+		for _, m := range matchScale(src, t, opts.Threshold) {
+			m.Rect = m.Rect.Add(src.origin)
+			matches = append(matches, m)
+		}
+	}
+
+	matches = suppress(matches)
+	if opts.MaxResults > 0 && len(matches) > opts.MaxResults {
+		matches = matches[:opts.MaxResults]
+	}
+	return matches
+}
+
+// matchScale finds matches of one template size.
+func matchScale(src, t *plane, threshold float64) []Match {
+	factor := min(t.w, t.h) / coarseMinSide
+	if factor < 2 {
+		return src.scan(t, threshold, image.Rect(0, 0, src.w-t.w+1, src.h-t.h+1))
+	}
+
+	// coarse pass
+	cs := src.resize(src.w/factor, src.h/factor)
+	ct := t.resize(t.w/factor, t.h/factor)
+	coarse := cs.scan(ct, threshold-coarseSlack, image.Rect(0, 0, cs.w-ct.w+1, cs.h-ct.h+1))
+	coarse = suppress(coarse)
+	if len(coarse) > maxCoarseCandidates {
+		coarse = coarse[:maxCoarseCandidates]
+	}
+
+	// fine pass around each candidate
+	var out []Match
+	for _, c := range coarse {
+		x, y := c.Rect.Min.X*factor, c.Rect.Min.Y*factor
+		window := image.Rect(x-factor, y-factor, x+factor+1, y+factor+1).
+			Intersect(image.Rect(0, 0, src.w-t.w+1, src.h-t.h+1))
+
+		if best := src.scan(t, threshold, window); len(best) > 0 {
+			sort.Slice(best, func(i, j int) bool { return best[i].Score > best[j].Score })
+			out = append(out, best[0])
+		}
+	}
+	return out
+}
+
+// suppress sorts matches by score and drops those overlapping a better one.
+func suppress(matches []Match) []Match {
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+
+	var kept []Match
+	for _, m := range matches {
+		overlaps := false
+		for _, k := range kept {
+			if iou(m.Rect, k.Rect) > nmsOverlap {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func iou(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	i := float64(inter.Dx() * inter.Dy())
+	u := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - i
+	return i / u
+}
+
+// plane is an RGB image as float channels with integral images for fast window statistics.
+type plane struct {
+	w, h   int
+	origin image.Point
+	pix    []float64 // r, g, b interleaved
+
+	sum   []float64 // (w+1)*(h+1) integral of r+g+b
+	sumSq []float64 // integral of r²+g²+b²
+}
+
+func newPlane(img image.Image, region image.Rectangle) *plane {
+	b := img.Bounds()
+	if !region.Empty() {
+		b = region.Intersect(b)
+	}
+
+	p := &plane{w: b.Dx(), h: b.Dy(), origin: b.Min}
+	p.pix = make([]float64, p.w*p.h*3)
+	for y := 0; y < p.h; y++ {
+		for x := 0; x < p.w; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			i := (y*p.w + x) * 3
+			p.pix[i], p.pix[i+1], p.pix[i+2] = float64(r>>8), float64(g>>8), float64(bl>>8)
+		}
+	}
+	p.integrate()
+	return p
+}
+
+// resize downsamples (box filter) or upsamples (nearest) the plane.
+func (p *plane) resize(w, h int) *plane {
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	out := &plane{w: w, h: h, origin: p.origin, pix: make([]float64, w*h*3)}
+	for y := 0; y < h; y++ {
+		y0 := y * p.h / h
+		y1 := max((y+1)*p.h/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * p.w / w
+			x1 := max((x+1)*p.w/w, x0+1)
+
+			var r, g, b float64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := (sy*p.w + sx) * 3
+					r, g, b = r+p.pix[i], g+p.pix[i+1], b+p.pix[i+2]
+				}
+			}
+			n := float64((y1 - y0) * (x1 - x0))
+			i := (y*w + x) * 3
+			out.pix[i], out.pix[i+1], out.pix[i+2] = r/n, g/n, b/n
+		}
+	}
+	out.integrate()
+	return out
+}
+
+func (p *plane) integrate() {
+	stride := p.w + 1
+	p.sum = make([]float64, stride*(p.h+1))
+	p.sumSq = make([]float64, stride*(p.h+1))
+	for y := 0; y < p.h; y++ {
+		var rowSum, rowSq float64
+		for x := 0; x < p.w; x++ {
+			i := (y*p.w + x) * 3
+			for c := 0; c < 3; c++ {
+				v := p.pix[i+c]
+				rowSum += v
+				rowSq += v * v
+			}
+			p.sum[(y+1)*stride+x+1] = p.sum[y*stride+x+1] + rowSum
+			p.sumSq[(y+1)*stride+x+1] = p.sumSq[y*stride+x+1] + rowSq
+		}
+	}
+}
+
+// window returns the sum and the sum of squares of a w×h window at (x, y).
+func (p *plane) window(x, y, w, h int) (float64, float64) {
+	stride := p.w + 1
+	a, b := y*stride+x, y*stride+x+w
+	c, d := (y+h)*stride+x, (y+h)*stride+x+w
+	return p.sum[d] - p.sum[b] - p.sum[c] + p.sum[a],
+		p.sumSq[d] - p.sumSq[b] - p.sumSq[c] + p.sumSq[a]
+}
+
+// scan computes NCC of t for every top-left position in positions and returns local peaks above threshold.
+func (p *plane) scan(t *plane, threshold float64, positions image.Rectangle) []Match {
+	n := float64(t.w * t.h * 3)
+
+	// the template is centered once
+	var tSum float64
+	for _, v := range t.pix {
+		tSum += v
+	}
+	tMean := tSum / n
+	centered := make([]float64, len(t.pix))
+	var tVar float64
+	for i, v := range t.pix {
+		centered[i] = v - tMean
+		tVar += centered[i] * centered[i]
+	}
+	if tVar == 0 {
+		return nil
+	}
+
+	pw, ph := positions.Dx(), positions.Dy()
+	if pw <= 0 || ph <= 0 {
+		return nil
+	}
+	scores := make([]float64, pw*ph)
+
+	rowLen := t.w * 3
+	for py := 0; py < ph; py++ {
+		y := positions.Min.Y + py
+		for px := 0; px < pw; px++ {
+			x := positions.Min.X + px
+
+			sum, sumSq := p.window(x, y, t.w, t.h)
+			wVar := sumSq - sum*sum/n
+			if wVar <= 1e-6 {
+				scores[py*pw+px] = -1
+				continue
+			}
+
+			// Σ (t - tMean)·w == Σ (t - tMean)·(w - wMean), because Σ (t - tMean) = 0
+			var cross float64
+			for ty := 0; ty < t.h; ty++ {
+				row := p.pix[((y+ty)*p.w+x)*3:]
+				trow := centered[ty*rowLen : (ty+1)*rowLen]
+				for i, v := range trow {
+					cross += v * row[i]
+				}
+			}
+
+			scores[py*pw+px] = cross / math.Sqrt(tVar*wVar)
+		}
+	}
+
+	var out []Match
+	for py := 0; py < ph; py++ {
+		for px := 0; px < pw; px++ {
+			s := scores[py*pw+px]
+			if s < threshold || !isPeak(scores, pw, ph, px, py) {
+				continue
+			}
+			x, y := positions.Min.X+px, positions.Min.Y+py
+			out = append(out, Match{Rect: image.Rect(x, y, x+t.w, y+t.h), Score: s})
+		}
+	}
+	return out
+}
+
+// isPeak reports whether the score is not lower than any of its 8 neighbours.
+func isPeak(scores []float64, w, h, x, y int) bool {
+	s := scores[y*w+x]
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny := x+dx, y+dy
+			if (dx == 0 && dy == 0) || nx < 0 || ny < 0 || nx >= w || ny >= h {
+				continue
+			}
+			if scores[ny*w+nx] > s {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package vision
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+
+	img, err := LoadPNG(path)
+	require.NoError(t, err)
+	return img
+}
+
+func TestFindTemplate_AllOccurrences(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/alliance/alliance_chest_gift.png")
+	button := loadTestPNG(t, "../../references/icons/alliance.state.isClaimButton.png")
+
+	matches := FindTemplate(screen, button, MatchOptions{Threshold: 0.95})
+
+	require.Len(t, matches, 5, "one Claim button per chest")
+	require.Equal(t, image.Rect(817, 1019, 997, 1081), matches[0].Rect)
+	for i, m := range matches {
+		require.Equal(t, 817, m.Rect.Min.X)
+		if i > 0 {
+			require.LessOrEqual(t, m.Score, matches[i-1].Score, "best first")
+		}
+	}
+}
+
+func TestFindTemplate_MaxResultsAndRegion(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/alliance/alliance_chest_gift.png")
+	button := loadTestPNG(t, "../../references/icons/alliance.state.isClaimButton.png")
+
+	require.Len(t, FindTemplate(screen, button, MatchOptions{Threshold: 0.95, MaxResults: 2}), 2)
+
+	matches := FindTemplate(screen, button, MatchOptions{Threshold: 0.95, Region: image.Rect(700, 1200, 1080, 1400)})
+	require.Len(t, matches, 1)
+	require.Equal(t, image.Rect(817, 1247, 997, 1309), matches[0].Rect, "screen coordinates")
+}
+
+func TestFindTemplate_NotFound(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/mail.png")
+	icon := loadTestPNG(t, "../../references/icons/alliance.state.isNeedSupport.png")
+
+	require.Empty(t, FindTemplate(screen, icon, MatchOptions{Threshold: 0.9}))
+}
+
+func TestFindTemplate_Scaled(t *testing.T) {
+	screen := loadTestPNG(t, "../../references/screenshots/city_main.png")
+	icon := loadTestPNG(t, "../../references/icons/alliance.state.isNeedSupport.png")
+
+	// the icon as it would look on a 10% larger UI
+	b := icon.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, b.Dx()*11/10, b.Dy()*11/10))
+	for y := 0; y < scaled.Bounds().Dy(); y++ {
+		for x := 0; x < scaled.Bounds().Dx(); x++ {
+			scaled.Set(x, y, icon.At(b.Min.X+x*10/11, b.Min.Y+y*10/11))
+		}
+	}
+
+	require.Empty(t, FindTemplate(screen, scaled, MatchOptions{Threshold: 0.9}))
+
+	matches := FindTemplate(screen, scaled, MatchOptions{Threshold: 0.9, Scales: []float64{0.9, 1, 1.1}})
+	require.Len(t, matches, 1)
+	require.InDelta(t, 751, matches[0].Rect.Min.X, 3)
+	require.InDelta(t, 2162, matches[0].Rect.Min.Y, 3)
+}
+
+func TestSuppress(t *testing.T) {
+	got := suppress([]Match{
+		{Rect: image.Rect(0, 0, 10, 10), Score: 0.91},
+		{Rect: image.Rect(1, 1, 11, 11), Score: 0.95},
+		{Rect: image.Rect(20, 20, 30, 30), Score: 0.92},
+	})
+
+	require.Equal(t, []Match{
+		{Rect: image.Rect(1, 1, 11, 11), Score: 0.95},
+		{Rect: image.Rect(20, 20, 30, 30), Score: 0.92},
+	}, got)
+}
@@ -1,4 +1,5 @@
 #!/usr/bin/env python3
+import contextlib
 import os
 import subprocess
 from fastapi import Body
@@ -10,7 +11,7 @@ import time
 import asyncio
 import paddle
 from concurrent.futures import ThreadPoolExecutor
-from fastapi import FastAPI, HTTPException
+from fastapi import FastAPI, HTTPException, WebSocket, WebSocketDisconnect
 from paddleocr import PaddleOCR
 from pydantic import BaseModel
 from typing import List, Optional
@@ -21,6 +22,8 @@ from starlette.middleware.base import BaseHTTPMiddleware
 import json
 from fastapi import Request
 from typing import AsyncIterator
+import uuid
+from fastapi import Response
 
 # --- Middleware for logging requests ---------------------------------------
 class RequestLoggingMiddleware(BaseHTTPMiddleware):
@@ -116,12 +119,14 @@ class Zone(BaseModel):
 
 class OcrRequest(BaseModel):
     device_id: Optional[str] = None
+    frame_id: Optional[str] = None
     debug_name: Optional[str] = None
     regions: Optional[List[Region]] = None
 
 class FindRequest(BaseModel):
     image_name: str
     device_id: Optional[str] = None
+    frame_id: Optional[str] = None
     threshold: Optional[float] = 0.8
     debug_name: Optional[str] = None
     regions: Optional[List[Region]] = None
@@ -130,6 +135,14 @@ class FindImageResponse(BaseModel):
     found: bool
     boxes: List[List[List[int]]]
 
+class FrameRequest(BaseModel):
+    device_id: Optional[str] = None
+
+class FrameResponse(BaseModel):
+    frame_id: str
+    width: int
+    height: int
+
 class WaitRequest(BaseModel):
     stop_words: List[str]
     device_id: Optional[str] = None
@@ -141,6 +154,8 @@ class WaitRequest(BaseModel):
 # --- Configuration ----------------------------------------------------------
 DEBUG_MODE = os.path.exists("/DEBUG")
 SCREENSHOT_TTL = 0.1  # seconds
+FRAME_TTL = 30.0      # seconds a captured frame can be referenced by frame_id
+MAX_FRAMES = 16
 CPU_THREADS = os.cpu_count() or 1
 OCR_VERSION = os.getenv("OCR_VERSION", "PP-OCRv4")
 ICON_DIR = os.path.abspath(os.path.join(
@@ -150,8 +165,37 @@ ICON_DIR = os.path.abspath(os.path.join(
 # --- Globals ----------------------------------------------------------------
 app = FastAPI()
 app.add_middleware(RequestLoggingMiddleware)
+
+# --- Tracing (optional) -------------------------------------------------------
+# The bot sends W3C traceparent headers (and "trace" in /ws messages). With the OpenTelemetry
+# packages installed, requests join the bot's trace and are exported to OTEL_EXPORTER_OTLP_ENDPOINT.
+try:
+    from opentelemetry import trace as otel_trace
+    from opentelemetry.propagate import extract as otel_extract
+    from opentelemetry.sdk.resources import Resource
+    from opentelemetry.sdk.trace import TracerProvider
+    from opentelemetry.sdk.trace.export import BatchSpanProcessor
+    from opentelemetry.exporter.otlp.proto.grpc.trace_exporter import OTLPSpanExporter
+    from opentelemetry.instrumentation.fastapi import FastAPIInstrumentor
+
+    _provider = TracerProvider(resource=Resource.create({"service.name": "ocr-service"}))
+    _provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    otel_trace.set_tracer_provider(_provider)
+    FastAPIInstrumentor.instrument_app(app)
+    tracer = otel_trace.get_tracer("ocr-service")
+except ImportError:
+    tracer = None
+
+
+def ws_span(msg: dict):
+    """Span of one /ws message, child of the caller's span."""
+    if tracer is None:
+        return contextlib.nullcontext()
+    return tracer.start_as_current_span(f"ws {msg.get('type')}", context=otel_extract(msg.get("trace") or {}))
+
 EXECUTOR = ThreadPoolExecutor(max_workers=CPU_THREADS)
 _screenshot_cache = {"ts": 0.0, "img": None}
+_frames = {}  # frame_id -> (ts, img)
 ocr = None
 _gray_templates = {}
 COLOR_MAP = {
@@ -418,7 +462,53 @@ def nms_boxes(all_boxes: List[List[List[int]]], thresh: float=0.5) -> List[List[
             keep.append(box)
     return keep
 
+# --- Frames -----------------------------------------------------------------
+def store_frame(img: np.ndarray) -> str:
+    """Keep a captured frame so several requests can analyze the same screen."""
+    now = time.time()
+    for fid, (ts, _) in list(_frames.items()):
+        if now - ts > FRAME_TTL:
+            del _frames[fid]
+    while len(_frames) >= MAX_FRAMES:
+        del _frames[min(_frames, key=lambda k: _frames[k][0])]
+
+    frame_id = uuid.uuid4().hex
+    _frames[frame_id] = (now, img)
+    return frame_id
+
+
+def get_frame(frame_id: str) -> np.ndarray:
+    frame = _frames.get(frame_id)
+    if frame is None or time.time() - frame[0] > FRAME_TTL:
+        raise HTTPException(status_code=404, detail=f"Frame {frame_id} not found or expired")
+    return frame[1]
+
+
+async def screen_for(device_id: Optional[str], frame_id: Optional[str]) -> np.ndarray:
+    """The referenced frame, or a fresh screenshot."""
+    if frame_id:
+        return get_frame(frame_id)
+    loop = asyncio.get_running_loop()
+    return await loop.run_in_executor(None, get_screenshot, device_id)
+
 # --- FastAPI endpoints ------------------------------------------------------
+@app.post("/frame", response_model=FrameResponse)
+async def frame_endpoint(req: FrameRequest):
+    """Capture the screen once; /ocr and /find_image accept the returned frame_id."""
+    loop = asyncio.get_running_loop()
+    screen = await loop.run_in_executor(None, get_screenshot, req.device_id)
+    h, w = screen.shape[:2]
+    return FrameResponse(frame_id=store_frame(screen), width=w, height=h)
+
+
+@app.get("/frame/{frame_id}")
+async def frame_image_endpoint(frame_id: str):
+    """The captured frame as PNG, e.g. for local template matching on the bot side."""
+    ok, png = cv2.imencode(".png", get_frame(frame_id))
+    if not ok:
+        raise HTTPException(status_code=500, detail="PNG encoding failed")
+    return Response(content=png.tobytes(), media_type="image/png")
+
 @app.post("/ocr", response_model=List[Zone])
 async def ocr_endpoint(req: OcrRequest):
     """
@@ -430,8 +520,8 @@ async def ocr_endpoint(req: OcrRequest):
         start = time.time()
         loop = asyncio.get_running_loop()
         try:
-            # 1) Take screenshot
-            screen = await loop.run_in_executor(None, get_screenshot, req.device_id)
+            # 1) Take screenshot (or reuse the referenced frame)
+            screen = await screen_for(req.device_id, req.frame_id)
 
             # 2) If no regions — analyze whole screen
             if not req.regions:
@@ -507,7 +597,7 @@ async def find_image_endpoint(req: FindRequest):
         raise HTTPException(status_code=404, detail=f"Template {req.image_name} not found")
 
     loop = asyncio.get_running_loop()
-    screen = await loop.run_in_executor(None, get_screenshot, req.device_id)
+    screen = await screen_for(req.device_id, req.frame_id)
     gray = await loop.run_in_executor(None, cv2.cvtColor, screen, cv2.COLOR_BGR2GRAY)
 
     raw_boxes = []
@@ -527,11 +617,10 @@ async def find_image_endpoint(req: FindRequest):
     return FindImageResponse(found=bool(filtered), boxes=filtered)
 
 
-@app.post("/wait_for_text", response_model=List[Zone])
-async def wait_for_text_endpoint(req: WaitRequest = Body(...)):
+async def wait_for_text_polls(req: WaitRequest) -> AsyncIterator[tuple]:
     """
-    Poll /ocr until any stop word (case-insensitive) is found in one of the zones,
-    or timeout expires. Interval between requests — interval.
+    Poll OCR until any stop word (case-insensitive) is found in one of the zones,
+    or timeout expires. Yields (zones, found) after every poll.
     """
     start = time.time()
     loop = asyncio.get_running_loop()
@@ -548,30 +637,120 @@ async def wait_for_text_endpoint(req: WaitRequest = Body(...)):
             zones = await loop.run_in_executor(None, process_roi, screen, full)
 
         # 3) Search for stop words (case-insensitive)
-        found = False
-        for z in zones:
-            txt = z.text.lower()
-            for w in req.stop_words:
-                if w.lower() in txt:
-                    found = True
-                    break
-            if found:
-                break
+        stop_words = [w.lower() for w in req.stop_words]
+        found = any(w in z.text.lower() for z in zones for w in stop_words)
 
-        # 4) If found — save DEBUG frame and return all zones
+        # 4) If found — save DEBUG frame
+        if found and DEBUG_MODE and req.debug_name:
+            debug_path = os.path.join("out", f"{req.debug_name}.png")
+            await loop.run_in_executor(None, cv2.imwrite, debug_path, screen)
+
+        yield zones, found
         if found:
-            if DEBUG_MODE and req.debug_name:
-                debug_path = os.path.join("out", f"{req.debug_name}.png")
-                await loop.run_in_executor(None, cv2.imwrite, debug_path, screen)
-            return zones
+            return
 
         # 5) Check timeout
         if time.time() - start >= req.timeout:
-            return []  # nothing found
+            return
 
         # 6) Wait interval
         await asyncio.sleep(req.interval)
 
+
+@app.post("/wait_for_text", response_model=List[Zone])
+async def wait_for_text_endpoint(req: WaitRequest = Body(...)):
+    """
+    Poll /ocr until any stop word is found, or timeout expires. Interval between requests — interval.
+    """
+    async for zones, found in wait_for_text_polls(req):
+        if found:
+            return zones
+    return []  # nothing found
+
+
+# --- WebSocket transport -----------------------------------------------------
+# One persistent connection per client. Every message carries an "id"; responses echo it.
+#   {"id", "type": "batch", "device_id", "frame_id", "ocr": {...} | null, "find_image": [{...}]}
+#       -> {"id", "type": "result", "ocr": [Zone] | null, "find_image": [FindImageResponse]}
+#   {"id", "type": "wait_for_text", ...WaitRequest}
+#       -> {"id", "type": "progress", "ocr": [Zone]} after every poll, then {"id", "type": "result", "ocr": [Zone]}
+#   {"id", "type": "cancel"} stops the request with the same id, no response follows.
+# Failures are answered with {"id", "type": "error", "error": "..."}.
+
+def zones_json(zones) -> list:
+    return [z.dict() for z in zones]
+
+
+async def ws_batch(msg: dict) -> dict:
+    device_id, frame_id = msg.get("device_id"), msg.get("frame_id")
+    parts = (msg.get("ocr") is not None) + len(msg.get("find_image") or [])
+    if not frame_id and parts > 1:
+        # every part of the batch sees the same screen
+        frame_id = store_frame(await screen_for(device_id, None))
+
+    out = {"ocr": None, "find_image": []}
+    if msg.get("ocr") is not None:
+        req = OcrRequest(device_id=device_id, frame_id=frame_id, **msg["ocr"])
+        out["ocr"] = zones_json(await ocr_endpoint(req))
+    for q in msg.get("find_image") or []:
+        req = FindRequest(device_id=device_id, frame_id=frame_id, **q)
+        out["find_image"].append((await find_image_endpoint(req)).dict())
+    return out
+
+
+async def ws_handle(ws: WebSocket, send_lock: asyncio.Lock, msg: dict):
+    async def send(payload: dict):
+        async with send_lock:
+            await ws.send_json({"id": msg.get("id"), **payload})
+
+    try:
+        with ws_span(msg):
+            if msg.get("type") == "batch":
+                await send({"type": "result", **(await ws_batch(msg))})
+            elif msg.get("type") == "wait_for_text":
+                req = WaitRequest(**{k: v for k, v in msg.items() if k not in ("id", "type", "trace")})
+                last = []
+                async for zones, found in wait_for_text_polls(req):
+                    if found:
+                        last = zones
+                        break
+                    await send({"type": "progress", "ocr": zones_json(zones)})
+                await send({"type": "result", "ocr": zones_json(last)})
+            else:
+                await send({"type": "error", "error": f"unknown message type {msg.get('type')!r}"})
+    except asyncio.CancelledError:
+        raise
+    except HTTPException as e:
+        await send({"type": "error", "error": f"status {e.status_code}: {e.detail}"})
+    except Exception as e:
+        await send({"type": "error", "error": str(e)})
+
+
+@app.websocket("/ws")
+async def ws_endpoint(ws: WebSocket):
+    await ws.accept()
+    send_lock = asyncio.Lock()
+    tasks = {}
+
+    try:
+        while True:
+            msg = await ws.receive_json()
+            msg_id = msg.get("id")
+            if msg.get("type") == "cancel":
+                task = tasks.pop(msg_id, None)
+                if task:
+                    task.cancel()
+                continue
+
+            task = asyncio.create_task(ws_handle(ws, send_lock, msg))
+            tasks[msg_id] = task
+            task.add_done_callback(lambda _, i=msg_id: tasks.pop(i, None))
+    except WebSocketDisconnect:
+        pass
+    finally:
+        for task in tasks.values():
+            task.cancel()
+
 # --- Main -------------------------------------------------------------------
 if __name__ == "__main__":
     import uvicorn